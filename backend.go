@@ -3,13 +3,18 @@ package gitlab
 import (
 	"context"
 
+	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/flags"
 	configPaths "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/config"
 	flagsPaths "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/flags"
+	issuedPaths "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/issued"
+	revocationPaths "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/revocation"
 	rolePaths "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/role"
+	staticRolePaths "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/staticrole"
+	sweepPaths "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/sweep"
 	tokenPaths "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/token"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
 )
@@ -39,6 +44,9 @@ func factory(ctx context.Context, conf *logical.BackendConfig, f flags.Flags) (l
 	b := backend.New(f)
 
 	s := secret.NewSecret(b, backend.DefaultConfigName)
+	ms := secret.NewMembershipSecret(b, backend.DefaultConfigName)
+	as := secret.NewJobTokenAllowlistSecret(b, backend.DefaultConfigName)
+	bs := secret.NewBatchSecret(b, backend.DefaultConfigName)
 
 	err := b.Init(ctx, conf,
 		backend.WithVersion(Version),
@@ -47,11 +55,18 @@ func factory(ctx context.Context, conf *logical.BackendConfig, f flags.Flags) (l
 			flagsPaths.New(b),
 			configPaths.New(b),
 			rolePaths.New(b),
-			tokenPaths.New(b, s),
+			tokenPaths.New(b, s, ms, as, bs),
+			staticRolePaths.New(b),
+			issuedPaths.New(b),
+			revocationPaths.New(b),
+			sweepPaths.New(b),
 		),
-		backend.WithSecrets(s),
-		backend.WithSealWrapStorage(backend.PathConfigStorage),
-		backend.WithLocalStorage(),
+		backend.WithSecrets(s, ms, as, bs),
+		// the WAL holds the value of tokens that can only be revoked by themselves, the pool the value of every token
+		backend.WithSealWrapStorage(backend.PathConfigStorage, backend.PathStaticRoleStorage, framework.WALPrefix, backend.PathPoolStorage, backend.PathReuseStorage, backend.PathCIVariableStorage),
+		// the inventory follows the leases, which are local to the cluster that issued them, and so does the pool
+		// the leases are handed out from
+		backend.WithLocalStorage(backend.PathIssuedStorage, backend.PathIssuedSinceStorage, backend.PathPoolStorage, backend.PathReuseStorage, backend.PathCIVariableStorage),
 	)
 
 	return b, err
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/helper/locksutil"
@@ -11,7 +12,12 @@ import (
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/flags"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/pool"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 )
 
 // Logging provides access to the backend logger.
@@ -61,11 +67,65 @@ type RoleStore interface {
 	GetRole(ctx context.Context, s logical.Storage, name string) (*role.Role, error)
 }
 
+// StaticRoleStore provides static role read and write operations.
+type StaticRoleStore interface {
+	GetStaticRole(ctx context.Context, s logical.Storage, name string) (*role.StaticRole, error)
+	SaveStaticRole(ctx context.Context, s logical.Storage, r *role.StaticRole) error
+}
+
+// IssuedTokenStore provides access to the inventory of issued tokens.
+type IssuedTokenStore interface {
+	GetIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) (*issued.Token, error)
+	SaveIssuedToken(ctx context.Context, s logical.Storage, t *issued.Token) error
+	DeleteIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) error
+}
+
+// IssuedSinceReader provides the time from which the issued tokens are recorded in the inventory.
+type IssuedSinceReader interface {
+	IssuedSince(ctx context.Context, s logical.Storage) (time.Time, error)
+}
+
+// PoolStore provides access to the tokens that are created ahead of the requests of a role.
+type PoolStore interface {
+	GetPooledToken(ctx context.Context, s logical.Storage, roleName, name string) (*pool.Entry, error)
+	SavePooledToken(ctx context.Context, s logical.Storage, e *pool.Entry) error
+	DeletePooledToken(ctx context.Context, s logical.Storage, roleName, name string) error
+}
+
+// ReuseStore provides access to the tokens that are shared by the leases of identical requests within the reuse
+// window of a role.
+type ReuseStore interface {
+	GetSharedToken(ctx context.Context, s logical.Storage, key, name string) (*reuse.Entry, error)
+	SaveSharedToken(ctx context.Context, s logical.Storage, e *reuse.Entry) error
+	DeleteSharedToken(ctx context.Context, s logical.Storage, key, name string) error
+}
+
+// CIVariableStore provides access to the latest injection into each CI/CD variable, so a lease that replaces the
+// value of another active lease restores the variable that was there before either of them.
+type CIVariableStore interface {
+	GetCIVariableInjection(ctx context.Context, s logical.Storage, name string) (*variable.Injection, error)
+	SaveCIVariableInjection(ctx context.Context, s logical.Storage, inj *variable.Injection) error
+	DeleteCIVariableInjection(ctx context.Context, s logical.Storage, name string) error
+}
+
+// RevocationQueueStore provides access to the queue of deferred revocations.
+type RevocationQueueStore interface {
+	GetDeferredRevocation(ctx context.Context, s logical.Storage, name string) (*revocation.Entry, error)
+	SaveDeferredRevocation(ctx context.Context, s logical.Storage, e *revocation.Entry) error
+	DeleteDeferredRevocation(ctx context.Context, s logical.Storage, name string) error
+}
+
 // EventSender abstracts sending audit/events from the backend.
 type EventSender interface {
 	SendEvent(ctx context.Context, eventType event.EventType, metadata map[string]string) error
 }
 
+// EntityReader looks up the Vault identity entity that made a request, and the groups it is a member of.
+type EntityReader interface {
+	EntityInfo(entityID string) (*logical.Entity, error)
+	GroupsForEntity(entityID string) ([]*logical.Group, error)
+}
+
 type WriteSafeReplicationState interface {
 	WriteSafeReplicationState() bool
 }
@@ -80,6 +140,14 @@ type Backend interface {
 	Locker
 	ConfigStore
 	RoleStore
+	StaticRoleStore
+	IssuedTokenStore
+	IssuedSinceReader
+	PoolStore
+	ReuseStore
+	CIVariableStore
+	RevocationQueueStore
 	EventSender
+	EntityReader
 	WriteSafeReplicationState
 }
@@ -3,6 +3,8 @@ package backend
 import (
 	"time"
 
+	"github.com/hashicorp/vault/sdk/framework"
+
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
 )
 
@@ -13,10 +15,34 @@ const (
 	DefaultAccessTokenMinTTL            = 24 * time.Hour
 	DefaultAccessTokenMaxPossibleTTL    = 365 * 24 * time.Hour
 	DefaultConfigName                   = "default"
+	DefaultRolePoolMaxAge               = time.Hour
+	MaxRolePoolSize                     = 50
 
 	// PathConfigStorage is the storage key prefix for config entries.
 	PathConfigStorage = "config"
 
 	// PathRoleStorage is the storage key prefix for role entries.
 	PathRoleStorage = "roles"
+
+	// PathStaticRoleStorage is the storage key prefix for static role entries.
+	PathStaticRoleStorage = "static-roles"
+
+	// PathIssuedStorage is the storage key prefix for the inventory of issued tokens.
+	PathIssuedStorage = "issued"
+
+	// PathIssuedSinceStorage is the storage key of the time the inventory of issued tokens started.
+	PathIssuedSinceStorage = "issued-since"
+
+	// PathPoolStorage is the storage key prefix for the tokens that are created ahead of the requests of a role.
+	PathPoolStorage = "pool"
+
+	// PathReuseStorage is the storage key prefix for the tokens that are shared by the leases of identical requests.
+	PathReuseStorage = "reuse"
+
+	// PathCIVariableStorage is the storage key prefix for the latest lease value written to each CI/CD variable.
+	PathCIVariableStorage = "ci-variable"
+
+	// PathRevocationQueueStorage is the storage key prefix for revocations that have been deferred,
+	// it lives under the WAL prefix which is always local storage.
+	PathRevocationQueueStorage = framework.WALPrefix + "revoke"
 )
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/locksutil"
@@ -17,7 +18,12 @@ import (
 	g "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
 	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/pool"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
 )
 
@@ -31,7 +37,14 @@ var (
 	_ Locker                    = (*Impl)(nil)
 	_ ConfigStore               = (*Impl)(nil)
 	_ RoleStore                 = (*Impl)(nil)
+	_ StaticRoleStore           = (*Impl)(nil)
+	_ IssuedTokenStore          = (*Impl)(nil)
+	_ PoolStore                 = (*Impl)(nil)
+	_ ReuseStore                = (*Impl)(nil)
+	_ CIVariableStore           = (*Impl)(nil)
+	_ RevocationQueueStore      = (*Impl)(nil)
 	_ EventSender               = (*Impl)(nil)
+	_ EntityReader              = (*Impl)(nil)
 	_ WriteSafeReplicationState = (*Impl)(nil)
 	_ Backend                   = (*Impl)(nil)
 )
@@ -45,8 +58,9 @@ type Impl struct {
 	// The client that we can use to create and revoke the access tokens
 	clients sync.Map
 
-	// locks provides per-key locking scoped by path prefix for roles, configs, clients, etc.
-	locks []*locksutil.LockEntry
+	// locks provides per-key locking scoped by path prefix for roles, configs, clients, etc. Every path prefix has
+	// its own set of locks, so the locks of different paths can be nested without ever sharing a lock.
+	locks sync.Map
 
 	// pathProviders holds the registered path providers with their optional hooks
 	pathProviders []PathProvider
@@ -55,7 +69,6 @@ type Impl struct {
 // New creates a new BackendImpl with the given flags. Call Init to complete setup.
 func New(f flags.Flags) *Impl {
 	return &Impl{
-		locks:   locksutil.CreateLocks(),
 		clients: sync.Map{},
 		flags:   f,
 	}
@@ -88,6 +101,7 @@ func (b *Impl) Init(ctx context.Context, conf *logical.BackendConfig, opts ...In
 		Secrets:      cfg.secrets,
 		Paths:        framework.PathAppend(allPaths),
 		PeriodicFunc: b.periodicFunc,
+		WALRollback:  b.walRollback,
 	}
 
 	return b.Setup(ctx, conf)
@@ -110,6 +124,19 @@ func (b *Impl) periodicFunc(ctx context.Context, req *logical.Request) error {
 	return errs
 }
 
+// walRollback dispatches WAL entries to all registered WALRollbackHandlers.
+func (b *Impl) walRollback(ctx context.Context, req *logical.Request, kind string, data any) error {
+	b.Logger().Debug("WAL rollback", "kind", kind)
+	var errs error
+	for _, p := range b.pathProviders {
+		if wh, ok := p.(WALRollbackHandler); ok {
+			b.Logger().Debug("WAL rollback handler dispatching", "provider", p.Name(), "kind", kind)
+			errs = errors.Join(errs, wh.WALRollback(ctx, req, kind, data))
+		}
+	}
+	return errs
+}
+
 // invalidate dispatches to all registered InvalidateHandlers.
 func (b *Impl) invalidate(ctx context.Context, key string) {
 	b.Logger().Debug("Backend invalidate", "key", key)
@@ -187,6 +214,16 @@ func (b *Impl) SendEvent(ctx context.Context, eventType event.EventType, metadat
 	return event.Event(ctx, b.Backend, eventType, metadata)
 }
 
+// EntityInfo returns the identity entity with the given id from the system view of the backend.
+func (b *Impl) EntityInfo(entityID string) (*logical.Entity, error) {
+	return b.System().EntityInfo(entityID)
+}
+
+// GroupsForEntity returns the identity groups of the entity with the given id from the system view of the backend.
+func (b *Impl) GroupsForEntity(entityID string) ([]*logical.Group, error) {
+	return b.System().GroupsForEntity(entityID)
+}
+
 func (b *Impl) Flags() flags.Flags {
 	l := b.LockForKey("flags", "default")
 	l.RLock()
@@ -202,7 +239,11 @@ func (b *Impl) UpdateFlags(fn func(*flags.Flags)) {
 }
 
 func (b *Impl) LockForKey(path, key string) *locksutil.LockEntry {
-	return locksutil.LockForKey(b.locks, path+"/"+key)
+	locks, ok := b.locks.Load(path)
+	if !ok {
+		locks, _ = b.locks.LoadOrStore(path, locksutil.CreateLocks())
+	}
+	return locksutil.LockForKey(locks.([]*locksutil.LockEntry), key)
 }
 
 func (b *Impl) GetConfig(ctx context.Context, s logical.Storage, name string) (*modelConfig.EntryConfig, error) {
@@ -216,3 +257,95 @@ func (b *Impl) SaveConfig(ctx context.Context, s logical.Storage, config *modelC
 func (b *Impl) GetRole(ctx context.Context, s logical.Storage, name string) (*role.Role, error) {
 	return model.Get[role.Role](ctx, s, fmt.Sprintf("%s/%s", PathRoleStorage, name))
 }
+
+func (b *Impl) GetStaticRole(ctx context.Context, s logical.Storage, name string) (*role.StaticRole, error) {
+	return model.Get[role.StaticRole](ctx, s, fmt.Sprintf("%s/%s", PathStaticRoleStorage, name))
+}
+
+func (b *Impl) SaveStaticRole(ctx context.Context, s logical.Storage, r *role.StaticRole) error {
+	return model.Save(ctx, s, PathStaticRoleStorage, r)
+}
+
+func (b *Impl) GetIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) (*issued.Token, error) {
+	return model.Get[issued.Token](ctx, s, fmt.Sprintf("%s/%s/%s/%d", PathIssuedStorage, configName, roleName, tokenId))
+}
+
+func (b *Impl) SaveIssuedToken(ctx context.Context, s logical.Storage, t *issued.Token) (err error) {
+	if err = model.Save(ctx, s, fmt.Sprintf("%s/%s/%s", PathIssuedStorage, t.ConfigName, t.RoleName), t); err != nil {
+		return err
+	}
+
+	// the first record marks the start of the inventory, the tokens created before it may be issued without a record
+	var since time.Time
+	if since, err = b.IssuedSince(ctx, s); err != nil || !since.IsZero() || t.IssuedAt.IsZero() {
+		return err
+	}
+
+	var entry *logical.StorageEntry
+	if entry, err = logical.StorageEntryJSON(PathIssuedSinceStorage, t.IssuedAt.UTC()); err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// IssuedSince returns the time the first token was recorded in the inventory, it's zero until a token is recorded.
+func (b *Impl) IssuedSince(ctx context.Context, s logical.Storage) (since time.Time, err error) {
+	var entry *logical.StorageEntry
+	if entry, err = s.Get(ctx, PathIssuedSinceStorage); err != nil || entry == nil {
+		return since, err
+	}
+	err = entry.DecodeJSON(&since)
+	return since, err
+}
+
+func (b *Impl) GetPooledToken(ctx context.Context, s logical.Storage, roleName, name string) (*pool.Entry, error) {
+	return model.Get[pool.Entry](ctx, s, fmt.Sprintf("%s/%s/%s", PathPoolStorage, roleName, name))
+}
+
+func (b *Impl) SavePooledToken(ctx context.Context, s logical.Storage, e *pool.Entry) error {
+	return model.Save(ctx, s, fmt.Sprintf("%s/%s", PathPoolStorage, e.RoleName), e)
+}
+
+func (b *Impl) DeletePooledToken(ctx context.Context, s logical.Storage, roleName, name string) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s/%s", PathPoolStorage, roleName, name))
+}
+
+func (b *Impl) GetSharedToken(ctx context.Context, s logical.Storage, key, name string) (*reuse.Entry, error) {
+	return model.Get[reuse.Entry](ctx, s, fmt.Sprintf("%s/%s/%s", PathReuseStorage, key, name))
+}
+
+func (b *Impl) SaveSharedToken(ctx context.Context, s logical.Storage, e *reuse.Entry) error {
+	return model.Save(ctx, s, fmt.Sprintf("%s/%s", PathReuseStorage, e.Key), e)
+}
+
+func (b *Impl) DeleteSharedToken(ctx context.Context, s logical.Storage, key, name string) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s/%s", PathReuseStorage, key, name))
+}
+
+func (b *Impl) GetCIVariableInjection(ctx context.Context, s logical.Storage, name string) (*variable.Injection, error) {
+	return model.Get[variable.Injection](ctx, s, fmt.Sprintf("%s/%s", PathCIVariableStorage, name))
+}
+
+func (b *Impl) SaveCIVariableInjection(ctx context.Context, s logical.Storage, inj *variable.Injection) error {
+	return model.Save(ctx, s, PathCIVariableStorage, inj)
+}
+
+func (b *Impl) DeleteCIVariableInjection(ctx context.Context, s logical.Storage, name string) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s", PathCIVariableStorage, name))
+}
+
+func (b *Impl) GetDeferredRevocation(ctx context.Context, s logical.Storage, name string) (*revocation.Entry, error) {
+	return model.Get[revocation.Entry](ctx, s, fmt.Sprintf("%s/%s", PathRevocationQueueStorage, name))
+}
+
+func (b *Impl) SaveDeferredRevocation(ctx context.Context, s logical.Storage, e *revocation.Entry) error {
+	return model.Save(ctx, s, PathRevocationQueueStorage, e)
+}
+
+func (b *Impl) DeleteDeferredRevocation(ctx context.Context, s logical.Storage, name string) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s", PathRevocationQueueStorage, name))
+}
+
+func (b *Impl) DeleteIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s/%s/%d", PathIssuedStorage, configName, roleName, tokenId))
+}
@@ -1,6 +1,7 @@
 package backend_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -12,6 +13,12 @@ import (
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/flags"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/pool"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 )
 
 func TestNew(t *testing.T) {
@@ -27,6 +34,32 @@ func TestFlags(t *testing.T) {
 	assert.True(t, b.Flags().ShowConfigToken)
 }
 
+func TestEntityInfo(t *testing.T) {
+	entity := &logical.Entity{ID: "entity-id", Name: "alice"}
+	b := backend.New(flags.Flags{})
+	require.NoError(t, b.Init(t.Context(), &logical.BackendConfig{
+		System:       &logical.StaticSystemView{EntityVal: entity},
+		EventsSender: &logical.MockEventSender{},
+	}))
+
+	got, err := b.EntityInfo("entity-id")
+	require.NoError(t, err)
+	assert.Equal(t, entity, got)
+}
+
+func TestGroupsForEntity(t *testing.T) {
+	groups := []*logical.Group{{ID: "group-id", Name: "platform"}}
+	b := backend.New(flags.Flags{})
+	require.NoError(t, b.Init(t.Context(), &logical.BackendConfig{
+		System:       &logical.StaticSystemView{GroupsVal: groups},
+		EventsSender: &logical.MockEventSender{},
+	}))
+
+	got, err := b.GroupsForEntity("entity-id")
+	require.NoError(t, err)
+	assert.Equal(t, groups, got)
+}
+
 func TestClientCRUD(t *testing.T) {
 	b := newTestBackend(t)
 	c := &dummyClient{valid: true}
@@ -60,6 +93,11 @@ func TestLockForKey(t *testing.T) {
 
 	// Different paths with the same key produce different locks.
 	assert.NotSame(t, b.LockForKey("role", "x"), b.LockForKey("config", "x"))
+
+	// Locks of different paths can be nested, they never share a lock whatever the keys are.
+	for i := range 1024 {
+		assert.NotSame(t, b.LockForKey("role", "r"), b.LockForKey("client", fmt.Sprint(i)))
+	}
 }
 
 func TestGetConfig(t *testing.T) {
@@ -83,6 +121,136 @@ func TestGetRole(t *testing.T) {
 	assert.Nil(t, r)
 }
 
+func TestGetStaticRole(t *testing.T) {
+	b := backend.New(flags.Flags{})
+	ctx, s := t.Context(), &logical.InmemStorage{}
+
+	r, err := b.GetStaticRole(ctx, s, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	require.NoError(t, b.SaveStaticRole(ctx, s, &role.StaticRole{RoleName: "static", TokenID: 42}))
+	r, err = b.GetStaticRole(ctx, s, "static")
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.EqualValues(t, 42, r.TokenID)
+}
+
+func TestIssuedToken(t *testing.T) {
+	b := backend.New(flags.Flags{})
+	ctx, s := t.Context(), &logical.InmemStorage{}
+
+	it, err := b.GetIssuedToken(ctx, s, "default", "role", 42)
+	require.NoError(t, err)
+	assert.Nil(t, it)
+
+	require.NoError(t, b.SaveIssuedToken(ctx, s, &issued.Token{ConfigName: "default", RoleName: "role", TokenID: 42}))
+	keys, err := s.List(ctx, "issued/default/role/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"42"}, keys)
+
+	it, err = b.GetIssuedToken(ctx, s, "default", "role", 42)
+	require.NoError(t, err)
+	require.NotNil(t, it)
+	assert.EqualValues(t, 42, it.TokenID)
+
+	require.NoError(t, b.DeleteIssuedToken(ctx, s, "default", "role", 42))
+	it, err = b.GetIssuedToken(ctx, s, "default", "role", 42)
+	require.NoError(t, err)
+	assert.Nil(t, it)
+}
+
+func TestIssuedSince(t *testing.T) {
+	b := backend.New(flags.Flags{})
+	ctx, s := t.Context(), &logical.InmemStorage{}
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	since, err := b.IssuedSince(ctx, s)
+	require.NoError(t, err)
+	assert.True(t, since.IsZero())
+
+	require.NoError(t, b.SaveIssuedToken(ctx, s, &issued.Token{ConfigName: "default", RoleName: "role", TokenID: 42, IssuedAt: now}))
+	require.NoError(t, b.SaveIssuedToken(ctx, s, &issued.Token{ConfigName: "default", RoleName: "role", TokenID: 43, IssuedAt: now.Add(time.Hour)}))
+	require.NoError(t, b.DeleteIssuedToken(ctx, s, "default", "role", 42))
+
+	since, err = b.IssuedSince(ctx, s)
+	require.NoError(t, err)
+	assert.Equal(t, now, since)
+}
+
+func TestPooledToken(t *testing.T) {
+	b := backend.New(flags.Flags{})
+	ctx, s := t.Context(), &logical.InmemStorage{}
+
+	e, err := b.GetPooledToken(ctx, s, "role", "42")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	require.NoError(t, b.SavePooledToken(ctx, s, &pool.Entry{RoleName: "role", TokenID: 42}))
+	keys, err := s.List(ctx, "pool/role/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"42"}, keys)
+
+	e, err = b.GetPooledToken(ctx, s, "role", "42")
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.EqualValues(t, 42, e.TokenID)
+
+	require.NoError(t, b.DeletePooledToken(ctx, s, "role", "42"))
+	e, err = b.GetPooledToken(ctx, s, "role", "42")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+}
+
+func TestSharedToken(t *testing.T) {
+	b := backend.New(flags.Flags{})
+	ctx, s := t.Context(), &logical.InmemStorage{}
+
+	e, err := b.GetSharedToken(ctx, s, "key", "42")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	require.NoError(t, b.SaveSharedToken(ctx, s, &reuse.Entry{Key: "key", TokenID: 42, Leases: 2}))
+	keys, err := s.List(ctx, "reuse/key/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"42"}, keys)
+
+	e, err = b.GetSharedToken(ctx, s, "key", "42")
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.EqualValues(t, 42, e.TokenID)
+	assert.Equal(t, 2, e.Leases)
+
+	require.NoError(t, b.DeleteSharedToken(ctx, s, "key", "42"))
+	e, err = b.GetSharedToken(ctx, s, "key", "42")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+}
+
+func TestDeferredRevocation(t *testing.T) {
+	b := backend.New(flags.Flags{})
+	ctx, s := t.Context(), &logical.InmemStorage{}
+
+	e, err := b.GetDeferredRevocation(ctx, s, "default-project-42")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	require.NoError(t, b.SaveDeferredRevocation(ctx, s, &revocation.Entry{ConfigName: "default", TokenType: token.TypeProject, TokenID: 42}))
+	keys, err := s.List(ctx, "wal/revoke/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default-project-42"}, keys)
+
+	e, err = b.GetDeferredRevocation(ctx, s, "default-project-42")
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.EqualValues(t, 42, e.TokenID)
+
+	require.NoError(t, b.DeleteDeferredRevocation(ctx, s, "default-project-42"))
+	e, err = b.GetDeferredRevocation(ctx, s, "default-project-42")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+}
+
 func TestGetClientByName(t *testing.T) {
 	b := newTestBackend(t)
 	ctx, s := t.Context(), &logical.InmemStorage{}
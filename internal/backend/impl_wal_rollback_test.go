@@ -0,0 +1,71 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+)
+
+// dummyWALRollbackProvider implements PathProvider and WALRollbackHandler.
+type dummyWALRollbackProvider struct {
+	dummyProvider
+	rollbackErr error
+	kinds       []string
+}
+
+func (d *dummyWALRollbackProvider) WALRollback(_ context.Context, _ *logical.Request, kind string, _ any) error {
+	d.kinds = append(d.kinds, kind)
+	return d.rollbackErr
+}
+
+func rollback(t *testing.T, b *backend.Impl, s logical.Storage) (*logical.Response, error) {
+	t.Helper()
+	return b.HandleRequest(t.Context(), &logical.Request{
+		Operation: logical.RollbackOperation,
+		Storage:   s,
+		Data:      map[string]any{"immediate": true},
+	})
+}
+
+func TestWALRollback_Dispatch(t *testing.T) {
+	p := &dummyWALRollbackProvider{dummyProvider: dummyProvider{name: "wal"}}
+	b := newTestBackend(t, backend.WithProviders(p, &dummyProvider{name: "plain"}))
+	s := &logical.InmemStorage{}
+
+	_, err := framework.PutWAL(t.Context(), s, "kind", map[string]any{"key": "value"})
+	require.NoError(t, err)
+
+	resp, err := rollback(t, b, s)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	assert.Equal(t, []string{"kind"}, p.kinds)
+
+	keys, err := framework.ListWAL(t.Context(), s)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestWALRollback_ErrorKeepsEntry(t *testing.T) {
+	p := &dummyWALRollbackProvider{dummyProvider: dummyProvider{name: "wal"}, rollbackErr: errors.New("rollback failed")}
+	b := newTestBackend(t, backend.WithProviders(p))
+	s := &logical.InmemStorage{}
+
+	_, err := framework.PutWAL(t.Context(), s, "kind", nil)
+	require.NoError(t, err)
+
+	resp, err := rollback(t, b, s)
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "rollback failed")
+
+	keys, err := framework.ListWAL(t.Context(), s)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
@@ -25,3 +25,10 @@ type PeriodicHandler interface {
 type InvalidateHandler interface {
 	Invalidate(ctx context.Context, key string)
 }
+
+// WALRollbackHandler is optionally implemented by PathProviders that write WAL entries.
+// Every entry that is due for a rollback is dispatched to all handlers, a handler must
+// ignore the kinds it does not own.
+type WALRollbackHandler interface {
+	WALRollback(ctx context.Context, req *logical.Request, kind string, data any) error
+}
@@ -0,0 +1,41 @@
+package gitlab_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
+)
+
+func TestCIVariable_KeepsTypeRawAndDescription(t *testing.T) {
+	var updated map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+		}
+		_, _ = w.Write([]byte(`{"key":"KUBECONFIG","value":"config","variable_type":"file","raw":false,"environment_scope":"*","description":"cluster access"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := gitlab.NewGitlabClient(&modelConfig.EntryConfig{BaseURL: srv.URL, Token: "glpat-token"}, nil, nil)
+	require.NoError(t, err)
+
+	v, err := client.GetCIVariable(t.Context(), variable.TargetProject, "example/app", "KUBECONFIG", "*")
+	require.NoError(t, err)
+	assert.Equal(t, &variable.Variable{
+		Key: "KUBECONFIG", Value: "config", EnvironmentScope: "*", VariableType: variable.TypeFile, Description: "cluster access",
+	}, v)
+
+	require.NoError(t, client.UpdateCIVariable(t.Context(), variable.TargetGroup, "example", v))
+	assert.Equal(t, variable.TypeFile, updated["variable_type"])
+	assert.Equal(t, false, updated["raw"])
+	assert.Equal(t, "cluster access", updated["description"])
+}
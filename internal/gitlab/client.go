@@ -7,12 +7,14 @@ import (
 	g "gitlab.com/gitlab-org/api/client-go/v2"
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 )
 
 type Client interface {
 	GitlabClient(ctx context.Context) *g.Client
 	Valid(ctx context.Context) bool
+	Saturation() Saturation
 	Metadata(ctx context.Context) (*g.Metadata, error)
 	CurrentTokenInfo(ctx context.Context) (*token.TokenConfig, error)
 	RotateCurrentToken(ctx context.Context) (newToken *token.TokenConfig, oldToken *token.TokenConfig, err error)
@@ -22,6 +24,9 @@ type Client interface {
 	RevokePersonalAccessToken(ctx context.Context, tokenId int64) error
 	RevokeProjectAccessToken(ctx context.Context, tokenId int64, projectId string) error
 	RevokeGroupAccessToken(ctx context.Context, tokenId int64, groupId string) error
+	ListPersonalAccessTokens(ctx context.Context, username string, userId int64) ([]*token.TokenPersonal, error)
+	ListProjectAccessTokens(ctx context.Context, projectId string) ([]*token.TokenProject, error)
+	ListGroupAccessTokens(ctx context.Context, groupId string) ([]*token.TokenGroup, error)
 	GetUserIdByUsername(ctx context.Context, username string) (int64, error)
 	GetGroupIdByPath(ctx context.Context, path string) (int64, error)
 	GetProjectIdByPath(ctx context.Context, path string) (int64, error)
@@ -31,10 +36,40 @@ type Client interface {
 	RevokeUserServiceAccountAccessToken(ctx context.Context, token string) error
 	RevokeGroupServiceAccountAccessToken(ctx context.Context, token string) error
 	RevokeProjectServiceAccountAccessToken(ctx context.Context, token string) error
+	CreateGroupServiceAccount(ctx context.Context, groupId string, name string) (userId int64, username string, err error)
+	CreateUserServiceAccount(ctx context.Context, name string) (userId int64, username string, err error)
+	DeleteGroupServiceAccount(ctx context.Context, groupId string, userId int64) error
+	DeleteUserServiceAccount(ctx context.Context, userId int64) error
+	AddMember(ctx context.Context, path string, userId int64, accessLevel t.AccessLevel, expiresAt *time.Time) error
+	RemoveMember(ctx context.Context, path string, userId int64) error
+	CreateRunner(ctx context.Context, tokenType t.Type, path string, parentId int64, name string, description string, tags []string, runUntagged bool, locked bool) (*token.TokenRunner, error)
+	DeleteRunner(ctx context.Context, runnerId int64) error
+	CreateClusterAgentToken(ctx context.Context, path string, projectId int64, agentName string, createAgent bool, name string) (et *token.TokenClusterAgent, err error)
+	RevokeClusterAgentToken(ctx context.Context, projectId, agentId, tokenId int64, deleteAgent bool) (err error)
+	CreateProjectDeployKey(ctx context.Context, path string, projectId int64, name string, publicKey string, canPush bool, expiresAt *time.Time) (et *token.TokenProjectDeployKey, err error)
+	RevokeProjectDeployKey(ctx context.Context, projectId, deployKeyId int64) (err error)
+	CreateUserSSHKey(ctx context.Context, username string, userId int64, name string, publicKey string, expiresAt *time.Time) (et *token.TokenUserSSHKey, err error)
+	RevokeUserSSHKey(ctx context.Context, userId, keyId int64) (err error)
+	CreateImpersonationToken(ctx context.Context, username string, userId int64, name string, expiresAt time.Time, scopes []string) (et *token.TokenImpersonation, err error)
+	RevokeImpersonationToken(ctx context.Context, userId, tokenId int64) (err error)
+	CreateOAuthApplication(ctx context.Context, path string, name string, redirectURIs []string, scopes []string, confidential bool) (et *token.TokenOAuthApplication, err error)
+	RenewOAuthApplicationSecret(ctx context.Context, path string, applicationId int64) (et *token.TokenOAuthApplication, err error)
+	DeleteOAuthApplication(ctx context.Context, applicationId int64) (err error)
+	AddJobTokenAllowlistEntry(ctx context.Context, tokenType t.Type, path string, projectId int64, allowedId int64) (et *token.TokenJobTokenAllowlist, err error)
+	RemoveJobTokenAllowlistEntry(ctx context.Context, tokenType t.Type, projectId int64, allowedId int64) (err error)
+	GetCIVariable(ctx context.Context, target string, path string, key string, environmentScope string) (v *variable.Variable, err error)
+	CreateCIVariable(ctx context.Context, target string, path string, v *variable.Variable) (err error)
+	UpdateCIVariable(ctx context.Context, target string, path string, v *variable.Variable) (err error)
+	DeleteCIVariable(ctx context.Context, target string, path string, key string, environmentScope string) (err error)
 	CreatePipelineProjectTriggerAccessToken(ctx context.Context, path, name string, projectId int64, description string, expiresAt *time.Time) (*token.TokenPipelineProjectTrigger, error)
 	RevokePipelineProjectTriggerAccessToken(ctx context.Context, projectId int64, tokenId int64) error
 	CreateProjectDeployToken(ctx context.Context, path string, projectId int64, name string, expiresAt *time.Time, scopes []string) (et *token.TokenProjectDeploy, err error)
 	RevokeProjectDeployToken(ctx context.Context, projectId, deployTokenId int64) (err error)
 	CreateGroupDeployToken(ctx context.Context, path string, groupId int64, name string, expiresAt *time.Time, scopes []string) (et *token.TokenGroupDeploy, err error)
 	RevokeGroupDeployToken(ctx context.Context, groupId, deployTokenId int64) (err error)
+	RotatePersonalAccessToken(ctx context.Context, username string, tokenId int64, expiresAt time.Time) (*token.TokenPersonal, error)
+	RotateGroupAccessToken(ctx context.Context, groupId string, tokenId int64, expiresAt time.Time) (*token.TokenGroup, error)
+	RotateProjectAccessToken(ctx context.Context, projectId string, tokenId int64, expiresAt time.Time) (*token.TokenProject, error)
+	RotateGroupServiceAccountAccessToken(ctx context.Context, path string, groupId string, userId int64, tokenId int64, expiresAt time.Time) (*token.TokenGroupServiceAccount, error)
+	RotateProjectServiceAccountAccessToken(ctx context.Context, path string, projectId string, userId int64, tokenId int64, expiresAt time.Time) (*token.TokenProjectServiceAccount, error)
 }
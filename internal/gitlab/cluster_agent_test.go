@@ -0,0 +1,92 @@
+package gitlab_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+)
+
+func TestRevokeClusterAgentToken(t *testing.T) {
+	var agentGitlab = func(t *testing.T, tokens string, revokeStatus int) (srv *httptest.Server, requests *[]string) {
+		t.Helper()
+		var mu sync.Mutex
+		requests = new([]string)
+		srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			*requests = append(*requests, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.Method == http.MethodGet:
+				_, _ = w.Write([]byte(tokens))
+			case strings.Contains(r.URL.Path, "/tokens/"):
+				w.WriteHeader(revokeStatus)
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return srv, requests
+	}
+
+	var client = func(t *testing.T, srv *httptest.Server) gitlab.Client {
+		t.Helper()
+		client, err := gitlab.NewGitlabClient(&modelConfig.EntryConfig{BaseURL: srv.URL, Token: "glpat-token"}, nil, nil)
+		require.NoError(t, err)
+		return client
+	}
+
+	t.Run("agent kept", func(t *testing.T) {
+		srv, requests := agentGitlab(t, `[]`, http.StatusNoContent)
+		require.NoError(t, client(t, srv).RevokeClusterAgentToken(t.Context(), 1, 2, 3, false))
+		assert.Equal(t, []string{"DELETE /api/v4/projects/1/cluster_agents/2/tokens/3"}, *requests)
+	})
+
+	t.Run("agent deleted without tokens", func(t *testing.T) {
+		srv, requests := agentGitlab(t, `[{"id":3,"status":"active"},{"id":4,"status":"revoked"}]`, http.StatusNoContent)
+		require.NoError(t, client(t, srv).RevokeClusterAgentToken(t.Context(), 1, 2, 3, true))
+		assert.Equal(t, []string{
+			"DELETE /api/v4/projects/1/cluster_agents/2/tokens/3",
+			"GET /api/v4/projects/1/cluster_agents/2/tokens",
+			"DELETE /api/v4/projects/1/cluster_agents/2",
+		}, *requests)
+	})
+
+	t.Run("agent with tokens kept", func(t *testing.T) {
+		srv, requests := agentGitlab(t, `[{"id":5,"status":"active"}]`, http.StatusNoContent)
+		require.NoError(t, client(t, srv).RevokeClusterAgentToken(t.Context(), 1, 2, 3, true))
+		assert.Equal(t, []string{
+			"DELETE /api/v4/projects/1/cluster_agents/2/tokens/3",
+			"GET /api/v4/projects/1/cluster_agents/2/tokens",
+		}, *requests)
+	})
+	t.Run("token already revoked", func(t *testing.T) {
+		srv, requests := agentGitlab(t, `[]`, http.StatusNotFound)
+		require.ErrorIs(t, client(t, srv).RevokeClusterAgentToken(t.Context(), 1, 2, 3, false), errs.ErrAccessTokenNotFound)
+		assert.Equal(t, []string{"DELETE /api/v4/projects/1/cluster_agents/2/tokens/3"}, *requests)
+	})
+
+	t.Run("agent deleted after the token was already revoked", func(t *testing.T) {
+		srv, requests := agentGitlab(t, `[{"id":3,"status":"revoked"}]`, http.StatusNotFound)
+		require.NoError(t, client(t, srv).RevokeClusterAgentToken(t.Context(), 1, 2, 3, true))
+		assert.Equal(t, []string{
+			"DELETE /api/v4/projects/1/cluster_agents/2/tokens/3",
+			"GET /api/v4/projects/1/cluster_agents/2/tokens",
+			"DELETE /api/v4/projects/1/cluster_agents/2",
+		}, *requests)
+	})
+
+	t.Run("token already revoked and agent with tokens kept", func(t *testing.T) {
+		srv, _ := agentGitlab(t, `[{"id":5,"status":"active"}]`, http.StatusNotFound)
+		require.ErrorIs(t, client(t, srv).RevokeClusterAgentToken(t.Context(), 1, 2, 3, true), errs.ErrAccessTokenNotFound)
+	})
+}
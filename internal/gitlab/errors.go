@@ -0,0 +1,25 @@
+package gitlab
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	g "gitlab.com/gitlab-org/api/client-go/v2"
+)
+
+// IsTransientError reports whether the error was caused by GitLab being unreachable or failing on its
+// side, in which case the same request is expected to succeed if it is retried later.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var errResponse *g.ErrorResponse
+	if errors.As(err, &errResponse) {
+		return errResponse.StatusCode >= http.StatusInternalServerError || errResponse.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
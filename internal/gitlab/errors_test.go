@@ -0,0 +1,41 @@
+package gitlab_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	g "gitlab.com/gitlab-org/api/client-go/v2"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+)
+
+func TestIsTransientError(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("boom"), false},
+		{"access token not found", errs.ErrAccessTokenNotFound, false},
+		{"not found", g.ErrNotFound, false},
+		{"unauthorized", &g.ErrorResponse{StatusCode: http.StatusUnauthorized}, false},
+		{"too many requests", &g.ErrorResponse{StatusCode: http.StatusTooManyRequests}, true},
+		{"internal server error", &g.ErrorResponse{StatusCode: http.StatusInternalServerError}, true},
+		{"bad gateway wrapped", fmt.Errorf("revoke: %w", &g.ErrorResponse{StatusCode: http.StatusBadGateway}), true},
+		{"connection refused", &url.Error{Op: "Delete", URL: "https://gitlab.example.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"dns error", fmt.Errorf("giving up: %w", &net.DNSError{Err: "no such host", Name: "gitlab.example.com"}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, gitlab.IsTransientError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package gitlab
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+)
+
+// newHTTPClient builds the http.Client for the connection settings of the config, the CA bundle, client
+// certificate, proxy and request timeout. A config without any of them returns nil, and client-go uses its own.
+func newHTTPClient(config *modelConfig.EntryConfig) (_ *http.Client, err error) {
+	var tlsConfig *tls.Config
+	if tlsConfig, err = config.TLSConfig(); err != nil {
+		return nil, err
+	}
+
+	var proxy *url.URL
+	if proxy, err = config.Proxy(); err != nil {
+		return nil, err
+	}
+
+	if tlsConfig == nil && proxy == nil && config.RequestTimeout == 0 {
+		return nil, nil
+	}
+
+	var transport = http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport, Timeout: config.RequestTimeout}, nil
+}
@@ -0,0 +1,93 @@
+package gitlab_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+)
+
+func clientCertificate(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vault"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestNewGitlabClient_ConnectionSettings(t *testing.T) {
+	certPEM, keyPEM := clientCertificate(t)
+
+	var header string
+	var peerCerts int
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header, peerCerts = r.Header.Get("X-Proxy-Auth"), len(r.TLS.PeerCertificates)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":"17.0.0"}`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	config := &modelConfig.EntryConfig{
+		BaseURL:        srv.URL,
+		Token:          "glpat-token",
+		CACert:         caPEM,
+		ClientCert:     certPEM,
+		ClientKey:      keyPEM,
+		RequestTimeout: 5 * time.Second,
+		Headers:        map[string]string{"X-Proxy-Auth": "secret"},
+	}
+
+	t.Run("trusted with a client certificate", func(t *testing.T) {
+		client, err := gitlab.NewGitlabClient(config, nil, nil)
+		require.NoError(t, err)
+		metadata, err := client.Metadata(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "17.0.0", metadata.Version)
+		assert.Equal(t, "secret", header)
+		assert.Equal(t, 1, peerCerts)
+	})
+
+	t.Run("without the ca", func(t *testing.T) {
+		var untrusted = *config
+		untrusted.CACert = ""
+		client, err := gitlab.NewGitlabClient(&untrusted, nil, nil)
+		require.NoError(t, err)
+		_, err = client.Metadata(t.Context())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		var invalid = *config
+		invalid.ClientKey = ""
+		_, err := gitlab.NewGitlabClient(&invalid, nil, nil)
+		require.Error(t, err)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
 	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
 	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
 )
@@ -27,6 +29,7 @@ type gitlabClient struct {
 	httpClient *http.Client
 	config     *modelConfig.EntryConfig
 	logger     hclog.Logger
+	limits     *requestLimits
 }
 
 func (gc *gitlabClient) GetProjectIdByPath(ctx context.Context, path string) (projectId int64, err error) {
@@ -288,10 +291,7 @@ func (gc *gitlabClient) RevokeUserServiceAccountAccessToken(ctx context.Context,
 	}
 
 	var c *g.Client
-	if c, err = newGitlabClient(&modelConfig.EntryConfig{
-		BaseURL: gc.config.BaseURL,
-		Token:   token,
-	}, gc.httpClient); err == nil {
+	if c, err = newGitlabClient(gc.configWithToken(token), gc.httpClient, gc.logger, gc.limits); err == nil {
 		_, err = c.PersonalAccessTokens.RevokePersonalAccessTokenSelf(g.WithContext(ctx))
 	}
 
@@ -306,10 +306,7 @@ func (gc *gitlabClient) RevokeGroupServiceAccountAccessToken(ctx context.Context
 	}
 
 	var c *g.Client
-	if c, err = newGitlabClient(&modelConfig.EntryConfig{
-		BaseURL: gc.config.BaseURL,
-		Token:   token,
-	}, gc.httpClient); err == nil {
+	if c, err = newGitlabClient(gc.configWithToken(token), gc.httpClient, gc.logger, gc.limits); err == nil {
 		_, err = c.PersonalAccessTokens.RevokePersonalAccessTokenSelf(g.WithContext(ctx))
 	}
 
@@ -324,10 +321,7 @@ func (gc *gitlabClient) RevokeProjectServiceAccountAccessToken(ctx context.Conte
 	}
 
 	var c *g.Client
-	if c, err = newGitlabClient(&modelConfig.EntryConfig{
-		BaseURL: gc.config.BaseURL,
-		Token:   token,
-	}, gc.httpClient); err == nil {
+	if c, err = newGitlabClient(gc.configWithToken(token), gc.httpClient, gc.logger, gc.limits); err == nil {
 		_, err = c.PersonalAccessTokens.RevokePersonalAccessTokenSelf(g.WithContext(ctx))
 	}
 
@@ -423,6 +417,144 @@ func (gc *gitlabClient) RotateCurrentToken(ctx context.Context) (token *modelTok
 	return token, currentEntryToken, err
 }
 
+func (gc *gitlabClient) RotatePersonalAccessToken(ctx context.Context, username string, tokenId int64, expiresAt time.Time) (et *modelToken.TokenPersonal, err error) {
+	var pat *g.PersonalAccessToken
+	defer func() {
+		gc.logger.Debug("Rotate personal access token", "et", et, "username", username, "tokenId", tokenId, "expiresAt", expiresAt, "error", err)
+	}()
+	if pat, _, err = gc.client.PersonalAccessTokens.RotatePersonalAccessTokenByID(tokenId, &g.RotatePersonalAccessTokenOptions{
+		ExpiresAt: (*g.ISOTime)(&expiresAt),
+	}, g.WithContext(ctx)); err == nil {
+		et = &modelToken.TokenPersonal{
+			TokenWithScopes: modelToken.TokenWithScopes{
+				Token: modelToken.Token{
+					TokenID:   pat.ID,
+					ParentID:  "",
+					Path:      username,
+					Name:      pat.Name,
+					Token:     pat.Token,
+					TokenType: t.TypePersonal,
+					CreatedAt: pat.CreatedAt,
+					ExpiresAt: (*time.Time)(pat.ExpiresAt),
+				},
+				Scopes: pat.Scopes,
+			},
+			UserID: pat.UserID,
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) RotateGroupAccessToken(ctx context.Context, groupId string, tokenId int64, expiresAt time.Time) (et *modelToken.TokenGroup, err error) {
+	var at *g.GroupAccessToken
+	defer func() {
+		gc.logger.Debug("Rotate group access token", "et", et, "groupId", groupId, "tokenId", tokenId, "expiresAt", expiresAt, "error", err)
+	}()
+	if at, _, err = gc.client.GroupAccessTokens.RotateGroupAccessToken(groupId, tokenId, &g.RotateGroupAccessTokenOptions{
+		ExpiresAt: (*g.ISOTime)(&expiresAt),
+	}, g.WithContext(ctx)); err == nil {
+		et = &modelToken.TokenGroup{
+			TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{
+				Token: modelToken.Token{
+					TokenID:   at.ID,
+					ParentID:  groupId,
+					Path:      groupId,
+					Name:      at.Name,
+					Token:     at.Token,
+					TokenType: t.TypeGroup,
+					CreatedAt: at.CreatedAt,
+					ExpiresAt: (*time.Time)(at.ExpiresAt),
+				},
+				Scopes: at.Scopes,
+			},
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) RotateProjectAccessToken(ctx context.Context, projectId string, tokenId int64, expiresAt time.Time) (et *modelToken.TokenProject, err error) {
+	var at *g.ProjectAccessToken
+	defer func() {
+		gc.logger.Debug("Rotate project access token", "et", et, "projectId", projectId, "tokenId", tokenId, "expiresAt", expiresAt, "error", err)
+	}()
+	if at, _, err = gc.client.ProjectAccessTokens.RotateProjectAccessToken(projectId, tokenId, &g.RotateProjectAccessTokenOptions{
+		ExpiresAt: (*g.ISOTime)(&expiresAt),
+	}, g.WithContext(ctx)); err == nil {
+		et = &modelToken.TokenProject{
+			TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{
+				Token: modelToken.Token{
+					TokenID:   at.ID,
+					ParentID:  projectId,
+					Path:      projectId,
+					Name:      at.Name,
+					Token:     at.Token,
+					TokenType: t.TypeProject,
+					CreatedAt: at.CreatedAt,
+					ExpiresAt: (*time.Time)(at.ExpiresAt),
+				},
+				Scopes: at.Scopes,
+			},
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) RotateGroupServiceAccountAccessToken(ctx context.Context, path string, groupId string, userId int64, tokenId int64, expiresAt time.Time) (et *modelToken.TokenGroupServiceAccount, err error) {
+	var pat *g.PersonalAccessToken
+	defer func() {
+		gc.logger.Debug("Rotate group service account access token", "et", et, "path", path, "groupId", groupId, "userId", userId, "tokenId", tokenId, "expiresAt", expiresAt, "error", err)
+	}()
+	if pat, _, err = gc.client.Groups.RotateServiceAccountPersonalAccessToken(groupId, userId, tokenId, &g.RotateServiceAccountPersonalAccessTokenOptions{
+		ExpiresAt: (*g.ISOTime)(&expiresAt),
+	}, g.WithContext(ctx)); err == nil {
+		et = &modelToken.TokenGroupServiceAccount{
+			TokenWithScopes: modelToken.TokenWithScopes{
+				Token: modelToken.Token{
+					TokenID:   pat.ID,
+					ParentID:  groupId,
+					Path:      path,
+					Name:      pat.Name,
+					Token:     pat.Token,
+					TokenType: t.TypeGroupServiceAccount,
+					CreatedAt: pat.CreatedAt,
+					ExpiresAt: (*time.Time)(pat.ExpiresAt),
+				},
+				Scopes: pat.Scopes,
+			},
+			UserID: userId,
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) RotateProjectServiceAccountAccessToken(ctx context.Context, path string, projectId string, userId int64, tokenId int64, expiresAt time.Time) (et *modelToken.TokenProjectServiceAccount, err error) {
+	var pat *g.PersonalAccessToken
+	defer func() {
+		gc.logger.Debug("Rotate project service account access token", "et", et, "path", path, "projectId", projectId, "userId", userId, "tokenId", tokenId, "expiresAt", expiresAt, "error", err)
+	}()
+	if pat, _, err = gc.client.Projects.RotateProjectServiceAccountPersonalAccessToken(projectId, userId, tokenId, &g.RotateProjectServiceAccountPersonalAccessTokenOptions{
+		ExpiresAt: (*g.ISOTime)(&expiresAt),
+	}, g.WithContext(ctx)); err == nil {
+		et = &modelToken.TokenProjectServiceAccount{
+			TokenWithScopes: modelToken.TokenWithScopes{
+				Token: modelToken.Token{
+					TokenID:   pat.ID,
+					ParentID:  projectId,
+					Path:      path,
+					Name:      pat.Name,
+					Token:     pat.Token,
+					TokenType: t.TypeProjectServiceAccount,
+					CreatedAt: pat.CreatedAt,
+					ExpiresAt: (*time.Time)(pat.ExpiresAt),
+				},
+				Scopes: pat.Scopes,
+			},
+			UserID: userId,
+		}
+	}
+	return et, err
+}
+
 func (gc *gitlabClient) GetUserIdByUsername(ctx context.Context, username string) (userId int64, err error) {
 	defer func() {
 		gc.logger.Debug("Get user id by username", "username", username, "userId", userId, "error", err)
@@ -472,6 +604,35 @@ func (gc *gitlabClient) CreatePersonalAccessToken(ctx context.Context, username
 	return et, err
 }
 
+func (gc *gitlabClient) CreateImpersonationToken(ctx context.Context, username string, userId int64, name string, expiresAt time.Time, scopes []string) (et *modelToken.TokenImpersonation, err error) {
+	var at *g.ImpersonationToken
+	defer func() {
+		gc.logger.Debug("Create impersonation token", "et", et, "username", username, "userId", userId, "name", name, "expiresAt", expiresAt, "scopes", scopes, "error", err)
+	}()
+	if at, _, err = gc.client.Users.CreateImpersonationToken(userId, &g.CreateImpersonationTokenOptions{
+		Name:      g.Ptr(name),
+		ExpiresAt: &expiresAt,
+		Scopes:    &scopes,
+	}, g.WithContext(ctx)); err == nil {
+		et = &modelToken.TokenImpersonation{
+			TokenWithScopes: modelToken.TokenWithScopes{
+				Token: modelToken.Token{
+					TokenID:   at.ID,
+					Path:      username,
+					Name:      name,
+					Token:     at.Token,
+					TokenType: t.TypeImpersonation,
+					CreatedAt: at.CreatedAt,
+					ExpiresAt: (*time.Time)(at.ExpiresAt),
+				},
+				Scopes: scopes,
+			},
+			UserID: userId,
+		}
+	}
+	return et, err
+}
+
 func (gc *gitlabClient) CreateGroupAccessToken(ctx context.Context, groupId string, name string, expiresAt time.Time, scopes []string, accessLevel t.AccessLevel) (et *modelToken.TokenGroup, err error) {
 	var at *g.GroupAccessToken
 	defer func() {
@@ -553,6 +714,18 @@ func (gc *gitlabClient) RevokePersonalAccessToken(ctx context.Context, tokenId i
 	return nil
 }
 
+func (gc *gitlabClient) RevokeImpersonationToken(ctx context.Context, userId, tokenId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Revoke impersonation token", "userId", userId, "tokenId", tokenId, "error", err)
+	}()
+	var resp *g.Response
+	resp, err = gc.client.Users.RevokeImpersonationToken(userId, tokenId, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("impersonation: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
+
 func (gc *gitlabClient) RevokeProjectAccessToken(ctx context.Context, tokenId int64, projectId string) (err error) {
 	defer func() {
 		gc.logger.Debug("Revoke project access token", "tokenId", tokenId, "error", err)
@@ -583,13 +756,107 @@ func (gc *gitlabClient) RevokeGroupAccessToken(ctx context.Context, tokenId int6
 	return nil
 }
 
+func (gc *gitlabClient) ListPersonalAccessTokens(ctx context.Context, username string, userId int64) (tokens []*modelToken.TokenPersonal, err error) {
+	defer func() {
+		gc.logger.Debug("List personal access tokens", "username", username, "userId", userId, "count", len(tokens), "error", err)
+	}()
+	var opts = &g.ListPersonalAccessTokensOptions{UserID: g.Ptr(userId), State: g.Ptr(string(g.AccessTokenStateActive))}
+	var pats []*g.PersonalAccessToken
+	if pats, err = g.ScanAndCollect(func(p g.PaginationOptionFunc) ([]*g.PersonalAccessToken, *g.Response, error) {
+		return gc.client.PersonalAccessTokens.ListPersonalAccessTokens(opts, p, g.WithContext(ctx))
+	}); err != nil {
+		return nil, err
+	}
+	for _, pat := range pats {
+		tokens = append(tokens, &modelToken.TokenPersonal{
+			TokenWithScopes: modelToken.TokenWithScopes{
+				Token: modelToken.Token{
+					TokenID:   pat.ID,
+					Path:      username,
+					Name:      pat.Name,
+					TokenType: t.TypePersonal,
+					CreatedAt: pat.CreatedAt,
+					ExpiresAt: (*time.Time)(pat.ExpiresAt),
+				},
+				Scopes: pat.Scopes,
+			},
+			UserID: pat.UserID,
+		})
+	}
+	return tokens, nil
+}
+
+func (gc *gitlabClient) ListProjectAccessTokens(ctx context.Context, projectId string) (tokens []*modelToken.TokenProject, err error) {
+	defer func() {
+		gc.logger.Debug("List project access tokens", "projectId", projectId, "count", len(tokens), "error", err)
+	}()
+	var opts = &g.ListProjectAccessTokensOptions{State: g.Ptr(string(g.AccessTokenStateActive))}
+	var ats []*g.ProjectAccessToken
+	if ats, err = g.ScanAndCollect(func(p g.PaginationOptionFunc) ([]*g.ProjectAccessToken, *g.Response, error) {
+		return gc.client.ProjectAccessTokens.ListProjectAccessTokens(projectId, opts, p, g.WithContext(ctx))
+	}); err != nil {
+		return nil, err
+	}
+	for _, at := range ats {
+		tokens = append(tokens, &modelToken.TokenProject{
+			TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{
+				Token: modelToken.Token{
+					TokenID:   at.ID,
+					ParentID:  projectId,
+					Path:      projectId,
+					Name:      at.Name,
+					TokenType: t.TypeProject,
+					CreatedAt: at.CreatedAt,
+					ExpiresAt: (*time.Time)(at.ExpiresAt),
+				},
+				Scopes: at.Scopes,
+			},
+		})
+	}
+	return tokens, nil
+}
+
+func (gc *gitlabClient) ListGroupAccessTokens(ctx context.Context, groupId string) (tokens []*modelToken.TokenGroup, err error) {
+	defer func() {
+		gc.logger.Debug("List group access tokens", "groupId", groupId, "count", len(tokens), "error", err)
+	}()
+	var opts = &g.ListGroupAccessTokensOptions{State: g.Ptr(g.AccessTokenStateActive)}
+	var ats []*g.GroupAccessToken
+	if ats, err = g.ScanAndCollect(func(p g.PaginationOptionFunc) ([]*g.GroupAccessToken, *g.Response, error) {
+		return gc.client.GroupAccessTokens.ListGroupAccessTokens(groupId, opts, p, g.WithContext(ctx))
+	}); err != nil {
+		return nil, err
+	}
+	for _, at := range ats {
+		tokens = append(tokens, &modelToken.TokenGroup{
+			TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{
+				Token: modelToken.Token{
+					TokenID:   at.ID,
+					ParentID:  groupId,
+					Path:      groupId,
+					Name:      at.Name,
+					TokenType: t.TypeGroup,
+					CreatedAt: at.CreatedAt,
+					ExpiresAt: (*time.Time)(at.ExpiresAt),
+				},
+				Scopes: at.Scopes,
+			},
+		})
+	}
+	return tokens, nil
+}
+
 func (gc *gitlabClient) Valid(ctx context.Context) bool {
 	return gc.client != nil && gc.config != nil
 }
 
+func (gc *gitlabClient) Saturation() Saturation {
+	return gc.limits.saturation()
+}
+
 var _ Client = new(gitlabClient)
 
-func newGitlabClient(config *modelConfig.EntryConfig, httpClient *http.Client) (gc *g.Client, err error) {
+func newGitlabClient(config *modelConfig.EntryConfig, httpClient *http.Client, logger hclog.Logger, limits *requestLimits) (gc *g.Client, err error) {
 	if strings.TrimSpace(config.BaseURL) == "" {
 		err = errors.Join(err, fmt.Errorf("gitlab base url: %w", errs.ErrInvalidValue))
 	}
@@ -604,16 +871,30 @@ func newGitlabClient(config *modelConfig.EntryConfig, httpClient *http.Client) (
 
 	var opts = []g.ClientOptionFunc{
 		g.WithBaseURL(fmt.Sprintf("%s/api/v4", strings.TrimSuffix(config.BaseURL, "/"))),
+		// the limits of the config are applied to every attempt by the transport, see requestLimits
 		g.WithCustomLimiter(rate.NewLimiter(rate.Inf, 0)),
 	}
+	opts = append(opts, newRetryPolicy(config, logger).options()...)
 
-	if httpClient != nil {
+	if httpClient = limits.wrap(httpClient); httpClient != nil {
 		opts = append(opts, g.WithHTTPClient(httpClient))
 	}
 
+	if len(config.Headers) > 0 {
+		opts = append(opts, g.WithRequestOptions(g.WithHeaders(config.Headers)))
+	}
+
 	return g.NewClient(config.Token, opts...)
 }
 
+// configWithToken returns the config of the client with another token, the requests made with it go through the
+// same connection settings.
+func (gc *gitlabClient) configWithToken(token string) *modelConfig.EntryConfig {
+	var config = *gc.config
+	config.Token = token
+	return &config
+}
+
 func NewGitlabClient(config *modelConfig.EntryConfig, httpClient *http.Client, logger hclog.Logger) (client Client, err error) {
 	if config == nil {
 		return nil, fmt.Errorf("configure the backend first, config: %w", errs.ErrNilValue)
@@ -623,10 +904,672 @@ func NewGitlabClient(config *modelConfig.EntryConfig, httpClient *http.Client, l
 		logger = logging.NewVaultLoggerWithWriter(io.Discard, hclog.NoLevel)
 	}
 
+	// an http client from the context is used as is, it's how the tests talk to GitLab
+	if httpClient == nil {
+		if httpClient, err = newHTTPClient(config); err != nil {
+			return nil, err
+		}
+	}
+
 	var gc *g.Client
-	if gc, err = newGitlabClient(config, httpClient); err != nil {
+	var limits = newRequestLimits(config)
+	if gc, err = newGitlabClient(config, httpClient, logger, limits); err != nil {
+		return nil, err
+	}
+
+	return &gitlabClient{client: gc, config: config, logger: logger, httpClient: httpClient, limits: limits}, err
+}
+
+func (gc *gitlabClient) CreateGroupServiceAccount(ctx context.Context, groupId string, name string) (userId int64, username string, err error) {
+	var sa *g.GroupServiceAccount
+	defer func() {
+		gc.logger.Debug("Create group service account", "groupId", groupId, "name", name, "userId", userId, "username", username, "error", err)
+	}()
+	if sa, _, err = gc.client.Groups.CreateServiceAccount(groupId, &g.CreateServiceAccountOptions{
+		Name: g.Ptr(name),
+	}, g.WithContext(ctx)); err == nil {
+		userId, username = sa.ID, sa.UserName
+	}
+	return userId, username, err
+}
+
+func (gc *gitlabClient) CreateUserServiceAccount(ctx context.Context, name string) (userId int64, username string, err error) {
+	var usr *g.User
+	defer func() {
+		gc.logger.Debug("Create user service account", "name", name, "userId", userId, "username", username, "error", err)
+	}()
+	if usr, _, err = gc.client.Users.CreateServiceAccountUser(&g.CreateServiceAccountUserOptions{
+		Name: g.Ptr(name),
+	}, g.WithContext(ctx)); err == nil {
+		userId, username = usr.ID, usr.Username
+	}
+	return userId, username, err
+}
+
+func (gc *gitlabClient) DeleteGroupServiceAccount(ctx context.Context, groupId string, userId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Delete group service account", "groupId", groupId, "userId", userId, "error", err)
+	}()
+	var resp *g.Response
+	resp, err = gc.client.Groups.DeleteServiceAccount(groupId, userId, &g.DeleteServiceAccountOptions{
+		HardDelete: g.Ptr(true),
+	}, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("group service account: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
+
+func (gc *gitlabClient) DeleteUserServiceAccount(ctx context.Context, userId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Delete user service account", "userId", userId, "error", err)
+	}()
+	var resp *g.Response
+	resp, err = gc.client.Users.DeleteUser(userId, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("user service account: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
+
+// AddMember adds the user as a member of the project at path, if there is no such project
+// the user is added as a member of the group at path instead.
+func (gc *gitlabClient) AddMember(ctx context.Context, path string, userId int64, accessLevel t.AccessLevel, expiresAt *time.Time) (err error) {
+	defer func() {
+		gc.logger.Debug("Add member", "path", path, "userId", userId, "accessLevel", accessLevel, "expiresAt", expiresAt, "error", err)
+	}()
+	var al = g.Ptr(g.AccessLevelValue(accessLevel.Value()))
+	var expires *string
+	if expiresAt != nil {
+		expires = g.Ptr(expiresAt.Format(time.DateOnly))
+	}
+
+	var resp *g.Response
+	if _, resp, err = gc.client.ProjectMembers.AddProjectMember(path, &g.AddProjectMemberOptions{
+		UserID:      userId,
+		AccessLevel: al,
+		ExpiresAt:   expires,
+	}, g.WithContext(ctx)); resp == nil || resp.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	_, _, err = gc.client.GroupMembers.AddGroupMember(path, &g.AddGroupMemberOptions{
+		UserID:      g.Ptr(userId),
+		AccessLevel: al,
+		ExpiresAt:   expires,
+	}, g.WithContext(ctx))
+	return err
+}
+
+// RemoveMember removes the user as a member of the project at path, if there is no such project
+// the user is removed as a member of the group at path instead.
+func (gc *gitlabClient) RemoveMember(ctx context.Context, path string, userId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Remove member", "path", path, "userId", userId, "error", err)
+	}()
+
+	var resp *g.Response
+	if resp, err = gc.client.ProjectMembers.DeleteProjectMember(path, userId, g.WithContext(ctx)); resp == nil || resp.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	resp, err = gc.client.GroupMembers.RemoveGroupMember(path, userId, &g.RemoveGroupMemberOptions{}, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("membership: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
+
+// runnerTypes maps the runner token types to the runner_type of the GitLab API.
+var runnerTypes = map[t.Type]string{
+	t.TypeInstanceRunner: "instance_type",
+	t.TypeGroupRunner:    "group_type",
+	t.TypeProjectRunner:  "project_type",
+}
+
+func (gc *gitlabClient) CreateRunner(ctx context.Context, tokenType t.Type, path string, parentId int64, name string, description string, tags []string, runUntagged bool, locked bool) (et *modelToken.TokenRunner, err error) {
+	var runner *g.UserRunner
+	defer func() {
+		gc.logger.Debug("Create runner", "tokenType", tokenType, "path", path, "parentId", parentId, "name", name, "description", description, "tags", tags, "runUntagged", runUntagged, "locked", locked, "error", err)
+	}()
+
+	runnerType, ok := runnerTypes[tokenType]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", tokenType, errs.ErrUnknownTokenType)
+	}
+
+	var opts = &g.CreateUserRunnerOptions{
+		RunnerType:  g.Ptr(runnerType),
+		Description: g.Ptr(description),
+		RunUntagged: g.Ptr(runUntagged),
+		Locked:      g.Ptr(locked),
+		TagList:     &tags,
+	}
+	switch tokenType {
+	case t.TypeGroupRunner:
+		opts.GroupID = g.Ptr(parentId)
+	case t.TypeProjectRunner:
+		opts.ProjectID = g.Ptr(parentId)
+	}
+
+	if runner, _, err = gc.client.Users.CreateUserRunner(opts, g.WithContext(ctx)); err == nil {
+		var parent string
+		if parentId > 0 {
+			parent = strconv.FormatInt(parentId, 10)
+		}
+		et = &modelToken.TokenRunner{
+			Token: modelToken.Token{
+				TokenID:   runner.ID,
+				ParentID:  parent,
+				Path:      path,
+				Name:      name,
+				Token:     runner.Token,
+				TokenType: tokenType,
+				CreatedAt: g.Ptr(time.Now()),
+				ExpiresAt: runner.TokenExpiresAt,
+			},
+			Description: description,
+			Tags:        tags,
+			RunUntagged: runUntagged,
+			Locked:      locked,
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) DeleteRunner(ctx context.Context, runnerId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Delete runner", "runnerId", runnerId, "error", err)
+	}()
+	var resp *g.Response
+	resp, err = gc.client.Runners.DeleteRegisteredRunnerByID(runnerId, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("runner: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
+
+func (gc *gitlabClient) CreateClusterAgentToken(ctx context.Context, path string, projectId int64, agentName string, createAgent bool, name string) (et *modelToken.TokenClusterAgent, err error) {
+	var agent *g.Agent
+	var at *g.AgentToken
+	defer func() {
+		gc.logger.Debug("Create cluster agent token", "path", path, "projectId", projectId, "agentName", agentName, "createAgent", createAgent, "name", name, "error", err)
+	}()
+
+	var agents []*g.Agent
+	if agents, err = g.ScanAndCollect(func(p g.PaginationOptionFunc) ([]*g.Agent, *g.Response, error) {
+		return gc.client.ClusterAgents.ListAgents(projectId, &g.ListAgentsOptions{}, p, g.WithContext(ctx))
+	}); err != nil {
 		return nil, err
 	}
+	for _, a := range agents {
+		if a.Name == agentName {
+			agent = a
+			break
+		}
+	}
+
+	var agentCreated bool
+	if agent == nil {
+		if !createAgent {
+			return nil, fmt.Errorf("cluster agent %s: %w", agentName, errs.ErrNotFound)
+		}
+		if agent, _, err = gc.client.ClusterAgents.RegisterAgent(projectId, &g.RegisterAgentOptions{Name: &agentName}, g.WithContext(ctx)); err != nil {
+			return nil, err
+		}
+		agentCreated = true
+	}
+
+	if at, _, err = gc.client.ClusterAgents.CreateAgentToken(
+		projectId,
+		agent.ID,
+		&g.CreateAgentTokenOptions{Name: &name},
+		g.WithContext(ctx),
+	); err == nil {
+		et = &modelToken.TokenClusterAgent{
+			Token: modelToken.Token{
+				TokenID:   at.ID,
+				ParentID:  strconv.FormatInt(projectId, 10),
+				Path:      path,
+				Name:      name,
+				Token:     at.Token,
+				TokenType: t.TypeClusterAgent,
+				CreatedAt: g.Ptr(time.Now()),
+			},
+			AgentID:      agent.ID,
+			AgentName:    agent.Name,
+			AgentCreated: agentCreated,
+		}
+	}
+	return et, err
+}
+
+// RevokeClusterAgentToken revokes the token of the agent, with deleteAgent the agent is deleted as well once it has
+// no active tokens left. A token that was already revoked, by an earlier attempt that failed to clean up the agent,
+// still cleans up the agent, errs.ErrAccessTokenNotFound is only returned when there is nothing left to do.
+func (gc *gitlabClient) RevokeClusterAgentToken(ctx context.Context, projectId, agentId, tokenId int64, deleteAgent bool) (err error) {
+	var deleted bool
+	defer func() {
+		gc.logger.Debug("Revoke cluster agent token", "projectId", projectId, "agentId", agentId, "tokenId", tokenId, "agentDeleted", deleted, "error", err)
+	}()
+
+	var resp *g.Response
+	var revoked = true
+	resp, err = gc.client.ClusterAgents.RevokeAgentToken(projectId, agentId, tokenId, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		revoked, err = false, nil
+	}
+	var done = func() error {
+		if revoked {
+			return nil
+		}
+		return fmt.Errorf("cluster agent token: %w", errs.ErrAccessTokenNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if !deleteAgent {
+		return done()
+	}
+
+	var tokens []*g.AgentToken
+	if tokens, err = g.ScanAndCollect(func(p g.PaginationOptionFunc) ([]*g.AgentToken, *g.Response, error) {
+		var at, r, e = gc.client.ClusterAgents.ListAgentTokens(projectId, agentId, &g.ListAgentTokensOptions{}, p, g.WithContext(ctx))
+		resp = r
+		return at, r, e
+	}); err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// the agent is gone already
+			return done()
+		}
+		return err
+	}
+	for _, at := range tokens {
+		if at.ID != tokenId && at.Status == "active" {
+			return done()
+		}
+	}
+
+	if resp, err = gc.client.ClusterAgents.DeleteAgent(projectId, agentId, g.WithContext(ctx)); resp != nil && resp.StatusCode == http.StatusNotFound {
+		return done()
+	}
+	deleted = err == nil
+	return err
+}
+
+func (gc *gitlabClient) CreateProjectDeployKey(ctx context.Context, path string, projectId int64, name string, publicKey string, canPush bool, expiresAt *time.Time) (et *modelToken.TokenProjectDeployKey, err error) {
+	var dk *g.ProjectDeployKey
+	defer func() {
+		gc.logger.Debug("Create project deploy key", "projectId", projectId, "name", name, "path", path, "canPush", canPush, "expiresAt", expiresAt, "error", err)
+	}()
+	if dk, _, err = gc.client.DeployKeys.AddDeployKey(
+		projectId,
+		&g.AddDeployKeyOptions{
+			Title:     &name,
+			Key:       &publicKey,
+			CanPush:   &canPush,
+			ExpiresAt: expiresAt,
+		},
+		g.WithContext(ctx),
+	); err == nil {
+		et = &modelToken.TokenProjectDeployKey{
+			TokenSSHKey: modelToken.TokenSSHKey{
+				Token: modelToken.Token{
+					TokenID:   dk.ID,
+					ParentID:  strconv.FormatInt(projectId, 10),
+					Path:      path,
+					Name:      name,
+					TokenType: t.TypeProjectDeployKey,
+					CreatedAt: g.Ptr(time.Now()),
+					ExpiresAt: dk.ExpiresAt,
+				},
+				PublicKey:   publicKey,
+				Fingerprint: dk.FingerprintSHA256,
+			},
+			CanPush: dk.CanPush,
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) RevokeProjectDeployKey(ctx context.Context, projectId, deployKeyId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Revoke project deploy key", "projectId", projectId, "deployKeyId", deployKeyId, "error", err)
+	}()
+
+	var resp *g.Response
+	resp, err = gc.client.DeployKeys.DeleteDeployKey(projectId, deployKeyId, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("deploy key: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
 
-	return &gitlabClient{client: gc, config: config, logger: logger, httpClient: httpClient}, err
+func (gc *gitlabClient) CreateUserSSHKey(ctx context.Context, username string, userId int64, name string, publicKey string, expiresAt *time.Time) (et *modelToken.TokenUserSSHKey, err error) {
+	var key *g.SSHKey
+	defer func() {
+		gc.logger.Debug("Create user SSH key", "username", username, "userId", userId, "name", name, "expiresAt", expiresAt, "error", err)
+	}()
+	var opts = &g.AddSSHKeyOptions{Title: &name, Key: &publicKey}
+	if expiresAt != nil {
+		opts.ExpiresAt = g.Ptr(g.ISOTime(*expiresAt))
+	}
+	if key, _, err = gc.client.Users.AddSSHKeyForUser(userId, opts, g.WithContext(ctx)); err == nil {
+		et = &modelToken.TokenUserSSHKey{
+			TokenSSHKey: modelToken.TokenSSHKey{
+				Token: modelToken.Token{
+					TokenID:   key.ID,
+					Path:      username,
+					Name:      name,
+					TokenType: t.TypeUserSSHKey,
+					CreatedAt: g.Ptr(time.Now()),
+					ExpiresAt: key.ExpiresAt,
+				},
+				PublicKey: publicKey,
+			},
+			UserID:   userId,
+			Username: username,
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) RevokeUserSSHKey(ctx context.Context, userId, keyId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Revoke user SSH key", "userId", userId, "keyId", keyId, "error", err)
+	}()
+
+	var resp *g.Response
+	resp, err = gc.client.Users.DeleteSSHKeyForUser(userId, keyId, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("ssh key: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
+
+func (gc *gitlabClient) CreateOAuthApplication(ctx context.Context, path string, name string, redirectURIs []string, scopes []string, confidential bool) (et *modelToken.TokenOAuthApplication, err error) {
+	var app *g.Application
+	defer func() {
+		gc.logger.Debug("Create OAuth application", "path", path, "name", name, "redirectURIs", redirectURIs, "scopes", scopes, "confidential", confidential, "error", err)
+	}()
+	if app, _, err = gc.client.Applications.CreateApplication(
+		&g.CreateApplicationOptions{
+			Name:         &name,
+			RedirectURI:  g.Ptr(strings.Join(redirectURIs, "\n")),
+			Scopes:       g.Ptr(strings.Join(scopes, " ")),
+			Confidential: &confidential,
+		},
+		g.WithContext(ctx),
+	); err == nil {
+		et = &modelToken.TokenOAuthApplication{
+			TokenWithScopes: modelToken.TokenWithScopes{
+				Token: modelToken.Token{
+					TokenID:   app.ID,
+					Path:      path,
+					Name:      name,
+					Token:     app.Secret,
+					TokenType: t.TypeOAuthApplication,
+					CreatedAt: g.Ptr(time.Now()),
+				},
+				Scopes: scopes,
+			},
+			ClientID:     app.ApplicationID,
+			RedirectURIs: redirectURIs,
+			Confidential: app.Confidential,
+			Mode:         t.OAuthApplicationModeCreate,
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) RenewOAuthApplicationSecret(ctx context.Context, path string, applicationId int64) (et *modelToken.TokenOAuthApplication, err error) {
+	var app *g.Application
+	var resp *g.Response
+	defer func() {
+		gc.logger.Debug("Renew OAuth application secret", "path", path, "applicationId", applicationId, "error", err)
+	}()
+	app, resp, err = gc.client.Applications.RenewApplicationSecret(applicationId, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("oauth application: %w", errs.ErrAccessTokenNotFound)
+	}
+	if err == nil {
+		var redirectURIs []string
+		if app.CallbackURL != "" {
+			redirectURIs = strings.Fields(app.CallbackURL)
+		}
+		et = &modelToken.TokenOAuthApplication{
+			TokenWithScopes: modelToken.TokenWithScopes{
+				Token: modelToken.Token{
+					TokenID:   app.ID,
+					Path:      path,
+					Name:      app.ApplicationName,
+					Token:     app.Secret,
+					TokenType: t.TypeOAuthApplication,
+					CreatedAt: g.Ptr(time.Now()),
+				},
+				Scopes: app.Scopes,
+			},
+			ClientID:     app.ApplicationID,
+			RedirectURIs: redirectURIs,
+			Confidential: app.Confidential,
+			Mode:         t.OAuthApplicationModeRotate,
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) DeleteOAuthApplication(ctx context.Context, applicationId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Delete OAuth application", "applicationId", applicationId, "error", err)
+	}()
+	var resp *g.Response
+	resp, err = gc.client.Applications.DeleteApplication(applicationId, g.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("oauth application: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
+
+// AddJobTokenAllowlistEntry adds the project or group to the job token allowlist of the project. An entry that is
+// already in the allowlist is refused, so it is never removed when the lease is revoked.
+func (gc *gitlabClient) AddJobTokenAllowlistEntry(ctx context.Context, tokenType t.Type, path string, projectId int64, allowedId int64) (et *modelToken.TokenJobTokenAllowlist, err error) {
+	defer func() {
+		gc.logger.Debug("Add job token allowlist entry", "tokenType", tokenType, "path", path, "projectId", projectId, "allowedId", allowedId, "error", err)
+	}()
+
+	var exists bool
+	switch tokenType {
+	case t.TypeProjectJobTokenAllowlist:
+		var projects []*g.Project
+		if projects, err = g.ScanAndCollect(func(p g.PaginationOptionFunc) ([]*g.Project, *g.Response, error) {
+			return gc.client.JobTokenScope.GetProjectJobTokenInboundAllowList(projectId, &g.GetJobTokenInboundAllowListOptions{}, p, g.WithContext(ctx))
+		}); err != nil {
+			return nil, err
+		}
+		exists = slices.ContainsFunc(projects, func(prj *g.Project) bool { return prj.ID == allowedId })
+	case t.TypeGroupJobTokenAllowlist:
+		var groups []*g.Group
+		if groups, err = g.ScanAndCollect(func(p g.PaginationOptionFunc) ([]*g.Group, *g.Response, error) {
+			return gc.client.JobTokenScope.GetJobTokenAllowlistGroups(projectId, &g.GetJobTokenAllowlistGroupsOptions{}, p, g.WithContext(ctx))
+		}); err != nil {
+			return nil, err
+		}
+		exists = slices.ContainsFunc(groups, func(grp *g.Group) bool { return grp.ID == allowedId })
+	default:
+		return nil, fmt.Errorf("%s: %w", tokenType, errs.ErrUnknownTokenType)
+	}
+	if exists {
+		return nil, fmt.Errorf("%d is already in the job token allowlist of project %d: %w", allowedId, projectId, errs.ErrInvalidValue)
+	}
+
+	if tokenType == t.TypeProjectJobTokenAllowlist {
+		_, _, err = gc.client.JobTokenScope.AddProjectToJobScopeAllowList(projectId, &g.JobTokenInboundAllowOptions{TargetProjectID: g.Ptr(allowedId)}, g.WithContext(ctx))
+	} else {
+		_, _, err = gc.client.JobTokenScope.AddGroupToJobTokenAllowlist(projectId, &g.AddGroupToJobTokenAllowlistOptions{TargetGroupID: g.Ptr(allowedId)}, g.WithContext(ctx))
+	}
+	if err == nil {
+		et = &modelToken.TokenJobTokenAllowlist{
+			Token: modelToken.Token{
+				TokenID:   allowedId,
+				ParentID:  strconv.FormatInt(projectId, 10),
+				Path:      path,
+				TokenType: tokenType,
+				CreatedAt: g.Ptr(time.Now()),
+			},
+			ProjectID: projectId,
+			AllowedID: allowedId,
+		}
+	}
+	return et, err
+}
+
+func (gc *gitlabClient) RemoveJobTokenAllowlistEntry(ctx context.Context, tokenType t.Type, projectId int64, allowedId int64) (err error) {
+	defer func() {
+		gc.logger.Debug("Remove job token allowlist entry", "tokenType", tokenType, "projectId", projectId, "allowedId", allowedId, "error", err)
+	}()
+
+	var resp *g.Response
+	switch tokenType {
+	case t.TypeProjectJobTokenAllowlist:
+		resp, err = gc.client.JobTokenScope.RemoveProjectFromJobScopeAllowList(projectId, allowedId, g.WithContext(ctx))
+	case t.TypeGroupJobTokenAllowlist:
+		resp, err = gc.client.JobTokenScope.RemoveGroupFromJobTokenAllowlist(projectId, allowedId, g.WithContext(ctx))
+	default:
+		return fmt.Errorf("%s: %w", tokenType, errs.ErrUnknownTokenType)
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("job token allowlist entry: %w", errs.ErrAccessTokenNotFound)
+	}
+	return err
+}
+
+// GetCIVariable returns the CI/CD variable of the project or group with the key and environment scope, a variable
+// that does not exist returns errs.ErrNotFound.
+func (gc *gitlabClient) GetCIVariable(ctx context.Context, target string, path string, key string, environmentScope string) (v *variable.Variable, err error) {
+	defer func() {
+		gc.logger.Debug("Get CI/CD variable", "target", target, "path", path, "key", key, "environmentScope", environmentScope, "error", err)
+	}()
+
+	var resp *g.Response
+	var filter = &g.VariableFilter{EnvironmentScope: environmentScope}
+	switch target {
+	case variable.TargetProject:
+		var pv *g.ProjectVariable
+		if pv, resp, err = gc.client.ProjectVariables.GetVariable(path, key, &g.GetProjectVariableOptions{Filter: filter}, g.WithContext(ctx)); err == nil {
+			v = &variable.Variable{Key: pv.Key, Value: pv.Value, EnvironmentScope: pv.EnvironmentScope, Protected: pv.Protected, Masked: pv.Masked, Hidden: pv.Hidden,
+				Raw: pv.Raw, VariableType: string(pv.VariableType), Description: pv.Description}
+		}
+	case variable.TargetGroup:
+		var gv *g.GroupVariable
+		if gv, resp, err = gc.client.GroupVariables.GetVariable(path, key, &g.GetGroupVariableOptions{Filter: filter}, g.WithContext(ctx)); err == nil {
+			v = &variable.Variable{Key: gv.Key, Value: gv.Value, EnvironmentScope: gv.EnvironmentScope, Protected: gv.Protected, Masked: gv.Masked, Hidden: gv.Hidden,
+				Raw: gv.Raw, VariableType: string(gv.VariableType), Description: gv.Description}
+		}
+	default:
+		return nil, fmt.Errorf("ci variable target '%s': %w", target, errs.ErrInvalidValue)
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("ci variable %s: %w", key, errs.ErrNotFound)
+	}
+	return v, err
+}
+
+func (gc *gitlabClient) CreateCIVariable(ctx context.Context, target string, path string, v *variable.Variable) (err error) {
+	defer func() {
+		gc.logger.Debug("Create CI/CD variable", "target", target, "path", path, "key", v.Key, "environmentScope", v.EnvironmentScope, "protected", v.Protected, "masked", v.Masked, "error", err)
+	}()
+
+	switch target {
+	case variable.TargetProject:
+		_, _, err = gc.client.ProjectVariables.CreateVariable(path, &g.CreateProjectVariableOptions{
+			Key:              g.Ptr(v.Key),
+			Value:            g.Ptr(v.Value),
+			EnvironmentScope: g.Ptr(v.EnvironmentScope),
+			Protected:        g.Ptr(v.Protected),
+			Masked:           g.Ptr(v.Masked),
+			Raw:              g.Ptr(v.Raw),
+			VariableType:     variableType(v),
+			Description:      g.Ptr(v.Description),
+		}, g.WithContext(ctx))
+	case variable.TargetGroup:
+		_, _, err = gc.client.GroupVariables.CreateVariable(path, &g.CreateGroupVariableOptions{
+			Key:              g.Ptr(v.Key),
+			Value:            g.Ptr(v.Value),
+			EnvironmentScope: g.Ptr(v.EnvironmentScope),
+			Protected:        g.Ptr(v.Protected),
+			Masked:           g.Ptr(v.Masked),
+			Raw:              g.Ptr(v.Raw),
+			VariableType:     variableType(v),
+			Description:      g.Ptr(v.Description),
+		}, g.WithContext(ctx))
+	default:
+		return fmt.Errorf("ci variable target '%s': %w", target, errs.ErrInvalidValue)
+	}
+	return err
+}
+
+func (gc *gitlabClient) UpdateCIVariable(ctx context.Context, target string, path string, v *variable.Variable) (err error) {
+	defer func() {
+		gc.logger.Debug("Update CI/CD variable", "target", target, "path", path, "key", v.Key, "environmentScope", v.EnvironmentScope, "protected", v.Protected, "masked", v.Masked, "error", err)
+	}()
+
+	var resp *g.Response
+	var filter = &g.VariableFilter{EnvironmentScope: v.EnvironmentScope}
+	switch target {
+	case variable.TargetProject:
+		_, resp, err = gc.client.ProjectVariables.UpdateVariable(path, v.Key, &g.UpdateProjectVariableOptions{
+			Value:        g.Ptr(v.Value),
+			Filter:       filter,
+			Protected:    g.Ptr(v.Protected),
+			Masked:       g.Ptr(v.Masked),
+			Raw:          g.Ptr(v.Raw),
+			VariableType: variableType(v),
+			Description:  g.Ptr(v.Description),
+		}, g.WithContext(ctx))
+	case variable.TargetGroup:
+		_, resp, err = gc.client.GroupVariables.UpdateVariable(path, v.Key, &g.UpdateGroupVariableOptions{
+			Value:        g.Ptr(v.Value),
+			Filter:       filter,
+			Protected:    g.Ptr(v.Protected),
+			Masked:       g.Ptr(v.Masked),
+			Raw:          g.Ptr(v.Raw),
+			VariableType: variableType(v),
+			Description:  g.Ptr(v.Description),
+		}, g.WithContext(ctx))
+	default:
+		return fmt.Errorf("ci variable target '%s': %w", target, errs.ErrInvalidValue)
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("ci variable %s: %w", v.Key, errs.ErrNotFound)
+	}
+	return err
+}
+
+// variableType returns the type to send for the variable, nil leaves the type of the variable as GitLab defaults it.
+func variableType(v *variable.Variable) *g.VariableTypeValue {
+	if v.VariableType == "" {
+		return nil
+	}
+	return g.Ptr(g.VariableTypeValue(v.VariableType))
+}
+
+func (gc *gitlabClient) DeleteCIVariable(ctx context.Context, target string, path string, key string, environmentScope string) (err error) {
+	defer func() {
+		gc.logger.Debug("Delete CI/CD variable", "target", target, "path", path, "key", key, "environmentScope", environmentScope, "error", err)
+	}()
+
+	var resp *g.Response
+	var filter = &g.VariableFilter{EnvironmentScope: environmentScope}
+	switch target {
+	case variable.TargetProject:
+		resp, err = gc.client.ProjectVariables.RemoveVariable(path, key, &g.RemoveProjectVariableOptions{Filter: filter}, g.WithContext(ctx))
+	case variable.TargetGroup:
+		resp, err = gc.client.GroupVariables.RemoveVariable(path, key, &g.RemoveGroupVariableOptions{Filter: filter}, g.WithContext(ctx))
+	default:
+		return fmt.Errorf("ci variable target '%s': %w", target, errs.ErrInvalidValue)
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("ci variable %s: %w", key, errs.ErrNotFound)
+	}
+	return err
 }
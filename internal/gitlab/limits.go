@@ -0,0 +1,150 @@
+package gitlab
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+)
+
+// Saturation is how much of the request limits of a client is in use.
+type Saturation struct {
+	// InFlight is the number of requests that are being made to GitLab.
+	InFlight int64
+	// Waiting is the number of requests waiting for the rate limit or a free slot.
+	Waiting int64
+	// MaxConcurrent is the number of requests that can be in flight together, 0 when not limited.
+	MaxConcurrent int
+	// RequestsPerSecond is the rate limit of the requests, 0 when not limited.
+	RequestsPerSecond float64
+	// Tokens is the number of requests that can start now without waiting for the rate limit.
+	Tokens float64
+}
+
+func (s Saturation) LogicalResponseData() map[string]any {
+	var data = map[string]any{
+		"in_flight":               s.InFlight,
+		"waiting":                 s.Waiting,
+		"max_concurrent_requests": s.MaxConcurrent,
+		"requests_per_second":     s.RequestsPerSecond,
+	}
+	if s.RequestsPerSecond > 0 {
+		data["available_tokens"] = s.Tokens
+	}
+	return data
+}
+
+// requestLimits caps the rate and the concurrency of the requests to GitLab. It's created once per client, so all
+// the requests made through the cached client of a config share it, including the ones that are retried.
+type requestLimits struct {
+	limiter  *rate.Limiter
+	slots    chan struct{}
+	inFlight atomic.Int64
+	waiting  atomic.Int64
+}
+
+func newRequestLimits(config *modelConfig.EntryConfig) *requestLimits {
+	var l = new(requestLimits)
+	var requestsPerSecond, burst, maxConcurrent = config.RequestLimits()
+	if requestsPerSecond > 0 {
+		l.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+func (l *requestLimits) saturation() (s Saturation) {
+	s = Saturation{InFlight: l.inFlight.Load(), Waiting: l.waiting.Load(), MaxConcurrent: cap(l.slots)}
+	if l.limiter != nil {
+		s.RequestsPerSecond, s.Tokens = float64(l.limiter.Limit()), l.limiter.Tokens()
+	}
+	return s
+}
+
+// acquire waits for the rate limit and a free slot, the returned func gives the slot back.
+func (l *requestLimits) acquire(ctx context.Context) (release func(), err error) {
+	l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+
+	if l.limiter != nil {
+		if err = l.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	l.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.inFlight.Add(-1)
+			if l.slots != nil {
+				<-l.slots
+			}
+		})
+	}, nil
+}
+
+// wrap returns a copy of the http client whose requests go through the limits, a request holds its slot until the
+// body of the response is closed. A config without limits returns the http client as is.
+func (l *requestLimits) wrap(httpClient *http.Client) *http.Client {
+	if l.limiter == nil && l.slots == nil {
+		return httpClient
+	}
+
+	var c http.Client
+	if httpClient != nil {
+		c = *httpClient
+	}
+	var next = c.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.Transport = &limitedTransport{limits: l, next: next}
+	return &c
+}
+
+type limitedTransport struct {
+	limits *requestLimits
+	next   http.RoundTripper
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limits.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,93 @@
+package gitlab_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+)
+
+func TestNewGitlabClient_RequestLimits(t *testing.T) {
+	t.Run("concurrency capped", func(t *testing.T) {
+		var inFlight, peak atomic.Int32
+		var unblock = make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for p := peak.Load(); current > p && !peak.CompareAndSwap(p, current); p = peak.Load() {
+			}
+			<-unblock
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"version":"17.0.0"}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		client, err := gitlab.NewGitlabClient(&modelConfig.EntryConfig{BaseURL: srv.URL, Token: "glpat-token", MaxConcurrent: 1}, nil, nil)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Go(func() {
+				_, err := client.Metadata(t.Context())
+				assert.NoError(t, err)
+			})
+		}
+
+		require.Eventually(t, func() bool {
+			s := client.Saturation()
+			return s.InFlight == 1 && s.Waiting == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, 1, client.Saturation().MaxConcurrent)
+
+		close(unblock)
+		wg.Wait()
+		assert.EqualValues(t, 1, peak.Load())
+		assert.Equal(t, gitlab.Saturation{MaxConcurrent: 1}, client.Saturation())
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		srv, hits := flakyGitlab(t, 0, nil)
+		client, err := gitlab.NewGitlabClient(&modelConfig.EntryConfig{BaseURL: srv.URL, Token: "glpat-token", RequestsPerSecond: 20, Burst: 1}, nil, nil)
+		require.NoError(t, err)
+
+		var start = time.Now()
+		for range 5 {
+			_, err = client.Metadata(t.Context())
+			require.NoError(t, err)
+		}
+		assert.EqualValues(t, 5, hits.Load())
+		assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+		assert.EqualValues(t, 20, client.Saturation().RequestsPerSecond)
+	})
+
+	t.Run("http client from the context isn't changed", func(t *testing.T) {
+		srv, hits := flakyGitlab(t, 0, nil)
+		var transport = http.DefaultTransport
+		var httpClient = &http.Client{Transport: transport}
+		client, err := gitlab.NewGitlabClient(&modelConfig.EntryConfig{BaseURL: srv.URL, Token: "glpat-token", MaxConcurrent: 1}, httpClient, nil)
+		require.NoError(t, err)
+
+		_, err = client.Metadata(t.Context())
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, hits.Load())
+		assert.Equal(t, transport, httpClient.Transport)
+	})
+
+	t.Run("without limits", func(t *testing.T) {
+		srv, _ := flakyGitlab(t, 0, nil)
+		client, err := gitlab.NewGitlabClient(&modelConfig.EntryConfig{BaseURL: srv.URL, Token: "glpat-token"}, nil, nil)
+		require.NoError(t, err)
+		_, err = client.Metadata(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, gitlab.Saturation{}, client.Saturation())
+		assert.NotContains(t, client.Saturation().LogicalResponseData(), "available_tokens")
+	})
+}
@@ -0,0 +1,48 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// ListableTokenTypes are the token types whose tokens can be listed in GitLab.
+var ListableTokenTypes = []t.Type{
+	t.TypePersonal,
+	t.TypeProject,
+	t.TypeGroup,
+}
+
+// ListTokens returns the tokens of the given type that belong to the user, project or group at the path.
+func ListTokens(ctx context.Context, client Client, tokenType t.Type, path string) (tokens []*token.Token, err error) {
+	switch tokenType {
+	case t.TypePersonal:
+		var userId int64
+		if userId, err = client.GetUserIdByUsername(ctx, path); err != nil {
+			return nil, err
+		}
+		var ets []*token.TokenPersonal
+		ets, err = client.ListPersonalAccessTokens(ctx, path, userId)
+		for _, et := range ets {
+			tokens = append(tokens, &et.Token)
+		}
+	case t.TypeProject:
+		var ets []*token.TokenProject
+		ets, err = client.ListProjectAccessTokens(ctx, path)
+		for _, et := range ets {
+			tokens = append(tokens, &et.Token)
+		}
+	case t.TypeGroup:
+		var ets []*token.TokenGroup
+		ets, err = client.ListGroupAccessTokens(ctx, path)
+		for _, et := range ets {
+			tokens = append(tokens, &et.Token)
+		}
+	default:
+		return nil, fmt.Errorf("%s: %w", tokenType.String(), errs.ErrUnknownTokenType)
+	}
+	return tokens, err
+}
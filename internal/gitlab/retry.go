@@ -0,0 +1,105 @@
+package gitlab
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-retryablehttp"
+	g "gitlab.com/gitlab-org/api/client-go/v2"
+
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+)
+
+// retryPolicy retries the requests to GitLab that are safe to repeat, the lookups and the revokes, with an
+// exponential backoff that gives way to the wait GitLab asks for.
+type retryPolicy struct {
+	logger   hclog.Logger
+	attempts int
+	waitMin  time.Duration
+	waitMax  time.Duration
+}
+
+func newRetryPolicy(config *modelConfig.EntryConfig, logger hclog.Logger) *retryPolicy {
+	var attempts, waitMin, waitMax = config.RetryPolicy()
+	return &retryPolicy{logger: logger, attempts: attempts, waitMin: waitMin, waitMax: waitMax}
+}
+
+// options returns the client options that install the policy.
+func (r *retryPolicy) options() []g.ClientOptionFunc {
+	return []g.ClientOptionFunc{
+		g.WithCustomRetryMax(r.attempts - 1),
+		g.WithCustomRetryWaitMinMax(r.waitMin, r.waitMax),
+		g.WithCustomBackoff(r.backoff),
+		// a request without its own check is never retried
+		g.WithCustomRetry(func(ctx context.Context, _ *http.Response, _ error) (bool, error) { return false, ctx.Err() }),
+		// the method isn't known to the client wide check when the request fails without a response, so every
+		// request gets a check of its own
+		g.WithRequestOptions(func(req *retryablehttp.Request) error {
+			return g.WithRequestRetry(r.checkRetry(req.Method))(req)
+		}),
+		g.WithRequestLogHook(func(_ retryablehttp.Logger, req *http.Request, retry int) {
+			if retry > 0 {
+				r.logger.Warn("Retrying request to GitLab", "method", req.Method, "path", req.URL.Path, "attempt", retry+1, "maxAttempts", r.attempts)
+			}
+		}),
+	}
+}
+
+// retryable reports if a request with the method can be repeated without side effects. The plugin only reads with
+// GET and HEAD, and only revokes with DELETE, a revoke that already happened is not repeated by GitLab.
+func retryable(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete
+}
+
+func (r *retryPolicy) checkRetry(method string) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if !retryable(method) {
+			return false, ctx.Err()
+		}
+		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	}
+}
+
+// backoff doubles the wait with every attempt, with a random jitter so the clients that failed together don't
+// retry together. The wait GitLab asks for in the response is used instead when there is one, up to waitMax, so a
+// far away reset doesn't hold the request for longer than the policy allows.
+func (r *retryPolicy) backoff(waitMin, waitMax time.Duration, attempt int, resp *http.Response) time.Duration {
+	if wait, ok := serverWait(resp, time.Now()); ok {
+		return min(wait, waitMax)
+	}
+
+	var wait = waitMax
+	if attempt < 32 && waitMin<<attempt > 0 && waitMin<<attempt < waitMax {
+		wait = waitMin << attempt
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// serverWait returns how long GitLab asked to wait before the next request, from the Retry-After header or when the
+// rate limit is exhausted from the RateLimit-Reset header.
+func serverWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	if val := resp.Header.Get("Retry-After"); val != "" {
+		if seconds, err := strconv.Atoi(val); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(val); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.Header.Get("RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,169 @@
+package gitlab_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// flakyGitlab fails the first requests with the given response, and answers the rest.
+func flakyGitlab(tt *testing.T, failures int32, fail func(w http.ResponseWriter)) (srv *httptest.Server, hits *atomic.Int32) {
+	tt.Helper()
+	hits = new(atomic.Int32)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= failures {
+			fail(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			_, _ = w.Write([]byte(`{"version":"17.0.0"}`))
+		}
+	}))
+	tt.Cleanup(srv.Close)
+	return srv, hits
+}
+
+func status(code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) { w.WriteHeader(code) }
+}
+
+func retryClient(tt *testing.T, srv *httptest.Server, attempts int, logger hclog.Logger) gitlab.Client {
+	tt.Helper()
+	return retryClientWaitMax(tt, srv, attempts, 5*time.Millisecond, logger)
+}
+
+func retryClientWaitMax(tt *testing.T, srv *httptest.Server, attempts int, waitMax time.Duration, logger hclog.Logger) gitlab.Client {
+	tt.Helper()
+	client, err := gitlab.NewGitlabClient(&modelConfig.EntryConfig{
+		BaseURL:          srv.URL,
+		Token:            "glpat-token",
+		RetryMaxAttempts: attempts,
+		RetryWaitMin:     time.Millisecond,
+		RetryWaitMax:     waitMax,
+	}, nil, logger)
+	require.NoError(tt, err)
+	return client
+}
+
+func TestNewGitlabClient_Retry(tt *testing.T) {
+	tt.Run("lookup retried until it succeeds", func(tt *testing.T) {
+		var buf bytes.Buffer
+		srv, hits := flakyGitlab(tt, 2, status(http.StatusBadGateway))
+		client := retryClient(tt, srv, 3, hclog.New(&hclog.LoggerOptions{Output: &buf, Level: hclog.Warn}))
+		metadata, err := client.Metadata(tt.Context())
+		require.NoError(tt, err)
+		assert.Equal(tt, "17.0.0", metadata.Version)
+		assert.EqualValues(tt, 3, hits.Load())
+		assert.Contains(tt, buf.String(), "attempt=2")
+		assert.Contains(tt, buf.String(), "attempt=3")
+	})
+
+	tt.Run("lookup gives up after the max attempts", func(tt *testing.T) {
+		srv, hits := flakyGitlab(tt, 10, status(http.StatusServiceUnavailable))
+		client := retryClient(tt, srv, 3, nil)
+		_, err := client.Metadata(tt.Context())
+		require.Error(tt, err)
+		assert.EqualValues(tt, 3, hits.Load())
+	})
+
+	tt.Run("single attempt", func(tt *testing.T) {
+		srv, hits := flakyGitlab(tt, 1, status(http.StatusBadGateway))
+		client := retryClient(tt, srv, 1, nil)
+		_, err := client.Metadata(tt.Context())
+		require.Error(tt, err)
+		assert.EqualValues(tt, 1, hits.Load())
+	})
+
+	tt.Run("revoke retried", func(tt *testing.T) {
+		srv, hits := flakyGitlab(tt, 1, status(http.StatusTooManyRequests))
+		client := retryClient(tt, srv, 3, nil)
+		require.NoError(tt, client.RevokePersonalAccessToken(tt.Context(), 1))
+		assert.EqualValues(tt, 2, hits.Load())
+	})
+
+	tt.Run("create not retried", func(tt *testing.T) {
+		srv, hits := flakyGitlab(tt, 1, status(http.StatusBadGateway))
+		client := retryClient(tt, srv, 3, nil)
+		_, err := client.CreateProjectAccessToken(tt.Context(), "1", "name", time.Now().Add(time.Hour), []string{"read_api"}, t.AccessLevelGuestPermissions)
+		require.Error(tt, err)
+		assert.EqualValues(tt, 1, hits.Load())
+	})
+
+	tt.Run("client errors not retried", func(tt *testing.T) {
+		srv, hits := flakyGitlab(tt, 1, status(http.StatusForbidden))
+		client := retryClient(tt, srv, 3, nil)
+		_, err := client.Metadata(tt.Context())
+		require.Error(tt, err)
+		assert.EqualValues(tt, 1, hits.Load())
+	})
+
+	tt.Run("waits as long as Retry-After asks", func(tt *testing.T) {
+		srv, hits := flakyGitlab(tt, 1, func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		client := retryClientWaitMax(tt, srv, 2, 5*time.Second, nil)
+		var start = time.Now()
+		_, err := client.Metadata(tt.Context())
+		require.NoError(tt, err)
+		assert.EqualValues(tt, 2, hits.Load())
+		assert.GreaterOrEqual(tt, time.Since(start), time.Second)
+	})
+
+	tt.Run("waits until RateLimit-Reset", func(tt *testing.T) {
+		var reset = time.Now().Add(2 * time.Second).Truncate(time.Second)
+		srv, hits := flakyGitlab(tt, 1, func(w http.ResponseWriter) {
+			w.Header().Set("RateLimit-Remaining", "0")
+			w.Header().Set("RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		client := retryClientWaitMax(tt, srv, 2, 5*time.Second, nil)
+		_, err := client.Metadata(tt.Context())
+		require.NoError(tt, err)
+		assert.EqualValues(tt, 2, hits.Load())
+		assert.False(tt, time.Now().Before(reset))
+	})
+
+	tt.Run("waits no longer than retry_wait_max", func(tt *testing.T) {
+		srv, hits := flakyGitlab(tt, 1, func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		client := retryClient(tt, srv, 2, nil)
+		var start = time.Now()
+		_, err := client.Metadata(tt.Context())
+		require.NoError(tt, err)
+		assert.EqualValues(tt, 2, hits.Load())
+		assert.Less(tt, time.Since(start), time.Second)
+	})
+
+	tt.Run("stops when the context is done", func(tt *testing.T) {
+		srv, hits := flakyGitlab(tt, 1, func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		client := retryClientWaitMax(tt, srv, 2, time.Minute, nil)
+		ctx, cancel := context.WithTimeout(tt.Context(), 100*time.Millisecond)
+		defer cancel()
+		_, err := client.Metadata(ctx)
+		require.Error(tt, err)
+		assert.EqualValues(tt, 1, hits.Load())
+	})
+}
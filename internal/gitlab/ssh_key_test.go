@@ -0,0 +1,43 @@
+package gitlab_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+)
+
+// goneGitlab answers every request with the status, like GitLab does for a key that was already deleted.
+func goneGitlab(t *testing.T, status int) gitlab.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message":"404 Not found"}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := gitlab.NewGitlabClient(&modelConfig.EntryConfig{BaseURL: srv.URL, Token: "glpat-token"}, nil, nil)
+	require.NoError(t, err)
+	return client
+}
+
+func TestRevokeProjectDeployKey_NotFound(t *testing.T) {
+	require.ErrorIs(t, goneGitlab(t, http.StatusNotFound).RevokeProjectDeployKey(t.Context(), 1, 2), errs.ErrAccessTokenNotFound)
+
+	err := goneGitlab(t, http.StatusForbidden).RevokeProjectDeployKey(t.Context(), 1, 2)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errs.ErrAccessTokenNotFound)
+}
+
+func TestRevokeUserSSHKey_NotFound(t *testing.T) {
+	require.ErrorIs(t, goneGitlab(t, http.StatusNotFound).RevokeUserSSHKey(t.Context(), 1, 2), errs.ErrAccessTokenNotFound)
+
+	err := goneGitlab(t, http.StatusForbidden).RevokeUserSSHKey(t.Context(), 1, 2)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errs.ErrAccessTokenNotFound)
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// ProxySchemes are the schemes of the proxies the requests to GitLab can be routed through.
+var ProxySchemes = []string{"http", "https", "socks5"}
+
+// reservedHeaders are set by the client for every request, a config can't override them.
+var reservedHeaders = []string{"Authorization", "Private-Token", "Job-Token", "Content-Type", "User-Agent"}
+
+// TLSConfig returns the TLS settings of the connection to GitLab, the CA bundle replaces the system CAs and the
+// client certificate is presented to GitLab. A config without either returns nil.
+func (e *EntryConfig) TLSConfig() (cfg *tls.Config, err error) {
+	if e.CACert == "" && e.ClientCert == "" && e.ClientKey == "" {
+		return nil, nil
+	}
+
+	cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	if e.CACert != "" {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(e.CACert)) {
+			return nil, fmt.Errorf("ca_cert has no PEM encoded certificates: %w", errs.ErrInvalidValue)
+		}
+	}
+
+	switch {
+	case e.ClientCert != "" && e.ClientKey != "":
+		var cert tls.Certificate
+		if cert, err = tls.X509KeyPair([]byte(e.ClientCert), []byte(e.ClientKey)); err != nil {
+			return nil, fmt.Errorf("client_cert and client_key: %s: %w", err, errs.ErrInvalidValue)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case e.ClientCert != "" || e.ClientKey != "":
+		return nil, fmt.Errorf("client_cert and client_key have to be set together: %w", errs.ErrInvalidValue)
+	}
+
+	return cfg, nil
+}
+
+// Proxy returns the URL of the proxy the requests to GitLab are routed through, a config without a proxy returns
+// nil and the proxy is taken from the environment.
+func (e *EntryConfig) Proxy() (u *url.URL, err error) {
+	if e.ProxyURL == "" {
+		return nil, nil
+	}
+	if u, err = url.Parse(e.ProxyURL); err != nil || u.Host == "" || !slices.Contains(ProxySchemes, u.Scheme) {
+		return nil, fmt.Errorf("proxy_url should be an url with one of the schemes %v: %w", ProxySchemes, errs.ErrInvalidValue)
+	}
+	return u, nil
+}
+
+// updateConnection updates the connection settings from the data, a patch only updates the fields that are in the
+// data. The settings are only changed if all of them are valid.
+func (e *EntryConfig) updateConnection(data *framework.FieldData, patch bool) (changes map[string]string, err *multierror.Error) {
+	var get = func(name string) (any, bool) {
+		if patch {
+			return data.GetOk(name)
+		}
+		return data.Get(name), true
+	}
+
+	var c = *e
+	changes = make(map[string]string)
+	if val, ok := get("ca_cert"); ok {
+		c.CACert = strings.TrimSpace(val.(string))
+		changes["ca_cert"] = fmt.Sprintf("%x", sha1.Sum([]byte(c.CACert)))
+	}
+	if val, ok := get("client_cert"); ok {
+		c.ClientCert = strings.TrimSpace(val.(string))
+		changes["client_cert"] = fmt.Sprintf("%x", sha1.Sum([]byte(c.ClientCert)))
+	}
+	if val, ok := get("client_key"); ok {
+		c.ClientKey = strings.TrimSpace(val.(string))
+		changes["client_key"] = fmt.Sprintf("%x", sha1.Sum([]byte(c.ClientKey)))
+	}
+	if val, ok := get("proxy_url"); ok {
+		c.ProxyURL = strings.TrimSpace(val.(string))
+		changes["proxy_url"] = redactURL(c.ProxyURL)
+	}
+	if val, ok := get("request_timeout"); ok {
+		timeout, _ := utils.ConvertToInt(val)
+		c.RequestTimeout = time.Duration(timeout) * time.Second
+		changes["request_timeout"] = c.RequestTimeout.String()
+	}
+	if val, ok := get("headers"); ok {
+		c.Headers = nil
+		if headers := val.(map[string]string); len(headers) > 0 {
+			c.Headers = maps.Clone(headers)
+		}
+		changes["headers"] = strings.Join(slices.Sorted(maps.Keys(c.Headers)), ", ")
+	}
+
+	if _, er := c.TLSConfig(); er != nil {
+		err = multierror.Append(err, er)
+	}
+	if _, er := c.Proxy(); er != nil {
+		err = multierror.Append(err, er)
+	}
+	if c.RequestTimeout > DefaultRequestTimeoutMax {
+		err = multierror.Append(err, fmt.Errorf("request_timeout can not be bigger than %s: %w", DefaultRequestTimeoutMax, errs.ErrInvalidValue))
+	}
+	for name, value := range c.Headers {
+		if !validHeaderName(name) || slices.Contains(reservedHeaders, http.CanonicalHeaderKey(name)) {
+			err = multierror.Append(err, fmt.Errorf("headers: %q can not be set: %w", name, errs.ErrInvalidValue))
+		} else if strings.ContainsAny(value, "\r\n") {
+			err = multierror.Append(err, fmt.Errorf("headers: the value of %q can not span lines: %w", name, errs.ErrInvalidValue))
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.CACert, e.ClientCert, e.ClientKey = c.CACert, c.ClientCert, c.ClientKey
+	e.ProxyURL, e.RequestTimeout, e.Headers = c.ProxyURL, c.RequestTimeout, c.Headers
+	return changes, nil
+}
+
+// validHeaderName reports if the name is a token as defined by RFC 7230.
+func validHeaderName(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return r > '~' || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r)
+	}) < 0
+}
+
+// redactURL hides the password of the url, so it can be shown.
+func redactURL(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return u.Redacted()
+	}
+	return raw
+}
@@ -5,4 +5,12 @@ import "time"
 const (
 	DefaultAutoRotateBeforeMinTTL = 24 * time.Hour
 	DefaultAutoRotateBeforeMaxTTL = 730 * time.Hour
+	DefaultSweepIntervalMin       = time.Hour
+	DefaultRequestTimeoutMax      = 10 * time.Minute
+	DefaultRetryMaxAttempts       = 4
+	DefaultRetryMaxAttemptsMax    = 10
+	DefaultRetryWaitMin           = time.Second
+	DefaultRetryWaitMax           = 30 * time.Second
+	DefaultRetryWaitMaxMax        = 5 * time.Minute
+	DefaultMaxConcurrentRequests  = 1000
 )
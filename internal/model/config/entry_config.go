@@ -3,6 +3,7 @@ package config
 import (
 	"crypto/sha1"
 	"fmt"
+	"maps"
 	"strconv"
 	"strings"
 	"time"
@@ -19,19 +20,33 @@ import (
 var _ model.Named = (*EntryConfig)(nil)
 
 type EntryConfig struct {
-	TokenId            int64            `json:"token_id" yaml:"token_id" mapstructure:"token_id"`
-	BaseURL            string           `json:"base_url" structs:"base_url" mapstructure:"base_url"`
-	Token              string           `json:"token" structs:"token" mapstructure:"token"`
-	AutoRotateToken    bool             `json:"auto_rotate_token" structs:"auto_rotate_token" mapstructure:"auto_rotate_token"`
-	AutoRotateBefore   time.Duration    `json:"auto_rotate_before" structs:"auto_rotate_before" mapstructure:"auto_rotate_before"`
-	TokenCreatedAt     time.Time        `json:"token_created_at" structs:"token_created_at" mapstructure:"token_created_at"`
-	TokenExpiresAt     time.Time        `json:"token_expires_at" structs:"token_expires_at" mapstructure:"token_expires_at"`
-	Scopes             []string         `json:"scopes" structs:"scopes" mapstructure:"scopes"`
-	Type               gitlabTypes.Type `json:"type" structs:"type" mapstructure:"type"`
-	Name               string           `json:"name" structs:"name" mapstructure:"name"`
-	GitlabVersion      string           `json:"gitlab_version" structs:"gitlab_version" mapstructure:"gitlab_version"`
-	GitlabRevision     string           `json:"gitlab_revision" structs:"gitlab_revision" mapstructure:"gitlab_revision"`
-	GitlabIsEnterprise bool             `json:"gitlab_is_enterprise" structs:"gitlab_is_enterprise" mapstructure:"gitlab_is_enterprise"`
+	TokenId            int64             `json:"token_id" yaml:"token_id" mapstructure:"token_id"`
+	BaseURL            string            `json:"base_url" structs:"base_url" mapstructure:"base_url"`
+	Token              string            `json:"token" structs:"token" mapstructure:"token"`
+	AutoRotateToken    bool              `json:"auto_rotate_token" structs:"auto_rotate_token" mapstructure:"auto_rotate_token"`
+	AutoRotateBefore   time.Duration     `json:"auto_rotate_before" structs:"auto_rotate_before" mapstructure:"auto_rotate_before"`
+	TokenCreatedAt     time.Time         `json:"token_created_at" structs:"token_created_at" mapstructure:"token_created_at"`
+	TokenExpiresAt     time.Time         `json:"token_expires_at" structs:"token_expires_at" mapstructure:"token_expires_at"`
+	Scopes             []string          `json:"scopes" structs:"scopes" mapstructure:"scopes"`
+	Type               gitlabTypes.Type  `json:"type" structs:"type" mapstructure:"type"`
+	Name               string            `json:"name" structs:"name" mapstructure:"name"`
+	GitlabVersion      string            `json:"gitlab_version" structs:"gitlab_version" mapstructure:"gitlab_version"`
+	GitlabRevision     string            `json:"gitlab_revision" structs:"gitlab_revision" mapstructure:"gitlab_revision"`
+	GitlabIsEnterprise bool              `json:"gitlab_is_enterprise" structs:"gitlab_is_enterprise" mapstructure:"gitlab_is_enterprise"`
+	SweepInterval      time.Duration     `json:"sweep_interval" structs:"sweep_interval" mapstructure:"sweep_interval"`
+	SweepRevoke        bool              `json:"sweep_revoke" structs:"sweep_revoke" mapstructure:"sweep_revoke"`
+	CACert             string            `json:"ca_cert" structs:"ca_cert" mapstructure:"ca_cert"`
+	ClientCert         string            `json:"client_cert" structs:"client_cert" mapstructure:"client_cert"`
+	ClientKey          string            `json:"client_key" structs:"client_key" mapstructure:"client_key"`
+	ProxyURL           string            `json:"proxy_url" structs:"proxy_url" mapstructure:"proxy_url"`
+	RequestTimeout     time.Duration     `json:"request_timeout" structs:"request_timeout" mapstructure:"request_timeout"`
+	Headers            map[string]string `json:"headers" structs:"headers" mapstructure:"headers"`
+	RetryMaxAttempts   int               `json:"retry_max_attempts" structs:"retry_max_attempts" mapstructure:"retry_max_attempts"`
+	RetryWaitMin       time.Duration     `json:"retry_wait_min" structs:"retry_wait_min" mapstructure:"retry_wait_min"`
+	RetryWaitMax       time.Duration     `json:"retry_wait_max" structs:"retry_wait_max" mapstructure:"retry_wait_max"`
+	RequestsPerSecond  float64           `json:"requests_per_second" structs:"requests_per_second" mapstructure:"requests_per_second"`
+	Burst              int               `json:"burst" structs:"burst" mapstructure:"burst"`
+	MaxConcurrent      int               `json:"max_concurrent_requests" structs:"max_concurrent_requests" mapstructure:"max_concurrent_requests"`
 }
 
 func (e *EntryConfig) GetName() string { return e.Name }
@@ -78,11 +93,42 @@ func (e *EntryConfig) Merge(data *framework.FieldData) (warnings []string, chang
 		changes["base_url"] = e.BaseURL
 	}
 
+	if _, ok := data.GetOk("sweep_interval"); ok {
+		if er = e.updateSweepInterval(data); er != nil {
+			err = multierror.Append(err, er)
+		} else {
+			changes["sweep_interval"] = e.SweepInterval.String()
+		}
+	}
+
+	if val, ok := data.GetOk("sweep_revoke"); ok {
+		e.SweepRevoke = val.(bool)
+		changes["sweep_revoke"] = strconv.FormatBool(e.SweepRevoke)
+	}
+
 	if val, ok := data.GetOk("token"); ok && len(val.(string)) > 0 {
 		e.Token = val.(string)
 		changes["token"] = strings.Repeat("*", len(e.Token))
 	}
 
+	if c, er := e.updateConnection(data, true); er != nil {
+		err = multierror.Append(err, er.Errors...)
+	} else {
+		maps.Copy(changes, c)
+	}
+
+	if c, er := e.updateRetry(data, true); er != nil {
+		err = multierror.Append(err, er.Errors...)
+	} else {
+		maps.Copy(changes, c)
+	}
+
+	if c, er := e.updateLimits(data, true); er != nil {
+		err = multierror.Append(err, er.Errors...)
+	} else {
+		maps.Copy(changes, c)
+	}
+
 	return warnings, changes, err
 }
 
@@ -103,6 +149,16 @@ func (e *EntryConfig) updateAutoRotateBefore(data *framework.FieldData) (warning
 	return warnings, err
 }
 
+func (e *EntryConfig) updateSweepInterval(data *framework.FieldData) (err error) {
+	val, _ := utils.ConvertToInt(data.Get("sweep_interval"))
+	var interval = time.Duration(val) * time.Second
+	if interval != 0 && interval < DefaultSweepIntervalMin {
+		return fmt.Errorf("sweep_interval can be 0 to disable it or at least %s: %w", DefaultSweepIntervalMin, errs.ErrInvalidValue)
+	}
+	e.SweepInterval = interval
+	return nil
+}
+
 func (e *EntryConfig) UpdateFromFieldData(data *framework.FieldData) (warnings []string, err error) {
 	if data == nil {
 		return warnings, multierror.Append(fmt.Errorf("data: %w", errs.ErrNilValue))
@@ -114,6 +170,11 @@ func (e *EntryConfig) UpdateFromFieldData(data *framework.FieldData) (warnings [
 
 	var er error
 	e.AutoRotateToken = data.Get("auto_rotate_token").(bool)
+	e.SweepRevoke = data.Get("sweep_revoke").(bool)
+
+	if er = e.updateSweepInterval(data); er != nil {
+		err = multierror.Append(err, er)
+	}
 
 	if token, ok := data.GetOk("token"); ok && len(token.(string)) > 0 {
 		e.Token = token.(string)
@@ -143,6 +204,18 @@ func (e *EntryConfig) UpdateFromFieldData(data *framework.FieldData) (warnings [
 		warnings = append(warnings, w...)
 	}
 
+	if _, er := e.updateConnection(data, false); er != nil {
+		err = multierror.Append(err, er.Errors...)
+	}
+
+	if _, er := e.updateRetry(data, false); er != nil {
+		err = multierror.Append(err, er.Errors...)
+	}
+
+	if _, er := e.updateLimits(data, false); er != nil {
+		err = multierror.Append(err, er.Errors...)
+	}
+
 	return warnings, err
 }
 
@@ -169,10 +242,36 @@ func (e *EntryConfig) LogicalResponseData(includeToken bool) (data map[string]an
 		"scopes":               strings.Join(e.Scopes, ", "),
 		"type":                 e.Type.String(),
 		"name":                 e.Name,
+		"sweep_interval":       e.SweepInterval.String(),
+		"sweep_revoke":         e.SweepRevoke,
+		"ca_cert":              e.CACert,
+		"client_cert":          e.ClientCert,
+		"proxy_url":            redactURL(e.ProxyURL),
+		"request_timeout":      e.RequestTimeout.String(),
+	}
+
+	var attempts, waitMin, waitMax = e.RetryPolicy()
+	data["retry_max_attempts"] = attempts
+	data["retry_wait_min"] = waitMin.String()
+	data["retry_wait_max"] = waitMax.String()
+
+	var requestsPerSecond, burst, maxConcurrent = e.RequestLimits()
+	data["requests_per_second"] = requestsPerSecond
+	data["burst"] = burst
+	data["max_concurrent_requests"] = maxConcurrent
+
+	// the values of the headers can hold credentials for a proxy in front of GitLab
+	var headers = make(map[string]string, len(e.Headers))
+	for name, value := range e.Headers {
+		headers[name] = strings.Repeat("*", len(value))
 	}
+	data["headers"] = headers
 
 	if includeToken {
 		data["token"] = e.Token
+		data["client_key"] = e.ClientKey
+		data["proxy_url"] = e.ProxyURL
+		data["headers"] = maps.Clone(e.Headers)
 	}
 
 	return data
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -78,6 +79,137 @@ func TestEntryConfigUpdateFromFieldData(t *testing.T) {
 				"base_url": "https://gitlab.com",
 			},
 		},
+		{
+			name: "connection settings",
+			expectedConfig: &config.EntryConfig{
+				Token:            "token",
+				Type:             gitlabTypes.TypeSelfManaged,
+				AutoRotateBefore: config.DefaultAutoRotateBeforeMinTTL,
+				BaseURL:          "https://gitlab.com",
+				ProxyURL:         "socks5://proxy:1080",
+				RequestTimeout:   30 * time.Second,
+				Headers:          map[string]string{"X-Proxy-Auth": "secret"},
+			},
+			warnings: []string{"auto_rotate_before not specified setting to 24h0m0s"},
+			raw: map[string]interface{}{
+				"token":           "token",
+				"type":            gitlabTypes.TypeSelfManaged.String(),
+				"base_url":        "https://gitlab.com",
+				"proxy_url":       "socks5://proxy:1080",
+				"request_timeout": "30s",
+				"headers":         map[string]interface{}{"X-Proxy-Auth": "secret"},
+			},
+		},
+		{
+			name: "invalid connection settings",
+			expectedConfig: &config.EntryConfig{
+				Token:            "token",
+				Type:             gitlabTypes.TypeSelfManaged,
+				AutoRotateBefore: config.DefaultAutoRotateBeforeMinTTL,
+				BaseURL:          "https://gitlab.com",
+			},
+			warnings: []string{"auto_rotate_before not specified setting to 24h0m0s"},
+			raw: map[string]interface{}{
+				"token":           "token",
+				"type":            gitlabTypes.TypeSelfManaged.String(),
+				"base_url":        "https://gitlab.com",
+				"ca_cert":         "not a certificate",
+				"client_key":      "not a key",
+				"proxy_url":       "ftp://proxy",
+				"request_timeout": "1h",
+				"headers":         map[string]interface{}{"Private-Token": "other", "X-Multi": "a\r\nb", "Bad Name": "x"},
+			},
+			err: true,
+			errMap: map[string]int{
+				errs.ErrInvalidValue.Error(): 6,
+			},
+		},
+		{
+			name: "retry settings",
+			expectedConfig: &config.EntryConfig{
+				Token:            "token",
+				Type:             gitlabTypes.TypeSelfManaged,
+				AutoRotateBefore: config.DefaultAutoRotateBeforeMinTTL,
+				BaseURL:          "https://gitlab.com",
+				RetryMaxAttempts: 6,
+				RetryWaitMin:     2 * time.Second,
+				RetryWaitMax:     time.Minute,
+			},
+			warnings: []string{"auto_rotate_before not specified setting to 24h0m0s"},
+			raw: map[string]interface{}{
+				"token":              "token",
+				"type":               gitlabTypes.TypeSelfManaged.String(),
+				"base_url":           "https://gitlab.com",
+				"retry_max_attempts": 6,
+				"retry_wait_min":     "2s",
+				"retry_wait_max":     "1m",
+			},
+		},
+		{
+			name: "invalid retry settings",
+			expectedConfig: &config.EntryConfig{
+				Token:            "token",
+				Type:             gitlabTypes.TypeSelfManaged,
+				AutoRotateBefore: config.DefaultAutoRotateBeforeMinTTL,
+				BaseURL:          "https://gitlab.com",
+			},
+			warnings: []string{"auto_rotate_before not specified setting to 24h0m0s"},
+			raw: map[string]interface{}{
+				"token":              "token",
+				"type":               gitlabTypes.TypeSelfManaged.String(),
+				"base_url":           "https://gitlab.com",
+				"retry_max_attempts": 11,
+				"retry_wait_min":     "1m",
+				"retry_wait_max":     "10s",
+			},
+			err: true,
+			errMap: map[string]int{
+				errs.ErrInvalidValue.Error(): 2,
+			},
+		},
+		{
+			name: "request limits",
+			expectedConfig: &config.EntryConfig{
+				Token:             "token",
+				Type:              gitlabTypes.TypeSelfManaged,
+				AutoRotateBefore:  config.DefaultAutoRotateBeforeMinTTL,
+				BaseURL:           "https://gitlab.com",
+				RequestsPerSecond: 2.5,
+				Burst:             5,
+				MaxConcurrent:     4,
+			},
+			warnings: []string{"auto_rotate_before not specified setting to 24h0m0s"},
+			raw: map[string]interface{}{
+				"token":                   "token",
+				"type":                    gitlabTypes.TypeSelfManaged.String(),
+				"base_url":                "https://gitlab.com",
+				"requests_per_second":     2.5,
+				"burst":                   5,
+				"max_concurrent_requests": 4,
+			},
+		},
+		{
+			name: "invalid request limits",
+			expectedConfig: &config.EntryConfig{
+				Token:            "token",
+				Type:             gitlabTypes.TypeSelfManaged,
+				AutoRotateBefore: config.DefaultAutoRotateBeforeMinTTL,
+				BaseURL:          "https://gitlab.com",
+			},
+			warnings: []string{"auto_rotate_before not specified setting to 24h0m0s"},
+			raw: map[string]interface{}{
+				"token":                   "token",
+				"type":                    gitlabTypes.TypeSelfManaged.String(),
+				"base_url":                "https://gitlab.com",
+				"requests_per_second":     -1,
+				"burst":                   -1,
+				"max_concurrent_requests": 1001,
+			},
+			err: true,
+			errMap: map[string]int{
+				errs.ErrInvalidValue.Error(): 3,
+			},
+		},
 		{
 			name: "auto_rotate_before specified (valid) should not warn and should set duration",
 			expectedConfig: &config.EntryConfig{
@@ -116,6 +248,28 @@ func TestEntryConfigUpdateFromFieldData(t *testing.T) {
 				errs.ErrInvalidValue.Error(): 1,
 			},
 		},
+		{
+			name: "sweep_interval specified (too small) should error",
+			expectedConfig: &config.EntryConfig{
+				Token:            "token",
+				Type:             gitlabTypes.TypeSelfManaged,
+				AutoRotateBefore: config.DefaultAutoRotateBeforeMinTTL,
+				BaseURL:          "https://gitlab.com",
+				SweepRevoke:      true,
+			},
+			warnings: []string{"auto_rotate_before not specified setting to 24h0m0s"},
+			raw: map[string]interface{}{
+				"token":          "token",
+				"type":           gitlabTypes.TypeSelfManaged.String(),
+				"base_url":       "https://gitlab.com",
+				"sweep_interval": 60,
+				"sweep_revoke":   true,
+			},
+			err: true,
+			errMap: map[string]int{
+				errs.ErrInvalidValue.Error(): 1,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// RequestLimits returns how many requests per second can be made to GitLab, how many of them can be made at once
+// above that rate, and how many can be in flight together. The settings that are 0 are not limited, a rate without a
+// burst allows the requests of one second at once.
+func (e *EntryConfig) RequestLimits() (requestsPerSecond float64, burst int, maxConcurrent int) {
+	requestsPerSecond, burst, maxConcurrent = e.RequestsPerSecond, e.Burst, e.MaxConcurrent
+	if requestsPerSecond > 0 && burst <= 0 {
+		burst = max(int(math.Ceil(requestsPerSecond)), 1)
+	}
+	return requestsPerSecond, burst, maxConcurrent
+}
+
+// updateLimits updates the request limits from the data, a patch only updates the fields that are in the data. The
+// limits are only changed if all of them are valid.
+func (e *EntryConfig) updateLimits(data *framework.FieldData, patch bool) (changes map[string]string, err *multierror.Error) {
+	var get = func(name string) (any, bool) {
+		if patch {
+			return data.GetOk(name)
+		}
+		return data.Get(name), true
+	}
+
+	var c = *e
+	changes = make(map[string]string)
+	if val, ok := get("requests_per_second"); ok {
+		c.RequestsPerSecond = val.(float64)
+		changes["requests_per_second"] = strconv.FormatFloat(c.RequestsPerSecond, 'f', -1, 64)
+	}
+	if val, ok := get("burst"); ok {
+		c.Burst, _ = utils.ConvertToInt(val)
+		changes["burst"] = strconv.Itoa(c.Burst)
+	}
+	if val, ok := get("max_concurrent_requests"); ok {
+		c.MaxConcurrent, _ = utils.ConvertToInt(val)
+		changes["max_concurrent_requests"] = strconv.Itoa(c.MaxConcurrent)
+	}
+
+	if c.RequestsPerSecond < 0 || math.IsNaN(c.RequestsPerSecond) || math.IsInf(c.RequestsPerSecond, 0) {
+		err = multierror.Append(err, fmt.Errorf("requests_per_second can be 0 to disable the rate limit or a positive number: %w", errs.ErrInvalidValue))
+	}
+	if c.Burst < 0 {
+		err = multierror.Append(err, fmt.Errorf("burst can not be negative: %w", errs.ErrInvalidValue))
+	} else if c.Burst > 0 && c.RequestsPerSecond == 0 {
+		err = multierror.Append(err, fmt.Errorf("burst can only be set together with requests_per_second: %w", errs.ErrInvalidValue))
+	}
+	if c.MaxConcurrent < 0 || c.MaxConcurrent > DefaultMaxConcurrentRequests {
+		err = multierror.Append(err, fmt.Errorf("max_concurrent_requests should be between 0 and %d: %w", DefaultMaxConcurrentRequests, errs.ErrInvalidValue))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.RequestsPerSecond, e.Burst, e.MaxConcurrent = c.RequestsPerSecond, c.Burst, c.MaxConcurrent
+	return changes, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// RetryPolicy returns how many times a request to GitLab is attempted and the bounds of the backoff between the
+// attempts. The settings that are 0 use the defaults.
+func (e *EntryConfig) RetryPolicy() (attempts int, waitMin, waitMax time.Duration) {
+	attempts, waitMin, waitMax = e.RetryMaxAttempts, e.RetryWaitMin, e.RetryWaitMax
+	if attempts <= 0 {
+		attempts = DefaultRetryMaxAttempts
+	}
+	if waitMin <= 0 {
+		waitMin = DefaultRetryWaitMin
+	}
+	if waitMax <= 0 {
+		waitMax = max(DefaultRetryWaitMax, waitMin)
+	}
+	return attempts, waitMin, waitMax
+}
+
+// updateRetry updates the retry settings from the data, a patch only updates the fields that are in the data. The
+// settings are only changed if all of them are valid.
+func (e *EntryConfig) updateRetry(data *framework.FieldData, patch bool) (changes map[string]string, err *multierror.Error) {
+	var get = func(name string) (any, bool) {
+		if patch {
+			return data.GetOk(name)
+		}
+		return data.Get(name), true
+	}
+
+	var c = *e
+	changes = make(map[string]string)
+	if val, ok := get("retry_max_attempts"); ok {
+		c.RetryMaxAttempts, _ = utils.ConvertToInt(val)
+		changes["retry_max_attempts"] = strconv.Itoa(c.RetryMaxAttempts)
+	}
+	if val, ok := get("retry_wait_min"); ok {
+		wait, _ := utils.ConvertToInt(val)
+		c.RetryWaitMin = time.Duration(wait) * time.Second
+		changes["retry_wait_min"] = c.RetryWaitMin.String()
+	}
+	if val, ok := get("retry_wait_max"); ok {
+		wait, _ := utils.ConvertToInt(val)
+		c.RetryWaitMax = time.Duration(wait) * time.Second
+		changes["retry_wait_max"] = c.RetryWaitMax.String()
+	}
+
+	var _, waitMin, waitMax = c.RetryPolicy()
+	if c.RetryMaxAttempts < 0 || c.RetryMaxAttempts > DefaultRetryMaxAttemptsMax {
+		err = multierror.Append(err, fmt.Errorf("retry_max_attempts should be between 0 and %d, 0 uses the default: %w", DefaultRetryMaxAttemptsMax, errs.ErrInvalidValue))
+	}
+	if waitMax < waitMin || waitMax > DefaultRetryWaitMaxMax {
+		err = multierror.Append(err, fmt.Errorf("retry_wait_max should be between retry_wait_min and %s: %w", DefaultRetryWaitMaxMax, errs.ErrInvalidValue))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.RetryMaxAttempts, e.RetryWaitMin, e.RetryWaitMax = c.RetryMaxAttempts, c.RetryWaitMin, c.RetryWaitMax
+	return changes, nil
+}
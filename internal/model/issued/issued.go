@@ -0,0 +1,88 @@
+package issued
+
+import (
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+var _ model.Named = (*Token)(nil)
+var _ model.IsNil = (*Token)(nil)
+var _ model.LogicalResponseData = (*Token)(nil)
+
+// Token is the inventory record of a token issued by the plugin. It only holds
+// metadata about the token, the token value itself is never stored. A token that
+// waits in the pool of its role is Pooled, it has no lease until it is handed out.
+type Token struct {
+	ConfigName         string            `json:"config_name"`
+	RoleName           string            `json:"role_name"`
+	TokenID            int64             `json:"token_id"`
+	TokenType          token.Type        `json:"token_type"`
+	Name               string            `json:"name"`
+	Path               string            `json:"path"`
+	ParentID           string            `json:"parent_id"`
+	GitlabRevokesToken bool              `json:"gitlab_revokes_token"`
+	IssuedAt           time.Time         `json:"issued_at"`
+	ExpiresAt          time.Time         `json:"expires_at"`
+	Metadata           map[string]string `json:"metadata"`
+	Pooled             bool              `json:"pooled"`
+}
+
+// New creates an inventory record from an issued token.
+func New(t token.Token, issuedAt time.Time) *Token {
+	var internal = t.Internal()
+	var tokenId, _ = utils.ConvertToInt64(internal["token_id"])
+	var gitlabRevokesToken, _ = internal["gitlab_revokes_token"].(bool)
+	return &Token{
+		ConfigName:         str(internal["config_name"]),
+		RoleName:           str(internal["role_name"]),
+		TokenID:            tokenId,
+		TokenType:          t.Type(),
+		Name:               str(internal["name"]),
+		Path:               str(internal["path"]),
+		ParentID:           str(internal["parent_id"]),
+		GitlabRevokesToken: gitlabRevokesToken,
+		IssuedAt:           issuedAt,
+		ExpiresAt:          t.GetExpiresAt(),
+		// the event metadata is the same set of fields that is already
+		// published when a token is issued, it never contains the token
+		Metadata: t.Event(nil),
+	}
+}
+
+func (e Token) IsNil() bool { return false }
+
+func (e Token) GetName() string {
+	return strconv.FormatInt(e.TokenID, 10)
+}
+
+func (e Token) LogicalResponseData() map[string]any {
+	var expiresAt = ""
+	if !e.ExpiresAt.IsZero() {
+		expiresAt = e.ExpiresAt.Format(time.RFC3339)
+	}
+
+	return map[string]any{
+		"config_name":          e.ConfigName,
+		"role_name":            e.RoleName,
+		"token_id":             e.TokenID,
+		"token_type":           e.TokenType.String(),
+		"name":                 e.Name,
+		"path":                 e.Path,
+		"parent_id":            e.ParentID,
+		"gitlab_revokes_token": e.GitlabRevokesToken,
+		"issued_at":            e.IssuedAt.Format(time.RFC3339),
+		"expires_at":           expiresAt,
+		"metadata":             maps.Clone(e.Metadata),
+		"pooled":               e.Pooled,
+	}
+}
+
+func str(val any) string {
+	s, _ := val.(string)
+	return s
+}
@@ -0,0 +1,53 @@
+package issued_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestNew(t *testing.T) {
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var expiresAt = now.Add(time.Hour)
+	tok := &modelToken.TokenProject{
+		TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{
+			Token: modelToken.Token{
+				TokenID: 42, Token: "glpat-secret", Name: "name", Path: "group/project", ParentID: "group/project",
+				RoleName: "role", ConfigName: "default", GitlabRevokesToken: true,
+				TokenType: token.TypeProject, CreatedAt: &now, ExpiresAt: &expiresAt,
+			},
+			Scopes:      []string{"api"},
+			AccessLevel: token.AccessLevelDeveloperPermissions,
+		},
+	}
+
+	entry := issued.New(tok, now)
+	require.NotNil(t, entry)
+	require.False(t, entry.IsNil())
+	assert.Equal(t, "42", entry.GetName())
+	assert.Equal(t, "default", entry.ConfigName)
+	assert.Equal(t, "role", entry.RoleName)
+	assert.Equal(t, token.TypeProject, entry.TokenType)
+	assert.Equal(t, "group/project", entry.Path)
+	assert.True(t, entry.GitlabRevokesToken)
+	assert.Equal(t, expiresAt, entry.ExpiresAt)
+	assert.Equal(t, "developer", entry.Metadata["access_level"])
+
+	data := entry.LogicalResponseData()
+	assert.EqualValues(t, 42, data["token_id"])
+	assert.Equal(t, false, data["pooled"])
+	assert.Equal(t, now.Format(time.RFC3339), data["issued_at"])
+	assert.Equal(t, expiresAt.Format(time.RFC3339), data["expires_at"])
+	for _, v := range data {
+		assert.NotEqual(t, "glpat-secret", v)
+	}
+	for _, v := range entry.Metadata {
+		assert.NotEqual(t, "glpat-secret", v)
+	}
+}
@@ -0,0 +1,101 @@
+package pool
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+var _ model.Named = (*Entry)(nil)
+var _ model.IsNil = (*Entry)(nil)
+var _ token.Token = (*Entry)(nil)
+
+// Entry is a token that was created ahead of a request and waits in the pool of its role to be handed out. It
+// holds the value of the token, so the pool is kept in seal-wrapped storage.
+type Entry struct {
+	RoleName     string            `json:"role_name"`
+	RoleHash     string            `json:"role_hash"`
+	TokenID      int64             `json:"token_id"`
+	TokenType    token.Type        `json:"token_type"`
+	CreatedAt    time.Time         `json:"created_at"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+	SecretData   map[string]any    `json:"data"`
+	InternalData map[string]any    `json:"internal"`
+	EventData    map[string]string `json:"event"`
+}
+
+// New creates a pool entry from a token that was created at createdAt for the role with the given hash.
+func New(t token.Token, createdAt time.Time, roleHash string) *Entry {
+	var internal = t.Internal()
+	var e = &Entry{
+		RoleHash:     roleHash,
+		TokenType:    t.Type(),
+		CreatedAt:    createdAt,
+		ExpiresAt:    t.GetExpiresAt(),
+		SecretData:   t.Data(),
+		InternalData: internal,
+		EventData:    t.Event(nil),
+	}
+	e.RoleName, _ = internal["role_name"].(string)
+	e.TokenID, _ = utils.ConvertToInt64(internal["token_id"])
+	return e
+}
+
+// RoleHash returns the hash of the settings of the role the tokens are created with, a pooled token is only handed
+// out while the role is unchanged. The size and max age of the pool don't change the tokens, so they are left out.
+func RoleHash(r role.Role) string {
+	r.PoolSize, r.PoolMaxAge = 0, 0
+	var raw, _ = json.Marshal(r)
+	return fmt.Sprintf("%x", sha256.Sum256(raw))
+}
+
+// Usable reports if the token can still be handed out at the given time for the role.
+func (e Entry) Usable(now time.Time, r role.Role) bool {
+	return e.RoleHash == RoleHash(r) && now.Before(e.CreatedAt.Add(r.PoolMaxAge))
+}
+
+func (e Entry) IsNil() bool { return false }
+
+func (e Entry) GetName() string {
+	return strconv.FormatInt(e.TokenID, 10)
+}
+
+func (e *Entry) Internal() map[string]any { return maps.Clone(e.InternalData) }
+func (e *Entry) Data() map[string]any     { return maps.Clone(e.SecretData) }
+func (e *Entry) Type() token.Type         { return e.TokenType }
+func (e *Entry) GetExpiresAt() time.Time  { return e.ExpiresAt }
+func (e *Entry) GetCreatedAt() time.Time  { return e.CreatedAt }
+func (e *Entry) TTL() time.Duration       { return e.ExpiresAt.Sub(e.CreatedAt) }
+
+func (e *Entry) Event(m map[string]string) (d map[string]string) {
+	d = maps.Clone(e.EventData)
+	d["ttl"] = e.TTL().String()
+	maps.Copy(d, m)
+	return d
+}
+
+func (e *Entry) SetConfigName(name string) { e.set("config_name", name) }
+func (e *Entry) SetRoleName(name string)   { e.RoleName = name; e.set("role_name", name) }
+
+func (e *Entry) SetGitlabRevokesToken(b bool) {
+	e.InternalData["gitlab_revokes_token"] = b
+}
+
+func (e *Entry) SetExpiresAt(expiresAt *time.Time) {
+	if expiresAt != nil {
+		e.ExpiresAt = *expiresAt
+	}
+	e.SecretData["expires_at"], e.InternalData["expires_at"] = expiresAt, expiresAt
+}
+
+func (e *Entry) set(key, value string) {
+	e.SecretData[key], e.InternalData[key], e.EventData[key] = value, value, value
+}
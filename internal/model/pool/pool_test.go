@@ -0,0 +1,71 @@
+package pool_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/pool"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestNew(t *testing.T) {
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var expiresAt = now.Add(2 * time.Hour)
+	var r = role.Role{RoleName: "role", TTL: time.Hour, Path: "group/project", TokenType: token.TypeProject, PoolSize: 2, PoolMaxAge: time.Hour}
+	tok := &modelToken.TokenProject{
+		TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{
+			Token: modelToken.Token{
+				TokenID: 42, Token: "glpat-secret", Name: "name", Path: "group/project", ParentID: "group/project",
+				RoleName: "role", ConfigName: "default", TokenType: token.TypeProject, CreatedAt: &now, ExpiresAt: &expiresAt,
+			},
+			Scopes:      []string{"api"},
+			AccessLevel: token.AccessLevelDeveloperPermissions,
+		},
+	}
+
+	// the entry has to work the same after it is read back from storage
+	var entry pool.Entry
+	raw, err := logical.StorageEntryJSON("pool/role/42", pool.New(tok, now, pool.RoleHash(r)))
+	require.NoError(t, err)
+	require.NoError(t, raw.DecodeJSON(&entry))
+
+	require.False(t, entry.IsNil())
+	assert.Equal(t, "42", entry.GetName())
+	assert.Equal(t, "role", entry.RoleName)
+	assert.Equal(t, token.TypeProject, entry.Type())
+	assert.Equal(t, "glpat-secret", entry.Data()["token"])
+	assert.Equal(t, "2h0m0s", entry.Event(nil)["ttl"])
+	assert.EqualValues(t, 42, revocation.New(&entry).TokenID)
+
+	t.Run("usable", func(t *testing.T) {
+		assert.True(t, entry.Usable(now.Add(59*time.Minute), r))
+		assert.False(t, entry.Usable(now.Add(time.Hour), r), "older than the max age of the pool")
+
+		var resized = r
+		resized.PoolSize, resized.PoolMaxAge = 5, 2*time.Hour
+		assert.True(t, entry.Usable(now.Add(90*time.Minute), resized), "the size of the pool doesn't change the tokens")
+
+		var changed = r
+		changed.Scopes = []string{"read_api"}
+		assert.False(t, entry.Usable(now, changed), "the role has changed")
+	})
+
+	t.Run("setters", func(t *testing.T) {
+		var handedOut = now.Add(3 * time.Hour)
+		entry.SetExpiresAt(&handedOut)
+		entry.SetConfigName("other")
+		entry.SetGitlabRevokesToken(true)
+		assert.Equal(t, handedOut, entry.GetExpiresAt())
+		assert.Equal(t, &handedOut, entry.Data()["expires_at"])
+		assert.Equal(t, "other", entry.Internal()["config_name"])
+		assert.Equal(t, "other", entry.Event(nil)["config_name"])
+		assert.Equal(t, true, entry.Internal()["gitlab_revokes_token"])
+	})
+}
@@ -0,0 +1,78 @@
+package reuse
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+var _ model.Named = (*Entry)(nil)
+var _ model.IsNil = (*Entry)(nil)
+
+// Entry is a token that is shared by the leases of identical requests, made by the same entity within the reuse
+// window of the role. It counts the leases that hold the token, the token is only revoked in GitLab when the last
+// of them is revoked. It holds the value of the token, so it is kept in seal-wrapped storage.
+type Entry struct {
+	Key          string            `json:"key"`
+	TokenID      int64             `json:"token_id"`
+	IssuedAt     time.Time         `json:"issued_at"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+	Leases       int               `json:"leases"`
+	SecretData   map[string]any    `json:"data"`
+	InternalData map[string]any    `json:"internal"`
+	EventData    map[string]string `json:"event"`
+}
+
+// New creates the shared entry of a token that was issued at issuedAt for the given key, held by a single lease.
+func New(key string, t token.Token, issuedAt time.Time) *Entry {
+	var internal = t.Internal()
+	var e = &Entry{
+		Key:          key,
+		IssuedAt:     issuedAt,
+		ExpiresAt:    t.GetExpiresAt(),
+		Leases:       1,
+		SecretData:   t.Data(),
+		InternalData: internal,
+		EventData:    t.Event(nil),
+	}
+	e.TokenID, _ = utils.ConvertToInt64(internal["token_id"])
+	return e
+}
+
+// Key returns the key that identical requests share, it's the entity that made the request and the role after the
+// path was resolved and the request narrowed it, so only requests for exactly the same token share it.
+func Key(entityId string, r role.Role) string {
+	var raw, _ = json.Marshal(struct {
+		EntityID string    `json:"entity_id"`
+		Role     role.Role `json:"role"`
+	}{EntityID: entityId, Role: r})
+	return fmt.Sprintf("%x", sha256.Sum256(raw))
+}
+
+// Joinable reports if a request made at the given time can share the token, within the window after it was issued.
+func (e Entry) Joinable(now time.Time, window time.Duration) bool {
+	return now.Before(e.IssuedAt.Add(window)) && now.Before(e.ExpiresAt)
+}
+
+func (e Entry) IsNil() bool { return false }
+
+func (e Entry) GetName() string {
+	return strconv.FormatInt(e.TokenID, 10)
+}
+
+func (e *Entry) Internal() map[string]any { return maps.Clone(e.InternalData) }
+func (e *Entry) Data() map[string]any     { return maps.Clone(e.SecretData) }
+
+func (e *Entry) Event(m map[string]string) (d map[string]string) {
+	d = maps.Clone(e.EventData)
+	maps.Copy(d, m)
+	return d
+}
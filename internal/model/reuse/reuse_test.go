@@ -0,0 +1,66 @@
+package reuse_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestNew(t *testing.T) {
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var expiresAt = now.Add(2 * time.Hour)
+	tok := &modelToken.TokenProject{
+		TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{
+			Token: modelToken.Token{
+				TokenID: 42, Token: "glpat-secret", Name: "name", Path: "group/project", ParentID: "group/project",
+				RoleName: "role", ConfigName: "default", TokenType: token.TypeProject, CreatedAt: &now, ExpiresAt: &expiresAt,
+			},
+			Scopes:      []string{"api"},
+			AccessLevel: token.AccessLevelDeveloperPermissions,
+		},
+	}
+
+	// the entry has to work the same after it is read back from storage
+	var entry reuse.Entry
+	raw, err := logical.StorageEntryJSON("reuse/key/42", reuse.New("key", tok, now))
+	require.NoError(t, err)
+	require.NoError(t, raw.DecodeJSON(&entry))
+
+	require.False(t, entry.IsNil())
+	assert.Equal(t, "42", entry.GetName())
+	assert.Equal(t, "key", entry.Key)
+	assert.Equal(t, 1, entry.Leases)
+	assert.Equal(t, expiresAt, entry.ExpiresAt)
+	assert.Equal(t, "glpat-secret", entry.Data()["token"])
+	assert.Equal(t, "role", entry.Internal()["role_name"])
+	assert.Equal(t, "shared", entry.Event(map[string]string{"reused": "shared"})["reused"])
+	assert.Equal(t, "default", entry.Event(nil)["config_name"])
+
+	t.Run("joinable", func(t *testing.T) {
+		assert.True(t, entry.Joinable(now.Add(59*time.Second), time.Minute))
+		assert.False(t, entry.Joinable(now.Add(time.Minute), time.Minute), "outside of the reuse window")
+		assert.False(t, entry.Joinable(expiresAt, 3*time.Hour), "the token has expired")
+	})
+}
+
+func TestKey(t *testing.T) {
+	var r = role.Role{RoleName: "role", TTL: time.Hour, Path: "group/project", TokenType: token.TypeProject}
+	assert.Equal(t, reuse.Key("entity", r), reuse.Key("entity", r))
+	assert.NotEqual(t, reuse.Key("entity", r), reuse.Key("other", r), "a token is never shared between entities")
+
+	var narrowed = r
+	narrowed.TTL = time.Minute
+	assert.NotEqual(t, reuse.Key("entity", r), reuse.Key("entity", narrowed), "a narrowed request gets its own token")
+
+	var path = r
+	path.Path = "group/other"
+	assert.NotEqual(t, reuse.Key("entity", r), reuse.Key("entity", path))
+}
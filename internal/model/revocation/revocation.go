@@ -0,0 +1,127 @@
+package revocation
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+const (
+	// DefaultBackoffBase is the delay before the first retry of a deferred revocation.
+	DefaultBackoffBase = time.Minute
+
+	// DefaultBackoffMax caps the delay between retries of a deferred revocation.
+	DefaultBackoffMax = 6 * time.Hour
+)
+
+// SelfRevokingTokenTypes are the token types that can only be revoked with the token itself,
+// these are the only entries that carry the token value.
+var SelfRevokingTokenTypes = []token.Type{
+	token.TypeUserServiceAccount,
+	token.TypeGroupServiceAccount,
+	token.TypeProjectServiceAccount,
+}
+
+var _ model.Named = (*Entry)(nil)
+var _ model.IsNil = (*Entry)(nil)
+var _ model.LogicalResponseData = (*Entry)(nil)
+
+// Entry is a revocation that could not be completed in GitLab at the time the lease was revoked.
+// It holds everything needed to revoke the token later without the lease.
+type Entry struct {
+	LeaseID       string     `json:"lease_id"`
+	ConfigName    string     `json:"config_name"`
+	RoleName      string     `json:"role_name"`
+	TokenID       int64      `json:"token_id"`
+	TokenType     token.Type `json:"token_type"`
+	ParentID      string     `json:"parent_id"`
+	UserID        int64      `json:"user_id"`
+	AgentID       int64      `json:"agent_id"`
+	AgentCreated  bool       `json:"agent_created"`
+	OAuthMode     string     `json:"oauth_mode"`
+	Path          string     `json:"path"`
+	Name          string     `json:"name"`
+	Token         string     `json:"token"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	CreatedAt     time.Time  `json:"created_at"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+}
+
+// New creates an entry that can be used to revoke the issued token.
+func New(t token.Token) *Entry {
+	var internal = t.Internal()
+	var e = &Entry{TokenType: t.Type()}
+	e.TokenID, _ = utils.ConvertToInt64(internal["token_id"])
+	e.ConfigName, _ = internal["config_name"].(string)
+	e.RoleName, _ = internal["role_name"].(string)
+	e.ParentID, _ = internal["parent_id"].(string)
+	e.UserID, _ = utils.ConvertToInt64(internal["user_id"])
+	e.AgentID, _ = utils.ConvertToInt64(internal["agent_id"])
+	e.AgentCreated, _ = internal["agent_created"].(bool)
+	e.OAuthMode, _ = internal["oauth_mode"].(string)
+	e.Path, _ = internal["path"].(string)
+	e.Name, _ = internal["name"].(string)
+	if slices.Contains(SelfRevokingTokenTypes, e.TokenType) {
+		e.Token, _ = internal["token"].(string)
+	}
+	return e
+}
+
+func (e Entry) IsNil() bool { return false }
+
+// GetName returns the key of the entry, there can only be a single pending revocation for a token.
+func (e Entry) GetName() string {
+	return fmt.Sprintf("%s-%s-%d", e.ConfigName, e.TokenType.String(), e.TokenID)
+}
+
+// Due reports if the next attempt to revoke the token should be made at the given time.
+func (e Entry) Due(now time.Time) bool {
+	return !now.Before(e.NextAttemptAt)
+}
+
+// Failed records a failed attempt and schedules the next one with an exponential backoff and jitter.
+func (e *Entry) Failed(now time.Time, err error) {
+	e.Attempts++
+	if err != nil {
+		e.LastError = err.Error()
+	}
+	e.NextAttemptAt = now.Add(Backoff(e.Attempts))
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts. The delay is between
+// half and the whole of the exponential backoff, so the revocations that failed together don't retry together.
+func Backoff(attempts int) time.Duration {
+	var delay = DefaultBackoffBase
+	for i := 1; i < attempts && delay < DefaultBackoffMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, DefaultBackoffMax)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (e Entry) LogicalResponseData() map[string]any {
+	return map[string]any{
+		"lease_id":        e.LeaseID,
+		"config_name":     e.ConfigName,
+		"role_name":       e.RoleName,
+		"token_id":        e.TokenID,
+		"token_type":      e.TokenType.String(),
+		"parent_id":       e.ParentID,
+		"user_id":         e.UserID,
+		"agent_id":        e.AgentID,
+		"agent_created":   e.AgentCreated,
+		"oauth_mode":      e.OAuthMode,
+		"path":            e.Path,
+		"name":            e.Name,
+		"attempts":        e.Attempts,
+		"last_error":      e.LastError,
+		"created_at":      e.CreatedAt.Format(time.RFC3339),
+		"next_attempt_at": e.NextAttemptAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,105 @@
+package revocation_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestBackoff(t *testing.T) {
+	for attempts, delay := range map[int]time.Duration{
+		0:   time.Minute,
+		1:   time.Minute,
+		2:   2 * time.Minute,
+		3:   4 * time.Minute,
+		100: revocation.DefaultBackoffMax,
+	} {
+		backoff := revocation.Backoff(attempts)
+		assert.GreaterOrEqual(t, backoff, delay/2, "attempts %d", attempts)
+		assert.LessOrEqual(t, backoff, delay, "attempts %d", attempts)
+	}
+}
+
+func TestEntry(t *testing.T) {
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := &revocation.Entry{ConfigName: "default", TokenType: token.TypeProject, TokenID: 42, Token: "glpat-secret", CreatedAt: now, NextAttemptAt: now}
+
+	assert.False(t, e.IsNil())
+	assert.Equal(t, "default-project-42", e.GetName())
+	assert.True(t, e.Due(now))
+
+	e.Failed(now, errors.New("gitlab unreachable"))
+	assert.Equal(t, 1, e.Attempts)
+	assert.Equal(t, "gitlab unreachable", e.LastError)
+	assert.WithinRange(t, e.NextAttemptAt, now.Add(30*time.Second), now.Add(time.Minute))
+	assert.False(t, e.Due(now))
+	assert.True(t, e.Due(now.Add(time.Minute)))
+
+	e.Failed(now.Add(time.Minute), errors.New("502"))
+	assert.WithinRange(t, e.NextAttemptAt, now.Add(2*time.Minute), now.Add(3*time.Minute))
+
+	data := e.LogicalResponseData()
+	assert.NotContains(t, data, "token")
+	assert.Equal(t, 2, data["attempts"])
+	assert.Equal(t, e.NextAttemptAt.Format(time.RFC3339), data["next_attempt_at"])
+}
+
+func TestNew(t *testing.T) {
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := modelToken.Token{
+		TokenID: 42, Token: "glpat-secret", Name: "name", Path: "group/sa", ParentID: "group",
+		RoleName: "role", ConfigName: "default", CreatedAt: &now, ExpiresAt: &now,
+	}
+
+	t.Run("revoked by id", func(t *testing.T) {
+		tok := base
+		tok.TokenType = token.TypeGroup
+		e := revocation.New(&modelToken.TokenGroup{TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{Token: tok}})
+		assert.EqualValues(t, 42, e.TokenID)
+		assert.Equal(t, token.TypeGroup, e.TokenType)
+		assert.Equal(t, "default", e.ConfigName)
+		assert.Equal(t, "role", e.RoleName)
+		assert.Equal(t, "group", e.ParentID)
+		assert.Empty(t, e.Token)
+	})
+
+	t.Run("self revoking", func(t *testing.T) {
+		tok := base
+		tok.TokenType = token.TypeGroupServiceAccount
+		e := revocation.New(&modelToken.TokenGroupServiceAccount{TokenWithScopes: modelToken.TokenWithScopes{Token: tok}})
+		assert.Equal(t, token.TypeGroupServiceAccount, e.TokenType)
+		assert.Equal(t, "glpat-secret", e.Token)
+	})
+	t.Run("ephemeral service account", func(t *testing.T) {
+		tok := base
+		tok.TokenType = token.TypeEphemeralGroupServiceAccount
+		e := revocation.New(&modelToken.TokenEphemeralServiceAccount{TokenWithScopes: modelToken.TokenWithScopes{Token: tok}, UserID: 7})
+		assert.Equal(t, token.TypeEphemeralGroupServiceAccount, e.TokenType)
+		assert.EqualValues(t, 7, e.UserID)
+		assert.Empty(t, e.Token)
+	})
+
+	t.Run("cluster agent", func(t *testing.T) {
+		tok := base
+		tok.TokenType = token.TypeClusterAgent
+		e := revocation.New(&modelToken.TokenClusterAgent{Token: tok, AgentID: 3, AgentName: "production"})
+		assert.Equal(t, token.TypeClusterAgent, e.TokenType)
+		assert.EqualValues(t, 3, e.AgentID)
+		assert.Empty(t, e.Token)
+	})
+
+	t.Run("oauth application", func(t *testing.T) {
+		tok := base
+		tok.TokenType = token.TypeOAuthApplication
+		e := revocation.New(&modelToken.TokenOAuthApplication{TokenWithScopes: modelToken.TokenWithScopes{Token: tok}, ClientID: "client-id", Mode: token.OAuthApplicationModeRotate})
+		assert.Equal(t, token.TypeOAuthApplication, e.TokenType)
+		assert.Equal(t, token.OAuthApplicationModeRotate, e.OAuthMode)
+		assert.Empty(t, e.Token)
+	})
+}
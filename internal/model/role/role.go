@@ -15,6 +15,7 @@ var _ model.LogicalResponseData = (*Role)(nil)
 type Role struct {
 	RoleName            string            `json:"role_name" structs:"role_name" mapstructure:"role_name"`
 	TTL                 time.Duration     `json:"ttl" structs:"ttl" mapstructure:"ttl"`
+	MaxTTL              time.Duration     `json:"max_ttl" structs:"max_ttl" mapstructure:"max_ttl"`
 	Path                string            `json:"path" structs:"path" mapstructure:"path"`
 	Name                string            `json:"name" structs:"name" mapstructure:"name"`
 	Scopes              []string          `json:"scopes" structs:"scopes" mapstructure:"scopes"`
@@ -23,6 +24,31 @@ type Role struct {
 	GitlabRevokesTokens bool              `json:"gitlab_revokes_token" structs:"gitlab_revokes_token" mapstructure:"gitlab_revokes_token"`
 	DynamicPath         bool              `json:"dynamic_path" structs:"dynamic_path" mapstructure:"dynamic_path"`
 	ConfigName          string            `json:"config_name" structs:"config_name" mapstructure:"config_name"`
+	RunnerTags          []string          `json:"runner_tags,omitempty" structs:"runner_tags" mapstructure:"runner_tags"`
+	RunnerRunUntagged   bool              `json:"runner_run_untagged,omitempty" structs:"runner_run_untagged" mapstructure:"runner_run_untagged"`
+	RunnerLocked        bool              `json:"runner_locked,omitempty" structs:"runner_locked" mapstructure:"runner_locked"`
+	RunnerDescription   string            `json:"runner_description,omitempty" structs:"runner_description" mapstructure:"runner_description"`
+	ClusterAgentCreate  bool              `json:"cluster_agent_create,omitempty" structs:"cluster_agent_create" mapstructure:"cluster_agent_create"`
+	CanPush             bool              `json:"can_push,omitempty" structs:"can_push" mapstructure:"can_push"`
+	OAuthMode           string            `json:"oauth_mode,omitempty" structs:"oauth_mode" mapstructure:"oauth_mode"`
+	OAuthRedirectURIs   []string          `json:"oauth_redirect_uris,omitempty" structs:"oauth_redirect_uris" mapstructure:"oauth_redirect_uris"`
+	OAuthConfidential   bool              `json:"oauth_confidential,omitempty" structs:"oauth_confidential" mapstructure:"oauth_confidential"`
+
+	EntityAliasMountAccessor string `json:"entity_alias_mount_accessor,omitempty" structs:"entity_alias_mount_accessor" mapstructure:"entity_alias_mount_accessor"`
+	EntityMetadataKey        string `json:"entity_metadata_key,omitempty" structs:"entity_metadata_key" mapstructure:"entity_metadata_key"`
+
+	CIVariableKey              string `json:"ci_variable_key,omitempty" structs:"ci_variable_key" mapstructure:"ci_variable_key"`
+	CIVariableTarget           string `json:"ci_variable_target,omitempty" structs:"ci_variable_target" mapstructure:"ci_variable_target"`
+	CIVariablePath             string `json:"ci_variable_path,omitempty" structs:"ci_variable_path" mapstructure:"ci_variable_path"`
+	CIVariableEnvironmentScope string `json:"ci_variable_environment_scope,omitempty" structs:"ci_variable_environment_scope" mapstructure:"ci_variable_environment_scope"`
+	CIVariableProtected        bool   `json:"ci_variable_protected,omitempty" structs:"ci_variable_protected" mapstructure:"ci_variable_protected"`
+	CIVariableMasked           bool   `json:"ci_variable_masked,omitempty" structs:"ci_variable_masked" mapstructure:"ci_variable_masked"`
+	CIVariableValue            string `json:"ci_variable_value,omitempty" structs:"ci_variable_value" mapstructure:"ci_variable_value"`
+
+	PoolSize   int           `json:"pool_size,omitempty" structs:"pool_size" mapstructure:"pool_size"`
+	PoolMaxAge time.Duration `json:"pool_max_age,omitempty" structs:"pool_max_age" mapstructure:"pool_max_age"`
+
+	ReuseWindow time.Duration `json:"reuse_window,omitempty" structs:"reuse_window" mapstructure:"reuse_window"`
 }
 
 func (e Role) IsNil() bool { return false }
@@ -31,6 +57,29 @@ func (e Role) GetName() string {
 	return e.Name
 }
 
+// GetMaxTTL returns the maximum lifetime of a token issued from this role, if max_ttl
+// was not configured then it falls back to the ttl of the role.
+func (e Role) GetMaxTTL() time.Duration {
+	return max(e.TTL, e.MaxTTL)
+}
+
+// UsernameFromEntity reports whether the username is taken from the Vault entity that requests the token, in which
+// case the path of the role is a regex the username has to match.
+func (e Role) UsernameFromEntity() bool {
+	return e.EntityAliasMountAccessor != "" || e.EntityMetadataKey != ""
+}
+
+// PathTemplated reports whether the path of the role references Vault identity templates, which are resolved for
+// the entity that requests the token.
+func (e Role) PathTemplated() bool {
+	return strings.Contains(e.Path, "{{")
+}
+
+// Pooled reports whether tokens of the role are created ahead of the requests and kept in a pool.
+func (e Role) Pooled() bool {
+	return e.PoolSize > 0
+}
+
 func (e Role) LogicalResponseData() map[string]any {
 	return map[string]any{
 		"role_name":            e.RoleName,
@@ -39,9 +88,35 @@ func (e Role) LogicalResponseData() map[string]any {
 		"scopes":               strings.Join(e.Scopes, ", "),
 		"access_level":         e.AccessLevel.String(),
 		"ttl":                  int64(e.TTL / time.Second),
+		"max_ttl":              int64(e.GetMaxTTL() / time.Second),
 		"token_type":           e.TokenType.String(),
 		"dynamic_path":         e.DynamicPath,
 		"gitlab_revokes_token": e.GitlabRevokesTokens,
 		"config_name":          e.ConfigName,
+		"runner_tags":          strings.Join(e.RunnerTags, ", "),
+		"runner_run_untagged":  e.RunnerRunUntagged,
+		"runner_locked":        e.RunnerLocked,
+		"runner_description":   e.RunnerDescription,
+		"cluster_agent_create": e.ClusterAgentCreate,
+		"can_push":             e.CanPush,
+		"oauth_mode":           e.OAuthMode,
+		"oauth_redirect_uris":  strings.Join(e.OAuthRedirectURIs, ", "),
+		"oauth_confidential":   e.OAuthConfidential,
+
+		"entity_alias_mount_accessor": e.EntityAliasMountAccessor,
+		"entity_metadata_key":         e.EntityMetadataKey,
+
+		"ci_variable_key":               e.CIVariableKey,
+		"ci_variable_target":            e.CIVariableTarget,
+		"ci_variable_path":              e.CIVariablePath,
+		"ci_variable_environment_scope": e.CIVariableEnvironmentScope,
+		"ci_variable_protected":         e.CIVariableProtected,
+		"ci_variable_masked":            e.CIVariableMasked,
+		"ci_variable_value":             e.CIVariableValue,
+
+		"pool_size":    e.PoolSize,
+		"pool_max_age": int64(e.PoolMaxAge / time.Second),
+
+		"reuse_window": int64(e.ReuseWindow / time.Second),
 	}
 }
@@ -2,6 +2,7 @@ package role_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -14,3 +15,20 @@ func TestRole(t *testing.T) {
 	require.EqualValues(t, "Name", r.GetName())
 	require.NotEmpty(t, r.LogicalResponseData())
 }
+
+func TestRoleGetMaxTTL(t *testing.T) {
+	require.Equal(t, time.Hour, role.Role{TTL: time.Hour}.GetMaxTTL())
+	require.Equal(t, 2*time.Hour, role.Role{TTL: time.Hour, MaxTTL: 2 * time.Hour}.GetMaxTTL())
+	require.EqualValues(t, 7200, role.Role{TTL: time.Hour, MaxTTL: 2 * time.Hour}.LogicalResponseData()["max_ttl"])
+}
+
+func TestRolePathTemplated(t *testing.T) {
+	require.False(t, role.Role{Path: "example/app"}.PathTemplated())
+	require.True(t, role.Role{Path: "{{identity.entity.metadata.gitlab_group}}/app"}.PathTemplated())
+}
+
+func TestRolePooled(t *testing.T) {
+	require.False(t, role.Role{}.Pooled())
+	require.True(t, role.Role{PoolSize: 3}.Pooled())
+	require.EqualValues(t, 3600, role.Role{PoolSize: 3, PoolMaxAge: time.Hour}.LogicalResponseData()["pool_max_age"])
+}
@@ -0,0 +1,84 @@
+package role
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+var _ model.Named = (*StaticRole)(nil)
+var _ model.IsNil = (*StaticRole)(nil)
+var _ model.LogicalResponseDataWithOptions[bool] = (*StaticRole)(nil)
+
+// StaticRole takes ownership of an already existing GitLab token and keeps it rotated
+// on a schedule. The current token value is stored alongside the role.
+type StaticRole struct {
+	RoleName       string        `json:"role_name" structs:"role_name" mapstructure:"role_name"`
+	ConfigName     string        `json:"config_name" structs:"config_name" mapstructure:"config_name"`
+	TokenType      token.Type    `json:"token_type" structs:"token_type" mapstructure:"token_type"`
+	Path           string        `json:"path" structs:"path" mapstructure:"path"`
+	TTL            time.Duration `json:"ttl" structs:"ttl" mapstructure:"ttl"`
+	RotationPeriod time.Duration `json:"rotation_period" structs:"rotation_period" mapstructure:"rotation_period"`
+	TokenID        int64         `json:"token_id" structs:"token_id" mapstructure:"token_id"`
+	Token          string        `json:"token" structs:"token" mapstructure:"token"`
+	Name           string        `json:"name" structs:"name" mapstructure:"name"`
+	Scopes         []string      `json:"scopes" structs:"scopes" mapstructure:"scopes"`
+	TokenCreatedAt time.Time     `json:"token_created_at" structs:"token_created_at" mapstructure:"token_created_at"`
+	TokenExpiresAt time.Time     `json:"token_expires_at" structs:"token_expires_at" mapstructure:"token_expires_at"`
+	LastRotatedAt  time.Time     `json:"last_rotated_at" structs:"last_rotated_at" mapstructure:"last_rotated_at"`
+}
+
+func (e StaticRole) IsNil() bool { return false }
+
+func (e StaticRole) GetName() string {
+	return e.RoleName
+}
+
+// NextRotation returns the time when the token is due to be rotated.
+func (e StaticRole) NextRotation() time.Time {
+	return e.LastRotatedAt.Add(e.RotationPeriod)
+}
+
+// NeedsRotation reports if the token is due to be rotated at the given time.
+func (e StaticRole) NeedsRotation(now time.Time) bool {
+	return !now.Before(e.NextRotation())
+}
+
+func (e StaticRole) LogicalResponseData(includeToken bool) map[string]any {
+	var tokenExpiresAt, tokenCreatedAt, lastRotatedAt = "", "", ""
+	if !e.TokenExpiresAt.IsZero() {
+		tokenExpiresAt = e.TokenExpiresAt.Format(time.RFC3339)
+	}
+	if !e.TokenCreatedAt.IsZero() {
+		tokenCreatedAt = e.TokenCreatedAt.Format(time.RFC3339)
+	}
+	if !e.LastRotatedAt.IsZero() {
+		lastRotatedAt = e.LastRotatedAt.Format(time.RFC3339)
+	}
+
+	data := map[string]any{
+		"role_name":        e.RoleName,
+		"config_name":      e.ConfigName,
+		"token_type":       e.TokenType.String(),
+		"path":             e.Path,
+		"ttl":              int64(e.TTL / time.Second),
+		"rotation_period":  int64(e.RotationPeriod / time.Second),
+		"token_id":         e.TokenID,
+		"name":             e.Name,
+		"scopes":           strings.Join(e.Scopes, ", "),
+		"token_created_at": tokenCreatedAt,
+		"token_expires_at": tokenExpiresAt,
+		"last_rotated_at":  lastRotatedAt,
+		"token_sha1_hash":  fmt.Sprintf("%x", sha1.Sum([]byte(e.Token))),
+	}
+
+	if includeToken {
+		data["token"] = e.Token
+	}
+
+	return data
+}
@@ -0,0 +1,26 @@
+package role_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+)
+
+func TestStaticRole(t *testing.T) {
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := role.StaticRole{RoleName: "static", Token: "glpat-secret", RotationPeriod: time.Hour, LastRotatedAt: now}
+	require.False(t, r.IsNil())
+	require.EqualValues(t, "static", r.GetName())
+	require.Equal(t, now.Add(time.Hour), r.NextRotation())
+	require.False(t, r.NeedsRotation(now.Add(59*time.Minute)))
+	require.True(t, r.NeedsRotation(now.Add(time.Hour)))
+
+	data := r.LogicalResponseData(false)
+	require.NotContains(t, data, "token")
+	require.Equal(t, now.Format(time.RFC3339), data["last_rotated_at"])
+	require.Empty(t, data["token_expires_at"])
+	require.Equal(t, "glpat-secret", r.LogicalResponseData(true)["token"])
+}
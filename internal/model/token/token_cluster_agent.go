@@ -0,0 +1,38 @@
+package token
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// TokenClusterAgent is a token of a GitLab agent for Kubernetes, the ParentID is the id of the
+// project the agent is registered with. AgentCreated is set when the agent was registered for the token.
+type TokenClusterAgent struct {
+	Token `json:",inline"`
+
+	AgentID      int64  `json:"agent_id"`
+	AgentName    string `json:"agent_name"`
+	AgentCreated bool   `json:"agent_created"`
+}
+
+func (t *TokenClusterAgent) Internal() (d map[string]any) {
+	d = map[string]any{"agent_id": t.AgentID, "agent_name": t.AgentName, "agent_created": t.AgentCreated}
+	maps.Copy(d, t.Token.Internal())
+	return d
+}
+
+func (t *TokenClusterAgent) Data() (d map[string]any) {
+	d = map[string]any{"agent_id": t.AgentID, "agent_name": t.AgentName, "agent_created": t.AgentCreated}
+	maps.Copy(d, t.Token.Data())
+	return d
+}
+
+func (t *TokenClusterAgent) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{"agent_id": strconv.FormatInt(t.AgentID, 10), "agent_name": t.AgentName}
+	maps.Copy(d, t.Token.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenClusterAgent)(nil)
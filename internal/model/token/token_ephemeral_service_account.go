@@ -0,0 +1,50 @@
+package token
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// TokenEphemeralServiceAccount is a token of a service account that was created for the lease,
+// the service account is deleted together with the token when the lease is revoked.
+type TokenEphemeralServiceAccount struct {
+	TokenWithScopes `json:",inline"`
+
+	UserID      int64             `json:"user_id"`
+	Username    string            `json:"username"`
+	AccessLevel token.AccessLevel `json:"access_level"`
+}
+
+func (t *TokenEphemeralServiceAccount) Internal() (d map[string]any) {
+	d = map[string]any{
+		"user_id":      t.UserID,
+		"username":     t.Username,
+		"access_level": t.AccessLevel.String(),
+	}
+	maps.Copy(d, t.TokenWithScopes.Internal())
+	return d
+}
+
+func (t *TokenEphemeralServiceAccount) Data() (d map[string]any) {
+	d = map[string]any{
+		"user_id":      t.UserID,
+		"username":     t.Username,
+		"access_level": t.AccessLevel.String(),
+	}
+	maps.Copy(d, t.TokenWithScopes.Data())
+	return d
+}
+
+func (t *TokenEphemeralServiceAccount) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{
+		"user_id":      strconv.FormatInt(t.UserID, 10),
+		"username":     t.Username,
+		"access_level": t.AccessLevel.String(),
+	}
+	maps.Copy(d, t.TokenWithScopes.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenEphemeralServiceAccount)(nil)
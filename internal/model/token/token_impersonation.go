@@ -0,0 +1,36 @@
+package token
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// TokenImpersonation is an impersonation token of a user, it's created by an admin and shows up
+// separately from the personal access tokens of the user.
+type TokenImpersonation struct {
+	TokenWithScopes `json:",inline"`
+
+	UserID int64 `json:"user_id"`
+}
+
+func (t *TokenImpersonation) Internal() (d map[string]any) {
+	d = map[string]any{"user_id": t.UserID}
+	maps.Copy(d, t.TokenWithScopes.Internal())
+	return d
+}
+
+func (t *TokenImpersonation) Data() (d map[string]any) {
+	d = map[string]any{"user_id": t.UserID}
+	maps.Copy(d, t.TokenWithScopes.Data())
+	return d
+}
+
+func (t *TokenImpersonation) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{"user_id": strconv.FormatInt(t.UserID, 10)}
+	maps.Copy(d, t.TokenWithScopes.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenImpersonation)(nil)
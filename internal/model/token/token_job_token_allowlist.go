@@ -0,0 +1,57 @@
+package token
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// TokenJobTokenAllowlist is a project or group added to the CI/CD job token allowlist of a project for as long as
+// the lease lasts. There is no token, the TokenID is the id of the allowed project or group and the ParentID the
+// id of the project.
+type TokenJobTokenAllowlist struct {
+	Token `json:",inline"`
+
+	ProjectID   int64  `json:"project_id"`
+	ProjectPath string `json:"project_path"`
+	AllowedID   int64  `json:"allowed_id"`
+	AllowedPath string `json:"allowed_path"`
+}
+
+func (t *TokenJobTokenAllowlist) Internal() (d map[string]any) {
+	d = map[string]any{
+		"project_id":   t.ProjectID,
+		"project_path": t.ProjectPath,
+		"allowed_id":   t.AllowedID,
+		"allowed_path": t.AllowedPath,
+	}
+	maps.Copy(d, t.Token.Internal())
+	return d
+}
+
+func (t *TokenJobTokenAllowlist) Data() (d map[string]any) {
+	d = map[string]any{
+		"project_id":   t.ProjectID,
+		"project_path": t.ProjectPath,
+		"allowed_id":   t.AllowedID,
+		"allowed_path": t.AllowedPath,
+	}
+	maps.Copy(d, t.Token.Data())
+	delete(d, "token")
+	delete(d, "token_sha1_hash")
+	return d
+}
+
+func (t *TokenJobTokenAllowlist) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{
+		"project_id":   strconv.FormatInt(t.ProjectID, 10),
+		"project_path": t.ProjectPath,
+		"allowed_id":   strconv.FormatInt(t.AllowedID, 10),
+		"allowed_path": t.AllowedPath,
+	}
+	maps.Copy(d, t.Token.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenJobTokenAllowlist)(nil)
@@ -0,0 +1,52 @@
+package token
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// TokenMembership is a membership of a user in a group or project that lasts as long as the lease.
+// There is no token, the TokenID is the id of the user and the ParentID the group or project.
+type TokenMembership struct {
+	Token `json:",inline"`
+
+	UserID      int64             `json:"user_id"`
+	Username    string            `json:"username"`
+	AccessLevel token.AccessLevel `json:"access_level"`
+}
+
+func (t *TokenMembership) Internal() (d map[string]any) {
+	d = map[string]any{
+		"user_id":      t.UserID,
+		"username":     t.Username,
+		"access_level": t.AccessLevel.String(),
+	}
+	maps.Copy(d, t.Token.Internal())
+	return d
+}
+
+func (t *TokenMembership) Data() (d map[string]any) {
+	d = map[string]any{
+		"user_id":      t.UserID,
+		"username":     t.Username,
+		"access_level": t.AccessLevel.String(),
+	}
+	maps.Copy(d, t.Token.Data())
+	delete(d, "token")
+	delete(d, "token_sha1_hash")
+	return d
+}
+
+func (t *TokenMembership) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{
+		"user_id":      strconv.FormatInt(t.UserID, 10),
+		"username":     t.Username,
+		"access_level": t.AccessLevel.String(),
+	}
+	maps.Copy(d, t.Token.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenMembership)(nil)
@@ -0,0 +1,60 @@
+package token
+
+import (
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// TokenOAuthApplication is the client id and secret of an OAuth application, the TokenID is the id of the
+// application and the token is the secret. Depending on the mode the application was created for the lease
+// or an existing application had its secret renewed. RenewedAt is when the request that renewed the secret started,
+// it tells which lease holds the current secret.
+type TokenOAuthApplication struct {
+	TokenWithScopes `json:",inline"`
+
+	ClientID     string    `json:"client_id"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Confidential bool      `json:"confidential"`
+	Mode         string    `json:"oauth_mode"`
+	RenewedAt    time.Time `json:"renewed_at"`
+}
+
+func (t *TokenOAuthApplication) Internal() (d map[string]any) {
+	d = map[string]any{
+		"client_id":     t.ClientID,
+		"redirect_uris": t.RedirectURIs,
+		"confidential":  t.Confidential,
+		"oauth_mode":    t.Mode,
+		"renewed_at":    t.RenewedAt,
+	}
+	maps.Copy(d, t.TokenWithScopes.Internal())
+	return d
+}
+
+func (t *TokenOAuthApplication) Data() (d map[string]any) {
+	d = map[string]any{
+		"client_id":     t.ClientID,
+		"client_secret": t.Token.Token,
+		"redirect_uris": t.RedirectURIs,
+		"confidential":  t.Confidential,
+		"oauth_mode":    t.Mode,
+	}
+	maps.Copy(d, t.TokenWithScopes.Data())
+	delete(d, "token")
+	return d
+}
+
+func (t *TokenOAuthApplication) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{
+		"client_id":     t.ClientID,
+		"redirect_uris": strings.Join(t.RedirectURIs, ","),
+		"oauth_mode":    t.Mode,
+	}
+	maps.Copy(d, t.TokenWithScopes.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenOAuthApplication)(nil)
@@ -0,0 +1,34 @@
+package token
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+type TokenProjectDeployKey struct {
+	TokenSSHKey `json:",inline"`
+
+	CanPush bool `json:"can_push"`
+}
+
+func (t *TokenProjectDeployKey) Internal() (d map[string]any) {
+	d = map[string]any{"can_push": t.CanPush}
+	maps.Copy(d, t.TokenSSHKey.Internal())
+	return d
+}
+
+func (t *TokenProjectDeployKey) Data() (d map[string]any) {
+	d = map[string]any{"can_push": t.CanPush}
+	maps.Copy(d, t.TokenSSHKey.Data())
+	return d
+}
+
+func (t *TokenProjectDeployKey) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{"can_push": strconv.FormatBool(t.CanPush)}
+	maps.Copy(d, t.TokenSSHKey.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenProjectDeployKey)(nil)
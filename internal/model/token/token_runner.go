@@ -0,0 +1,49 @@
+package token
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// TokenRunner is the authentication token of a runner that was created for the lease,
+// the TokenID is the id of the runner that is deleted when the lease is revoked.
+type TokenRunner struct {
+	Token `json:",inline"`
+
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	RunUntagged bool     `json:"run_untagged"`
+	Locked      bool     `json:"locked"`
+}
+
+func (t *TokenRunner) Internal() (d map[string]any) {
+	d = map[string]any{
+		"description":  t.Description,
+		"tags":         t.Tags,
+		"run_untagged": t.RunUntagged,
+		"locked":       t.Locked,
+	}
+	maps.Copy(d, t.Token.Internal())
+	return d
+}
+
+func (t *TokenRunner) Data() (d map[string]any) {
+	d = map[string]any{
+		"description":  t.Description,
+		"tags":         t.Tags,
+		"run_untagged": t.RunUntagged,
+		"locked":       t.Locked,
+	}
+	maps.Copy(d, t.Token.Data())
+	return d
+}
+
+func (t *TokenRunner) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{"tags": strings.Join(t.Tags, ",")}
+	maps.Copy(d, t.Token.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenRunner)(nil)
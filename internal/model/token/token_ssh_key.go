@@ -0,0 +1,37 @@
+package token
+
+import (
+	"maps"
+)
+
+// TokenSSHKey is an SSH key pair generated by the plugin, the public key is added to GitLab and the
+// private key is the token returned with the lease.
+type TokenSSHKey struct {
+	Token `json:",inline"`
+
+	PublicKey   string `json:"public_key"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+func (t *TokenSSHKey) Internal() (d map[string]any) {
+	d = map[string]any{"public_key": t.PublicKey, "fingerprint": t.Fingerprint}
+	maps.Copy(d, t.Token.Internal())
+	return d
+}
+
+func (t *TokenSSHKey) Data() (d map[string]any) {
+	d = map[string]any{
+		"public_key":  t.PublicKey,
+		"fingerprint": t.Fingerprint,
+		"private_key": t.Token.Token,
+	}
+	maps.Copy(d, t.Token.Data())
+	delete(d, "token")
+	return d
+}
+
+func (t *TokenSSHKey) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{"fingerprint": t.Fingerprint}
+	maps.Copy(d, t.Token.Event(m))
+	return d
+}
@@ -0,0 +1,36 @@
+package token
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// TokenUserSSHKey is an SSH key added to a user for the lease, the TokenID is the id of the key.
+type TokenUserSSHKey struct {
+	TokenSSHKey `json:",inline"`
+
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (t *TokenUserSSHKey) Internal() (d map[string]any) {
+	d = map[string]any{"user_id": t.UserID, "username": t.Username}
+	maps.Copy(d, t.TokenSSHKey.Internal())
+	return d
+}
+
+func (t *TokenUserSSHKey) Data() (d map[string]any) {
+	d = map[string]any{"user_id": t.UserID, "username": t.Username}
+	maps.Copy(d, t.TokenSSHKey.Data())
+	return d
+}
+
+func (t *TokenUserSSHKey) Event(m map[string]string) (d map[string]string) {
+	d = map[string]string{"user_id": strconv.FormatInt(t.UserID, 10), "username": t.Username}
+	maps.Copy(d, t.TokenSSHKey.Event(m))
+	return d
+}
+
+var _ token.Token = (*TokenUserSSHKey)(nil)
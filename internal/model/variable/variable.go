@@ -0,0 +1,83 @@
+package variable
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+)
+
+var _ model.Named = (*Injection)(nil)
+
+const (
+	// TargetProject writes the variable to the CI/CD variables of a project.
+	TargetProject = "project"
+	// TargetGroup writes the variable to the CI/CD variables of a group.
+	TargetGroup = "group"
+
+	// DefaultEnvironmentScope is the environment scope that matches all environments.
+	DefaultEnvironmentScope = "*"
+
+	// TypeEnvVar is the type of a variable that is exposed as an environment variable.
+	TypeEnvVar = "env_var"
+	// TypeFile is the type of a variable that is written to a file, with the path in the environment variable.
+	TypeFile = "file"
+)
+
+// Targets are the valid values for the ci_variable_target of a role.
+var Targets = []string{TargetProject, TargetGroup}
+
+// Variable is a CI/CD variable of a project or group.
+type Variable struct {
+	Key              string `json:"key"`
+	Value            string `json:"value"`
+	EnvironmentScope string `json:"environment_scope"`
+	Protected        bool   `json:"protected"`
+	Masked           bool   `json:"masked"`
+	Hidden           bool   `json:"hidden"`
+	Raw              bool   `json:"raw"`
+	VariableType     string `json:"variable_type,omitempty"`
+	Description      string `json:"description,omitempty"`
+}
+
+// Injection is the CI/CD variable a value issued for a lease was written to. It holds the variable that was
+// replaced, so it can be restored when the lease is revoked, and a hash of the written value, so a variable
+// that was changed by someone else in the meantime is left alone.
+type Injection struct {
+	Target           string    `json:"target"`
+	Path             string    `json:"path"`
+	Key              string    `json:"key"`
+	EnvironmentScope string    `json:"environment_scope"`
+	ValueHash        string    `json:"value_hash"`
+	Previous         *Variable `json:"previous,omitempty"`
+}
+
+// GetName returns the name the injection is stored under, it is the same for every injection into the variable.
+func (i Injection) GetName() string {
+	return Hash(strings.Join([]string{i.Target, i.Path, i.Key, i.EnvironmentScope}, "\n"))
+}
+
+// Hash returns the hash of the value that is stored with the injection.
+func Hash(value string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))
+}
+
+// Holds reports whether the value is the one that was written for the lease.
+func (i Injection) Holds(value string) bool {
+	return i.ValueHash == Hash(value)
+}
+
+// Decode converts the injection stored in the internal data of a lease back to an Injection.
+func Decode(data any) (i *Injection, err error) {
+	var raw []byte
+	if raw, err = json.Marshal(data); err != nil {
+		return nil, fmt.Errorf("decode ci variable: %w", err)
+	}
+	i = new(Injection)
+	if err = json.Unmarshal(raw, i); err != nil {
+		return nil, fmt.Errorf("decode ci variable: %w", err)
+	}
+	return i, nil
+}
@@ -0,0 +1,50 @@
+package variable_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
+)
+
+func TestInjection_Holds(t *testing.T) {
+	var i = variable.Injection{ValueHash: variable.Hash("glpat-test")}
+	assert.True(t, i.Holds("glpat-test"))
+	assert.False(t, i.Holds("glpat-other"))
+}
+
+func TestDecode(t *testing.T) {
+	var i = &variable.Injection{
+		Target:           variable.TargetProject,
+		Path:             "example/app",
+		Key:              "DEPLOY_TOKEN",
+		EnvironmentScope: "production",
+		ValueHash:        variable.Hash("glpat-test"),
+		Previous:         &variable.Variable{Key: "DEPLOY_TOKEN", Value: "old", EnvironmentScope: "production", Masked: true},
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		got, err := variable.Decode(i)
+		require.NoError(t, err)
+		assert.Equal(t, i, got)
+	})
+
+	t.Run("map", func(t *testing.T) {
+		got, err := variable.Decode(map[string]any{
+			"target": "group", "path": "example", "key": "TOKEN", "environment_scope": "*", "value_hash": "abc",
+			"previous": map[string]any{"key": "TOKEN", "value": "old", "variable_type": "file", "raw": true, "description": "deploy"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &variable.Injection{
+			Target: "group", Path: "example", Key: "TOKEN", EnvironmentScope: "*", ValueHash: "abc",
+			Previous: &variable.Variable{Key: "TOKEN", Value: "old", VariableType: variable.TypeFile, Raw: true, Description: "deploy"},
+		}, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := variable.Decode("not an injection")
+		require.Error(t, err)
+	})
+}
@@ -26,6 +26,9 @@ type mockConfigBackend struct {
 	client    gitlab.Client
 	clientErr error
 
+	// ClientGetter
+	cachedClient gitlab.Client
+
 	// ClientSetter -- track calls
 	setClientName string
 
@@ -56,6 +59,8 @@ func (m *mockConfigBackend) GetClientByName(_ context.Context, _ logical.Storage
 	return m.client, m.clientErr
 }
 
+func (m *mockConfigBackend) GetClient(_ string) gitlab.Client { return m.cachedClient }
+
 func (m *mockConfigBackend) SetClient(_ gitlab.Client, name string) {
 	m.setClientName = name
 }
@@ -95,6 +100,7 @@ type mockGitlabClient struct {
 	rotatedToken *modelToken.TokenConfig
 	rotatedOld   *modelToken.TokenConfig
 	rotateErr    error
+	saturation   gitlab.Saturation
 }
 
 func (m *mockGitlabClient) CurrentTokenInfo(_ context.Context) (*modelToken.TokenConfig, error) {
@@ -111,6 +117,8 @@ func (m *mockGitlabClient) RotateCurrentToken(_ context.Context) (*modelToken.To
 
 func (m *mockGitlabClient) Valid(_ context.Context) bool { return true }
 
+func (m *mockGitlabClient) Saturation() gitlab.Saturation { return m.saturation }
+
 // testConfig returns a realistic EntryConfig for test use.
 func testConfig() *modelConfig.EntryConfig {
 	now := time.Now()
@@ -73,6 +73,114 @@ var (
 				Name: "Auto Rotate Before",
 			},
 		},
+		"sweep_interval": {
+			Type:        framework.TypeDurationSecond,
+			Description: `How often the periodic function looks for tokens in GitLab that were created by the plugin but no longer have a lease or an issued token record. The value is 0 to disable the periodic sweep or at least 1 hour (3600 seconds).`,
+			Default:     0,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Sweep Interval",
+			},
+		},
+		"sweep_revoke": {
+			Type:        framework.TypeBool,
+			Default:     false,
+			Description: `Determines whether the periodic sweep revokes the orphaned tokens it finds, otherwise they are only reported.`,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Sweep Revoke",
+			},
+		},
+		"ca_cert": {
+			Type:        framework.TypeString,
+			Description: `PEM encoded CA bundle used to verify the TLS certificate of GitLab. When set, it replaces the system CAs, so only GitLab instances with a certificate issued by one of these CAs are trusted.`,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "CA Certificate",
+			},
+		},
+		"client_cert": {
+			Type:        framework.TypeString,
+			Description: `PEM encoded client certificate presented to GitLab for mutual TLS, it requires client_key.`,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Client Certificate",
+			},
+		},
+		"client_key": {
+			Type:        framework.TypeString,
+			Description: `PEM encoded private key of the client certificate.`,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name:      "Client Key",
+				Sensitive: true,
+			},
+		},
+		"proxy_url": {
+			Type:        framework.TypeString,
+			Description: `URL of the http, https or socks5 proxy the requests to GitLab are routed through. When not set, the proxy is taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables of Vault.`,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Proxy URL",
+			},
+		},
+		"request_timeout": {
+			Type:        framework.TypeDurationSecond,
+			Description: `The time limit for a request to GitLab, including the connection and reading the response. The value is 0 for no limit or at most 10 minutes (600 seconds).`,
+			Default:     0,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Request Timeout",
+			},
+		},
+		"headers": {
+			Type:        framework.TypeKVPairs,
+			Description: `Headers added to every request to GitLab, for example those required by a reverse proxy in front of it. The headers used for authentication can't be set.`,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Headers",
+			},
+		},
+		"retry_max_attempts": {
+			Type:        framework.TypeInt,
+			Description: `How many times a request to GitLab is attempted when it fails with a connection error, a 429 or a 5xx response. Only lookups and revokes are retried, as repeating them has no side effects. The value is 1 to disable retries or at most 10, 0 uses the default of 4 attempts.`,
+			Default:     0,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Retry Max Attempts",
+			},
+		},
+		"retry_wait_min": {
+			Type:        framework.TypeDurationSecond,
+			Description: `The wait before the first retry, it doubles with every following retry and a random jitter is added. A Retry-After or RateLimit-Reset header from GitLab takes precedence, up to retry_wait_max. The value is 0 for the default of 1 second.`,
+			Default:     0,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Retry Wait Min",
+			},
+		},
+		"retry_wait_max": {
+			Type:        framework.TypeDurationSecond,
+			Description: `The longest wait between two retries, including the waits GitLab asks for, at least retry_wait_min and at most 5 minutes (300 seconds). The value is 0 for the default of 30 seconds.`,
+			Default:     0,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Retry Wait Max",
+			},
+		},
+		"requests_per_second": {
+			Type:        framework.TypeFloat,
+			Description: `How many requests per second can be made to GitLab with this config, including the retries. The requests above the rate wait for their turn. The value is 0 to disable the rate limit.`,
+			Default:     0.0,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Requests Per Second",
+			},
+		},
+		"burst": {
+			Type:        framework.TypeInt,
+			Description: `How many requests can be made at once above requests_per_second. The value is 0 for the requests of one second.`,
+			Default:     0,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Burst",
+			},
+		},
+		"max_concurrent_requests": {
+			Type:        framework.TypeInt,
+			Description: `How many requests to GitLab can be in flight at the same time with this config, the others wait for a free slot. The value is 0 for no limit or at most 1000.`,
+			Default:     0,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Max Concurrent Requests",
+			},
+		},
 		"config_name": {
 			Type:        framework.TypeString,
 			Description: "Config name",
@@ -89,6 +197,7 @@ type configBackend interface {
 	backend.Logging
 	backend.FlagsProvider
 	backend.ClientReader
+	backend.ClientGetter
 	backend.ClientSetter
 	backend.ClientDeleter
 	backend.ConfigStore
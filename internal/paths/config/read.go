@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
 )
 
 func (p *Provider) pathConfigRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
@@ -23,6 +24,15 @@ func (p *Provider) pathConfigRead(ctx context.Context, req *logical.Request, dat
 	}
 
 	lrd := config.LogicalResponseData(p.b.Flags().ShowConfigToken)
+
+	// the limits are shared by the requests through the cached client, a config without one has nothing in flight
+	var saturation gitlab.Saturation
+	if client := p.b.GetClient(name); client != nil {
+		saturation = client.Saturation()
+	} else {
+		saturation.RequestsPerSecond, _, saturation.MaxConcurrent = config.RequestLimits()
+	}
+	lrd["saturation"] = saturation.LogicalResponseData()
 	p.b.Logger().Debug("Reading configuration info", "info", lrd)
 	return &logical.Response{Data: lrd}, nil
 }
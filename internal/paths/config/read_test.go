@@ -11,6 +11,7 @@ import (
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/flags"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
 	pathConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/config"
 )
 
@@ -80,4 +81,41 @@ func TestPathConfigRead(t *testing.T) {
 		assert.Equal(t, cfg.Token, resp.Data["token"])
 		assert.NotEmpty(t, resp.Data["token_sha1_hash"])
 	})
+	t.Run("saturation of the cached client", func(t *testing.T) {
+		mb := &mockConfigBackend{
+			config:       testConfig(),
+			cachedClient: &mockGitlabClient{saturation: gitlab.Saturation{InFlight: 3, Waiting: 2, MaxConcurrent: 3, RequestsPerSecond: 5, Tokens: 0.5}},
+		}
+		p := pathConfig.New(mb)
+		readOp := p.Paths()[0].Operations[logical.ReadOperation].Handler()
+
+		resp, err := readOp(t.Context(), &logical.Request{Storage: &logical.InmemStorage{}}, newFieldData())
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, map[string]any{
+			"in_flight":               int64(3),
+			"waiting":                 int64(2),
+			"max_concurrent_requests": 3,
+			"requests_per_second":     float64(5),
+			"available_tokens":        0.5,
+		}, resp.Data["saturation"])
+	})
+
+	t.Run("saturation without a cached client", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.MaxConcurrent = 10
+		mb := &mockConfigBackend{config: cfg}
+		p := pathConfig.New(mb)
+		readOp := p.Paths()[0].Operations[logical.ReadOperation].Handler()
+
+		resp, err := readOp(t.Context(), &logical.Request{Storage: &logical.InmemStorage{}}, newFieldData())
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, map[string]any{
+			"in_flight":               int64(0),
+			"waiting":                 int64(0),
+			"max_concurrent_requests": 10,
+			"requests_per_second":     float64(0),
+		}, resp.Data["saturation"])
+	})
 }
@@ -0,0 +1,66 @@
+package issued_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	modelIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	pathIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/issued"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mockIssuedBackend is a hand-written mock satisfying the issuedBackend interface,
+// issued tokens are persisted in the request storage.
+type mockIssuedBackend struct {
+	issuedErr error
+}
+
+func (m *mockIssuedBackend) Logger() hclog.Logger { return hclog.NewNullLogger() }
+func (m *mockIssuedBackend) GetIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) (*modelIssued.Token, error) {
+	if m.issuedErr != nil {
+		return nil, m.issuedErr
+	}
+	return model.Get[modelIssued.Token](ctx, s, fmt.Sprintf("%s/%s/%s/%d", backend.PathIssuedStorage, configName, roleName, tokenId))
+}
+func (m *mockIssuedBackend) SaveIssuedToken(ctx context.Context, s logical.Storage, t *modelIssued.Token) error {
+	return model.Save(ctx, s, fmt.Sprintf("%s/%s/%s", backend.PathIssuedStorage, t.ConfigName, t.RoleName), t)
+}
+func (m *mockIssuedBackend) DeleteIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s/%s/%d", backend.PathIssuedStorage, configName, roleName, tokenId))
+}
+
+func issuedToken(configName, roleName string, tokenId int64) *modelIssued.Token {
+	return &modelIssued.Token{
+		ConfigName: configName,
+		RoleName:   roleName,
+		TokenID:    tokenId,
+		TokenType:  tk.TypeProject,
+		Name:       "token",
+		Path:       "group/project",
+		IssuedAt:   testNow,
+		ExpiresAt:  testNow.Add(time.Hour),
+		Metadata:   map[string]string{"scopes": "api"},
+	}
+}
+
+func handler(mb *mockIssuedBackend, idx int, op logical.Operation) framework.OperationFunc {
+	return pathIssued.New(mb).Paths()[idx].Operations[op].Handler()
+}
+
+func fieldData(mb *mockIssuedBackend, idx int, raw map[string]interface{}) *framework.FieldData {
+	return &framework.FieldData{Raw: raw, Schema: pathIssued.New(mb).Paths()[idx].Fields}
+}
+
+// newRequest creates a minimal logical.Request with in-memory storage.
+func newRequest() *logical.Request {
+	return &logical.Request{Storage: &logical.InmemStorage{}}
+}
@@ -0,0 +1,95 @@
+package issued
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	modelIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+)
+
+func (p *Provider) pathIssuedList(ctx context.Context, req *logical.Request, data *framework.FieldData) (l *logical.Response, err error) {
+	var roleName = data.Get("role_name").(string)
+	var configs, keys []string
+	var keyInfo = make(map[string]any)
+	defer func() {
+		p.b.Logger().Debug("Issued tokens", "role_name", roleName, "tokens", keys, "err", err)
+	}()
+
+	if configs, err = req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathIssuedStorage)); err != nil {
+		return logical.ErrorResponse("Error listing issued tokens"), err
+	}
+
+	for _, config := range configs {
+		var ids []string
+		if ids, err = req.Storage.List(ctx, fmt.Sprintf("%s/%s%s/", backend.PathIssuedStorage, config, roleName)); err != nil {
+			return logical.ErrorResponse("Error listing issued tokens"), err
+		}
+
+		for _, id := range ids {
+			var tokenId int64
+			if tokenId, err = strconv.ParseInt(id, 10, 64); err != nil {
+				return logical.ErrorResponse("Error listing issued tokens"), err
+			}
+
+			var entry *modelIssued.Token
+			if entry, err = p.b.GetIssuedToken(ctx, req.Storage, strings.TrimSuffix(config, "/"), roleName, tokenId); err != nil {
+				return logical.ErrorResponse("Error listing issued tokens"), err
+			}
+
+			if entry == nil {
+				continue
+			}
+
+			keys = append(keys, id)
+			keyInfo[id] = entry.LogicalResponseData()
+		}
+	}
+
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
+}
+
+func (p *Provider) pathListIssued() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathListIssuedHelpSyn),
+		HelpDescription: strings.TrimSpace(pathListIssuedHelpDesc),
+		Pattern:         fmt.Sprintf("%s/%s/?$", backend.PathIssuedStorage, framework.GenericNameRegex("role_name")),
+		Fields: map[string]*framework.FieldSchema{
+			"role_name": FieldSchemaIssued["role_name"],
+		},
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "issued-tokens",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: p.pathIssuedList,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "list",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+						Fields: map[string]*framework.FieldSchema{
+							"token_id": FieldSchemaIssued["token_id"],
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+const (
+	pathListIssuedHelpSyn  = `Lists the outstanding tokens issued for a role`
+	pathListIssuedHelpDesc = `
+This path allows you to list all tokens that were issued for a role and have not been revoked yet. The key info of 
+each entry contains the metadata of the token, the token value itself is never stored.`
+)
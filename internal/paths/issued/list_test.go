@@ -0,0 +1,49 @@
+package issued_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathIssuedList(t *testing.T) {
+	mb := &mockIssuedBackend{}
+	req := newRequest()
+	raw := map[string]interface{}{"role_name": "role"}
+
+	resp, err := handler(mb, 0, logical.ListOperation)(t.Context(), req, fieldData(mb, 0, raw))
+	require.NoError(t, err)
+	assert.Empty(t, resp.Data["keys"])
+
+	require.NoError(t, mb.SaveIssuedToken(t.Context(), req.Storage, issuedToken("default", "role", 1)))
+	require.NoError(t, mb.SaveIssuedToken(t.Context(), req.Storage, issuedToken("other", "role", 2)))
+	require.NoError(t, mb.SaveIssuedToken(t.Context(), req.Storage, issuedToken("default", "another", 3)))
+
+	resp, err = handler(mb, 0, logical.ListOperation)(t.Context(), req, fieldData(mb, 0, raw))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1", "2"}, resp.Data["keys"])
+
+	keyInfo := resp.Data["key_info"].(map[string]any)
+	require.Len(t, keyInfo, 2)
+	assert.Equal(t, "other", keyInfo["2"].(map[string]any)["config_name"])
+	assert.NotContains(t, keyInfo["1"], "token")
+
+	require.NoError(t, mb.DeleteIssuedToken(t.Context(), req.Storage, "default", "role", 1))
+	resp, err = handler(mb, 0, logical.ListOperation)(t.Context(), req, fieldData(mb, 0, raw))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, resp.Data["keys"])
+}
+
+func TestPathIssuedList_Error(t *testing.T) {
+	mb := &mockIssuedBackend{}
+	req := newRequest()
+	require.NoError(t, mb.SaveIssuedToken(t.Context(), req.Storage, issuedToken("default", "role", 1)))
+
+	mb.issuedErr = errors.New("storage error")
+	resp, err := handler(mb, 0, logical.ListOperation)(t.Context(), req, fieldData(mb, 0, map[string]interface{}{"role_name": "role"}))
+	require.ErrorContains(t, err, "storage error")
+	require.True(t, resp.IsError())
+}
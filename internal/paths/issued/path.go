@@ -0,0 +1,54 @@
+package issued
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+)
+
+var (
+	FieldSchemaIssued = map[string]*framework.FieldSchema{
+		"role_name": {
+			Type:        framework.TypeString,
+			Description: "Role name",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Role Name",
+			},
+		},
+		"token_id": {
+			Type:        framework.TypeInt64,
+			Description: "The ID of the issued token in GitLab",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Token ID",
+			},
+		},
+	}
+)
+
+// issuedBackend defines the narrow interface this provider needs.
+type issuedBackend interface {
+	backend.Logging
+	backend.IssuedTokenStore
+}
+
+// Provider implements backend.PathProvider for the issued token inventory paths.
+type Provider struct {
+	b issuedBackend
+}
+
+func (p *Provider) Name() string { return "issued" }
+
+// New creates a new issued token path provider.
+func New(b issuedBackend) *Provider {
+	return &Provider{b: b}
+}
+
+// Paths returns all issued token related framework paths.
+func (p *Provider) Paths() []*framework.Path {
+	return []*framework.Path{
+		p.pathListIssued(),
+		p.pathIssued(),
+	}
+}
@@ -0,0 +1,25 @@
+package issued_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pathIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/issued"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := pathIssued.New(&mockIssuedBackend{})
+	assert.Equal(t, "issued", p.Name())
+}
+
+func TestProvider_Paths(t *testing.T) {
+	p := pathIssued.New(&mockIssuedBackend{})
+	paths := p.Paths()
+	require.Len(t, paths, 2)
+
+	assert.NotNil(t, paths[0].Operations[logical.ListOperation])
+	assert.NotNil(t, paths[1].Operations[logical.ReadOperation])
+}
@@ -0,0 +1,73 @@
+package issued
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+)
+
+func (p *Provider) pathIssuedRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	var roleName = data.Get("role_name").(string)
+	var tokenId = data.Get("token_id").(int64)
+
+	// the role name is unique across configs, so the record can be found without knowing the config
+	configs, err := req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathIssuedStorage))
+	if err != nil {
+		return logical.ErrorResponse("error reading issued token"), err
+	}
+
+	for _, config := range configs {
+		entry, err := p.b.GetIssuedToken(ctx, req.Storage, strings.TrimSuffix(config, "/"), roleName, tokenId)
+		if err != nil {
+			return logical.ErrorResponse("error reading issued token"), err
+		}
+
+		if entry != nil {
+			p.b.Logger().Debug("Issued token read", "role_name", roleName, "token_id", tokenId)
+			return &logical.Response{Data: entry.LogicalResponseData()}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (p *Provider) pathIssued() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathIssuedHelpSyn),
+		HelpDescription: strings.TrimSpace(pathIssuedHelpDesc),
+		Pattern:         fmt.Sprintf("%s/%s/(?P<token_id>\\d+)$", backend.PathIssuedStorage, framework.GenericNameRegex("role_name")),
+		Fields:          FieldSchemaIssued,
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "issued-token",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: p.pathIssuedRead,
+				Summary:  "Reads the metadata of an issued token",
+				Responses: map[int][]framework.Response{
+					http.StatusNotFound: {{
+						Description: http.StatusText(http.StatusNotFound),
+					}},
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+					}},
+				},
+			},
+		},
+	}
+}
+
+const (
+	pathIssuedHelpSyn  = `Reads the metadata of an issued token`
+	pathIssuedHelpDesc = `
+This path allows you to read the metadata of a token that was issued for a role and has not been revoked yet. 
+The token value itself is never stored.`
+)
@@ -0,0 +1,43 @@
+package issued_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathIssuedRead(t *testing.T) {
+	mb := &mockIssuedBackend{}
+	req := newRequest()
+
+	resp, err := handler(mb, 1, logical.ReadOperation)(t.Context(), req, fieldData(mb, 1, map[string]interface{}{"role_name": "role", "token_id": 2}))
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	require.NoError(t, mb.SaveIssuedToken(t.Context(), req.Storage, issuedToken("default", "role", 1)))
+	require.NoError(t, mb.SaveIssuedToken(t.Context(), req.Storage, issuedToken("other", "role", 2)))
+
+	resp, err = handler(mb, 1, logical.ReadOperation)(t.Context(), req, fieldData(mb, 1, map[string]interface{}{"role_name": "role", "token_id": 2}))
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, "other", resp.Data["config_name"])
+	assert.EqualValues(t, 2, resp.Data["token_id"])
+	assert.Equal(t, testNow.Format(time.RFC3339), resp.Data["issued_at"])
+	assert.Equal(t, map[string]string{"scopes": "api"}, resp.Data["metadata"])
+	assert.NotContains(t, resp.Data, "token")
+}
+
+func TestPathIssuedRead_Error(t *testing.T) {
+	mb := &mockIssuedBackend{}
+	req := newRequest()
+	require.NoError(t, mb.SaveIssuedToken(t.Context(), req.Storage, issuedToken("default", "role", 1)))
+
+	mb.issuedErr = errors.New("storage error")
+	resp, err := handler(mb, 1, logical.ReadOperation)(t.Context(), req, fieldData(mb, 1, map[string]interface{}{"role_name": "role", "token_id": 1}))
+	require.ErrorContains(t, err, "storage error")
+	require.True(t, resp.IsError())
+}
@@ -0,0 +1,5 @@
+package revocation
+
+import "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+
+var eventRevoke = event.MustEventType("token-revoke")
@@ -0,0 +1,103 @@
+package revocation_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	modelIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	modelRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	pathRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/revocation"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mockRevocationBackend is a hand-written mock satisfying the revocationBackend interface,
+// deferred revocations and issued tokens are persisted in the request storage.
+type mockRevocationBackend struct {
+	client    gitlab.Client
+	clientErr error
+	events    []event.EventType
+	metadata  []map[string]string
+}
+
+func (m *mockRevocationBackend) Logger() hclog.Logger { return hclog.NewNullLogger() }
+func (m *mockRevocationBackend) GetClientByName(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+	return m.client, m.clientErr
+}
+func (m *mockRevocationBackend) GetIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) (*modelIssued.Token, error) {
+	return model.Get[modelIssued.Token](ctx, s, fmt.Sprintf("%s/%s/%s/%d", backend.PathIssuedStorage, configName, roleName, tokenId))
+}
+func (m *mockRevocationBackend) SaveIssuedToken(ctx context.Context, s logical.Storage, t *modelIssued.Token) error {
+	return model.Save(ctx, s, fmt.Sprintf("%s/%s/%s", backend.PathIssuedStorage, t.ConfigName, t.RoleName), t)
+}
+func (m *mockRevocationBackend) DeleteIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s/%s/%d", backend.PathIssuedStorage, configName, roleName, tokenId))
+}
+func (m *mockRevocationBackend) GetDeferredRevocation(ctx context.Context, s logical.Storage, name string) (*modelRevocation.Entry, error) {
+	return model.Get[modelRevocation.Entry](ctx, s, fmt.Sprintf("%s/%s", backend.PathRevocationQueueStorage, name))
+}
+func (m *mockRevocationBackend) SaveDeferredRevocation(ctx context.Context, s logical.Storage, e *modelRevocation.Entry) error {
+	return model.Save(ctx, s, backend.PathRevocationQueueStorage, e)
+}
+func (m *mockRevocationBackend) DeleteDeferredRevocation(ctx context.Context, s logical.Storage, name string) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s", backend.PathRevocationQueueStorage, name))
+}
+func (m *mockRevocationBackend) SendEvent(_ context.Context, eventType event.EventType, metadata map[string]string) error {
+	m.events = append(m.events, eventType)
+	m.metadata = append(m.metadata, metadata)
+	return nil
+}
+
+// mockGitlabClient implements the revoke method used by the tests.
+type mockGitlabClient struct {
+	gitlab.Client
+	revokeErr error
+	revoked   []int64
+}
+
+func (m *mockGitlabClient) RevokeProjectAccessToken(_ context.Context, tokenId int64, _ string) error {
+	if m.revokeErr != nil {
+		return m.revokeErr
+	}
+	m.revoked = append(m.revoked, tokenId)
+	return nil
+}
+
+func deferredEntry(tokenId int64) *modelRevocation.Entry {
+	return &modelRevocation.Entry{
+		LeaseID:       "gitlab/token/role/lease",
+		ConfigName:    "default",
+		RoleName:      "role",
+		TokenID:       tokenId,
+		TokenType:     tk.TypeProject,
+		ParentID:      "group/project",
+		Path:          "group/project",
+		Name:          "token",
+		Attempts:      1,
+		CreatedAt:     testNow,
+		NextAttemptAt: testNow,
+	}
+}
+
+func handler(mb *mockRevocationBackend, idx int, op logical.Operation) framework.OperationFunc {
+	return pathRevocation.New(mb).Paths()[idx].Operations[op].Handler()
+}
+
+func fieldData(mb *mockRevocationBackend, idx int, raw map[string]interface{}) *framework.FieldData {
+	return &framework.FieldData{Raw: raw, Schema: pathRevocation.New(mb).Paths()[idx].Fields}
+}
+
+// newRequest creates a minimal logical.Request with in-memory storage.
+func newRequest() *logical.Request {
+	return &logical.Request{Storage: &logical.InmemStorage{}}
+}
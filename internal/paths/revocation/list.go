@@ -0,0 +1,76 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	modelRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+)
+
+func (p *Provider) pathRevocationQueueList(ctx context.Context, req *logical.Request, data *framework.FieldData) (l *logical.Response, err error) {
+	var names, keys []string
+	var keyInfo = make(map[string]any)
+	defer func() {
+		p.b.Logger().Debug("Deferred revocations", "keys", keys, "err", err)
+	}()
+
+	if names, err = req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathRevocationQueueStorage)); err != nil {
+		return logical.ErrorResponse("Error listing deferred revocations"), err
+	}
+
+	for _, name := range names {
+		var entry *modelRevocation.Entry
+		if entry, err = p.b.GetDeferredRevocation(ctx, req.Storage, name); err != nil {
+			return logical.ErrorResponse("Error listing deferred revocations"), err
+		}
+
+		if entry == nil {
+			continue
+		}
+
+		keys = append(keys, name)
+		keyInfo[name] = entry.LogicalResponseData()
+	}
+
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
+}
+
+func (p *Provider) pathListRevocationQueue() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathListRevocationQueueHelpSyn),
+		HelpDescription: strings.TrimSpace(pathListRevocationQueueHelpDesc),
+		Pattern:         fmt.Sprintf("%s/?$", PathRevocationQueue),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "revocation-queue",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: p.pathRevocationQueueList,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "list",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+					}},
+				},
+			},
+		},
+	}
+}
+
+const (
+	pathListRevocationQueueHelpSyn  = `Lists the revocations waiting to be retried`
+	pathListRevocationQueueHelpDesc = `
+This path lists the tokens whose lease has been revoked while GitLab was unreachable. The revocation in GitLab is 
+retried in the background with an exponential backoff until it succeeds. The key info of each entry contains the 
+number of attempts, the last error and the time of the next attempt.`
+)
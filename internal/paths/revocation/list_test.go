@@ -0,0 +1,30 @@
+package revocation_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathRevocationQueueList(t *testing.T) {
+	mb := &mockRevocationBackend{}
+	req := newRequest()
+
+	resp, err := handler(mb, 0, logical.ListOperation)(t.Context(), req, fieldData(mb, 0, map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, resp.Data["keys"])
+
+	entry := deferredEntry(42)
+	entry.Token = "glpat-secret"
+	require.NoError(t, mb.SaveDeferredRevocation(t.Context(), req.Storage, entry))
+
+	resp, err = handler(mb, 0, logical.ListOperation)(t.Context(), req, fieldData(mb, 0, map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default-project-42"}, resp.Data["keys"])
+
+	info := resp.Data["key_info"].(map[string]any)["default-project-42"].(map[string]any)
+	assert.Equal(t, 1, info["attempts"])
+	assert.NotContains(t, info, "token")
+}
@@ -0,0 +1,40 @@
+package revocation
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+)
+
+const (
+	// PathRevocationQueue is the path used to inspect the revocations that are waiting to be retried.
+	PathRevocationQueue = "revocation-queue"
+)
+
+// revocationBackend defines the narrow interface this provider needs.
+type revocationBackend interface {
+	backend.Logging
+	backend.ClientReader
+	backend.IssuedTokenStore
+	backend.RevocationQueueStore
+	backend.EventSender
+}
+
+// Provider implements backend.PathProvider and backend.PeriodicHandler for the queue of deferred revocations.
+type Provider struct {
+	b revocationBackend
+}
+
+func (p *Provider) Name() string { return "revocation" }
+
+// New creates a new revocation queue path provider.
+func New(b revocationBackend) *Provider {
+	return &Provider{b: b}
+}
+
+// Paths returns all revocation queue related framework paths.
+func (p *Provider) Paths() []*framework.Path {
+	return []*framework.Path{
+		p.pathListRevocationQueue(),
+	}
+}
@@ -0,0 +1,30 @@
+package revocation_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	pathRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/revocation"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := pathRevocation.New(&mockRevocationBackend{})
+	assert.Equal(t, "revocation", p.Name())
+}
+
+func TestProvider_Paths(t *testing.T) {
+	p := pathRevocation.New(&mockRevocationBackend{})
+	paths := p.Paths()
+	require.Len(t, paths, 1)
+	assert.NotNil(t, paths[0].Operations[logical.ListOperation])
+}
+
+func TestProvider_PeriodicHandler(t *testing.T) {
+	var p any = pathRevocation.New(&mockRevocationBackend{})
+	_, ok := p.(backend.PeriodicHandler)
+	assert.True(t, ok)
+}
@@ -0,0 +1,82 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// PeriodicFunc implements backend.PeriodicHandler.
+// It retries the deferred revocations that are due, failures are rescheduled with an exponential backoff.
+func (p *Provider) PeriodicFunc(ctx context.Context, req *logical.Request) (err error) {
+	var names []string
+	names, err = req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathRevocationQueueStorage))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		err = errors.Join(err, p.retryRevocation(ctx, req.Storage, name))
+	}
+
+	return err
+}
+
+func (p *Provider) retryRevocation(ctx context.Context, s logical.Storage, name string) (err error) {
+	var now = utils.TimeFromContext(ctx).UTC()
+	var entry *modelRevocation.Entry
+	if entry, err = p.b.GetDeferredRevocation(ctx, s, name); err != nil || entry == nil {
+		return err
+	}
+
+	if !entry.Due(now) {
+		return nil
+	}
+
+	var client gitlab.Client
+	if client, err = p.b.GetClientByName(ctx, s, entry.ConfigName); err == nil {
+		err = secret.RevokeToken(ctx, client, entry)
+	}
+
+	if err != nil && !errors.Is(err, errs.ErrAccessTokenNotFound) {
+		// GitLab being down is not an error of the periodic function, the attempt is just rescheduled
+		entry.Failed(now, err)
+		p.b.Logger().Warn("Deferred revocation failed", "name", name, "attempts", entry.Attempts, "next_attempt_at", entry.NextAttemptAt, "err", err)
+		return p.b.SaveDeferredRevocation(ctx, s, entry)
+	}
+
+	if err = p.b.DeleteIssuedToken(ctx, s, entry.ConfigName, entry.RoleName, entry.TokenID); err != nil {
+		return err
+	}
+
+	if err = p.b.DeleteDeferredRevocation(ctx, s, name); err != nil {
+		return err
+	}
+
+	p.b.Logger().Debug("Deferred revocation completed", "name", name, "attempts", entry.Attempts+1)
+
+	_ = p.b.SendEvent(ctx, eventRevoke, map[string]string{
+		"lease_id":    entry.LeaseID,
+		"path":        entry.Path,
+		"name":        entry.Name,
+		"token_id":    strconv.FormatInt(entry.TokenID, 10),
+		"token_type":  entry.TokenType.String(),
+		"config_name": entry.ConfigName,
+		"deferred":    "true",
+		"attempts":    strconv.Itoa(entry.Attempts + 1),
+		"created_at":  entry.CreatedAt.Format(time.RFC3339),
+	})
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package revocation_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	g "gitlab.com/gitlab-org/api/client-go/v2"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	modelIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	pathRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/revocation"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func TestPeriodicFunc(t *testing.T) {
+	var ctx = utils.WithStaticTime(t.Context(), testNow)
+
+	t.Run("revoked", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockRevocationBackend{client: client}
+		req := newRequest()
+
+		require.NoError(t, mb.SaveDeferredRevocation(ctx, req.Storage, deferredEntry(42)))
+		require.NoError(t, mb.SaveIssuedToken(ctx, req.Storage, &modelIssued.Token{ConfigName: "default", RoleName: "role", TokenID: 42}))
+
+		require.NoError(t, pathRevocation.New(mb).PeriodicFunc(ctx, req))
+		assert.Equal(t, []int64{42}, client.revoked)
+
+		entry, err := mb.GetDeferredRevocation(ctx, req.Storage, "default-project-42")
+		require.NoError(t, err)
+		assert.Nil(t, entry)
+
+		issued, err := mb.GetIssuedToken(ctx, req.Storage, "default", "role", 42)
+		require.NoError(t, err)
+		assert.Nil(t, issued)
+
+		require.Len(t, mb.events, 1)
+		assert.Equal(t, "token-revoke", mb.events[0].String())
+		assert.Equal(t, "true", mb.metadata[0]["deferred"])
+		assert.Equal(t, "2", mb.metadata[0]["attempts"])
+	})
+
+	t.Run("already gone", func(t *testing.T) {
+		mb := &mockRevocationBackend{client: &mockGitlabClient{revokeErr: fmt.Errorf("project: %w", errs.ErrAccessTokenNotFound)}}
+		req := newRequest()
+
+		require.NoError(t, mb.SaveDeferredRevocation(ctx, req.Storage, deferredEntry(42)))
+		require.NoError(t, pathRevocation.New(mb).PeriodicFunc(ctx, req))
+
+		entry, err := mb.GetDeferredRevocation(ctx, req.Storage, "default-project-42")
+		require.NoError(t, err)
+		assert.Nil(t, entry)
+	})
+
+	t.Run("not due", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockRevocationBackend{client: client}
+		req := newRequest()
+
+		entry := deferredEntry(42)
+		entry.NextAttemptAt = testNow.Add(time.Minute)
+		require.NoError(t, mb.SaveDeferredRevocation(ctx, req.Storage, entry))
+		require.NoError(t, pathRevocation.New(mb).PeriodicFunc(ctx, req))
+		assert.Empty(t, client.revoked)
+		assert.Empty(t, mb.events)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		mb := &mockRevocationBackend{client: &mockGitlabClient{revokeErr: &g.ErrorResponse{StatusCode: http.StatusBadGateway}}}
+		req := newRequest()
+
+		require.NoError(t, mb.SaveDeferredRevocation(ctx, req.Storage, deferredEntry(42)))
+		require.NoError(t, pathRevocation.New(mb).PeriodicFunc(ctx, req))
+
+		entry, err := mb.GetDeferredRevocation(ctx, req.Storage, "default-project-42")
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		assert.Equal(t, 2, entry.Attempts)
+		assert.WithinRange(t, entry.NextAttemptAt, testNow.Add(time.Minute), testNow.Add(2*time.Minute))
+		assert.Contains(t, entry.LastError, "502")
+		assert.Empty(t, mb.events)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mb := &mockRevocationBackend{clientErr: errors.New("config not found")}
+		req := newRequest()
+
+		require.NoError(t, mb.SaveDeferredRevocation(ctx, req.Storage, deferredEntry(42)))
+		require.NoError(t, pathRevocation.New(mb).PeriodicFunc(ctx, req))
+
+		entry, err := mb.GetDeferredRevocation(ctx, req.Storage, "default-project-42")
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		assert.Equal(t, "config not found", entry.LastError)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
@@ -66,6 +67,14 @@ var (
 				Name: "Token TTL",
 			},
 		},
+		"max_ttl": {
+			Type:        framework.TypeDurationSecond,
+			Description: "The maximum lifetime of the token, the lease can be renewed in ttl steps up to this value. Defaults to ttl.",
+			Required:    false,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Token Max TTL",
+			},
+		},
 		"access_level": {
 			Type:        framework.TypeString,
 			Description: "access level of access token (only required for Group and Project access tokens)",
@@ -102,6 +111,190 @@ var (
 				Name: "Configuration.",
 			},
 		},
+		"runner_tags": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "List of tags of the runner (only used for runner token types)",
+			Required:    false,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Runner Tags",
+			},
+		},
+		"runner_run_untagged": {
+			Type:        framework.TypeBool,
+			Default:     false,
+			Required:    false,
+			Description: "Should the runner pick up jobs without tags (only used for runner token types)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Runner Run Untagged",
+			},
+		},
+		"runner_locked": {
+			Type:        framework.TypeBool,
+			Default:     false,
+			Required:    false,
+			Description: "Should the runner be locked to the project it was created for (only used for runner token types)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Runner Locked",
+			},
+		},
+		"runner_description": {
+			Type:        framework.TypeString,
+			Required:    false,
+			Description: "The description of the runner, defaults to the token name (only used for runner token types)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Runner Description",
+			},
+		},
+		"cluster_agent_create": {
+			Type:        framework.TypeBool,
+			Default:     false,
+			Required:    false,
+			Description: "Register the agent with the project if it doesn't exist yet (only used for the cluster-agent token type)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Cluster Agent Create",
+			},
+		},
+		"can_push": {
+			Type:        framework.TypeBool,
+			Default:     false,
+			Required:    false,
+			Description: "Allow the deploy key to push to the repository (only used for the project-deploy-key token type)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Can Push",
+			},
+		},
+		"oauth_mode": {
+			Type:          framework.TypeString,
+			Default:       token.OAuthApplicationModeCreate,
+			Required:      false,
+			AllowedValues: utils.ToAny(token.OAuthApplicationModes...),
+			Description:   "Create a new OAuth application for every lease, or rotate the secret of the existing application in the path (only used for the oauth-application token type)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "OAuth Mode",
+			},
+		},
+		"oauth_redirect_uris": {
+			Type:        framework.TypeCommaStringSlice,
+			Required:    false,
+			Description: "List of redirect URIs of the created OAuth application (only used for the oauth-application token type)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "OAuth Redirect URIs",
+			},
+		},
+		"oauth_confidential": {
+			Type:        framework.TypeBool,
+			Default:     true,
+			Required:    false,
+			Description: "Is the created OAuth application used where the client secret can be kept confidential (only used for the oauth-application token type)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "OAuth Confidential",
+			},
+		},
+		"entity_alias_mount_accessor": {
+			Type:        framework.TypeString,
+			Required:    false,
+			Description: "Use the name of the alias of the requesting entity on the auth mount with this accessor as the username, the path is a regex the username has to match (only used for the personal token type)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Entity Alias Mount Accessor",
+			},
+		},
+		"entity_metadata_key": {
+			Type:        framework.TypeString,
+			Required:    false,
+			Description: "Use the value of this metadata key of the requesting entity as the username, the path is a regex the username has to match (only used for the personal token type)",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Entity Metadata Key",
+			},
+		},
+		"ci_variable_key": {
+			Type:        framework.TypeString,
+			Required:    false,
+			Description: "Write the issued token to the CI/CD variable with this key, the variable is restored or deleted when the lease is revoked",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "CI/CD Variable Key",
+			},
+		},
+		"ci_variable_target": {
+			Type:          framework.TypeString,
+			Default:       variable.TargetProject,
+			Required:      false,
+			AllowedValues: utils.ToAny(variable.Targets...),
+			Description:   "Is the CI/CD variable written to a project or a group",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "CI/CD Variable Target",
+			},
+		},
+		"ci_variable_path": {
+			Type:        framework.TypeString,
+			Required:    false,
+			Description: "Full path of the project or group the CI/CD variable is written to",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "CI/CD Variable Path",
+			},
+		},
+		"ci_variable_environment_scope": {
+			Type:        framework.TypeString,
+			Default:     variable.DefaultEnvironmentScope,
+			Required:    false,
+			Description: "Environment scope of the CI/CD variable",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "CI/CD Variable Environment Scope",
+			},
+		},
+		"ci_variable_protected": {
+			Type:        framework.TypeBool,
+			Default:     true,
+			Required:    false,
+			Description: "Is the CI/CD variable only exported to pipelines on protected branches and tags",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "CI/CD Variable Protected",
+			},
+		},
+		"ci_variable_masked": {
+			Type:        framework.TypeBool,
+			Default:     true,
+			Required:    false,
+			Description: "Is the value of the CI/CD variable masked in job logs",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "CI/CD Variable Masked",
+			},
+		},
+		"ci_variable_value": {
+			Type:        framework.TypeString,
+			Default:     "token",
+			Required:    false,
+			Description: "The field of the issued secret that is written to the CI/CD variable, e.g. private_key or client_secret",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "CI/CD Variable Value",
+			},
+		},
+		"pool_size": {
+			Type:        framework.TypeInt,
+			Default:     0,
+			Required:    false,
+			Description: "Number of tokens created ahead of the requests and handed out from a pool, 0 disables the pool",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Pool Size",
+			},
+		},
+		"pool_max_age": {
+			Type:        framework.TypeDurationSecond,
+			Default:     backend.DefaultRolePoolMaxAge,
+			Required:    false,
+			Description: "How long a token waits in the pool to be handed out, older tokens are revoked and replaced",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Pool Max Age",
+			},
+		},
+		"reuse_window": {
+			Type:        framework.TypeDurationSecond,
+			Default:     0,
+			Required:    false,
+			Description: "Requests from the same entity for the same path within this window share a token, 0 disables the reuse",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Reuse Window",
+			},
+		},
 		"dynamic_path": {
 			Type:        framework.TypeBool,
 			Default:     false,
@@ -114,6 +307,45 @@ var (
 	}
 )
 
+// tokenTypeFields are the role fields that can only be set for the listed token types.
+var tokenTypeFields = map[string][]token.Type{
+	"runner_tags":          token.RunnerTokenTypes,
+	"runner_run_untagged":  token.RunnerTokenTypes,
+	"runner_locked":        token.RunnerTokenTypes,
+	"runner_description":   token.RunnerTokenTypes,
+	"cluster_agent_create": {token.TypeClusterAgent},
+	"can_push":             {token.TypeProjectDeployKey},
+	"oauth_mode":           {token.TypeOAuthApplication},
+	"oauth_redirect_uris":  {token.TypeOAuthApplication},
+	"oauth_confidential":   {token.TypeOAuthApplication},
+
+	"entity_alias_mount_accessor": {token.TypePersonal},
+	"entity_metadata_key":         {token.TypePersonal},
+
+	"ci_variable_key":               token.CIVariableTokenTypes,
+	"ci_variable_target":            token.CIVariableTokenTypes,
+	"ci_variable_path":              token.CIVariableTokenTypes,
+	"ci_variable_environment_scope": token.CIVariableTokenTypes,
+	"ci_variable_protected":         token.CIVariableTokenTypes,
+	"ci_variable_masked":            token.CIVariableTokenTypes,
+	"ci_variable_value":             token.CIVariableTokenTypes,
+
+	"pool_size":    token.PoolTokenTypes,
+	"pool_max_age": token.PoolTokenTypes,
+
+	"reuse_window": token.ReuseTokenTypes,
+}
+
+// ciVariableFields are the role fields that configure the CI/CD variable the issued token is written to.
+var ciVariableFields = []string{
+	"ci_variable_target",
+	"ci_variable_path",
+	"ci_variable_environment_scope",
+	"ci_variable_protected",
+	"ci_variable_masked",
+	"ci_variable_value",
+}
+
 // roleBackend defines the narrow interface this provider needs.
 type roleBackend interface {
 	backend.Logging
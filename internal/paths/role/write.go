@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"context"
 	"fmt"
+	"maps"
 	"regexp"
 	"slices"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
 	"github.com/hashicorp/vault/sdk/logical"
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
@@ -18,10 +20,17 @@ import (
 	gitlabTypes "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab/types"
 	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
 	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
 )
 
+// ciVariableKeyRegex matches the keys GitLab accepts for CI/CD variables.
+var ciVariableKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9_]{1,255}$`)
+
+// identityTemplateRegex matches the identity templates in the path of a role.
+var identityTemplateRegex = regexp.MustCompile(`{{[^}]*}}`)
+
 func (p *Provider) pathRolesWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	var roleName = data.Get("role_name").(string)
 	var config *modelConfig.EntryConfig
@@ -46,6 +55,7 @@ func (p *Provider) pathRolesWrite(ctx context.Context, req *logical.Request, dat
 	var role = modelRole.Role{
 		RoleName:            roleName,
 		TTL:                 time.Duration(data.Get("ttl").(int)) * time.Second,
+		MaxTTL:              time.Duration(data.Get("max_ttl").(int)) * time.Second,
 		Path:                data.Get("path").(string),
 		Name:                data.Get("name").(string),
 		Scopes:              data.Get("scopes").([]string),
@@ -54,22 +64,62 @@ func (p *Provider) pathRolesWrite(ctx context.Context, req *logical.Request, dat
 		TokenType:           tokenType,
 		GitlabRevokesTokens: data.Get("gitlab_revokes_token").(bool),
 		ConfigName:          configName,
+		RunnerTags:          data.Get("runner_tags").([]string),
+		RunnerRunUntagged:   data.Get("runner_run_untagged").(bool),
+		RunnerLocked:        data.Get("runner_locked").(bool),
+		RunnerDescription:   data.Get("runner_description").(string),
+		ClusterAgentCreate:  data.Get("cluster_agent_create").(bool),
+		CanPush:             data.Get("can_push").(bool),
+
+		EntityAliasMountAccessor: data.Get("entity_alias_mount_accessor").(string),
+		EntityMetadataKey:        data.Get("entity_metadata_key").(string),
+	}
+
+	if tokenType == token.TypeOAuthApplication {
+		role.OAuthMode = data.Get("oauth_mode").(string)
+		role.OAuthRedirectURIs = data.Get("oauth_redirect_uris").([]string)
+		role.OAuthConfidential = data.Get("oauth_confidential").(bool)
+	}
+
+	if role.CIVariableKey = data.Get("ci_variable_key").(string); role.CIVariableKey != "" {
+		role.CIVariableTarget = data.Get("ci_variable_target").(string)
+		role.CIVariablePath = data.Get("ci_variable_path").(string)
+		role.CIVariableEnvironmentScope = data.Get("ci_variable_environment_scope").(string)
+		role.CIVariableProtected = data.Get("ci_variable_protected").(bool)
+		role.CIVariableMasked = data.Get("ci_variable_masked").(bool)
+		role.CIVariableValue = data.Get("ci_variable_value").(string)
 	}
 
+	if role.PoolSize = data.Get("pool_size").(int); role.Pooled() {
+		role.PoolMaxAge = time.Duration(data.Get("pool_max_age").(int)) * time.Second
+	}
+
+	role.ReuseWindow = time.Duration(data.Get("reuse_window").(int)) * time.Second
+
 	// validate the name of the entry role
 	if e := utils.ValidateTokenNameName(role); e != nil {
 		err = multierror.Append(err, fmt.Errorf("invalid template %s for name: %w", role.Name, e))
 	}
 
-	if role.DynamicPath {
-		// if we have a dynamic path, and we can override the path, validate the regexp that it compiles
+	var path = role.Path
+	if role.PathTemplated() {
+		if _, _, e := identitytpl.PopulateString(identitytpl.PopulateStringInput{String: role.Path, ValidityCheckOnly: true}); e != nil {
+			err = multierror.Append(err, fmt.Errorf("invalid identity template in path %s: %w: %w", role.Path, e, errs.ErrInvalidValue))
+		}
+		// the values of the templates are only known when a token is requested,
+		// the rest of the path is validated with a placeholder in their place
+		path = identityTemplateRegex.ReplaceAllString(role.Path, "placeholder")
+	}
+
+	if role.DynamicPath || role.UsernameFromEntity() {
+		// if we have a dynamic path, or the username comes from the entity, validate the regexp that it compiles
 		// this is required as during token creation we will validate the path using this regexp
-		if _, err = regexp.Compile(role.Path); err != nil {
+		if _, e := regexp.Compile(path); e != nil {
 			err = multierror.Append(err, fmt.Errorf("invalid regexp %s for path: %w", role.Path, errs.ErrInvalidValue))
 		}
 	} else {
 		// validate the path that it confirms to the correct format for the given
-		if !token.IsValidPath(role.Path, role.TokenType) {
+		if !token.IsValidPath(path, role.TokenType) {
 			err = multierror.Append(err, fmt.Errorf("invalid path %s for token type %s: %w", role.Path, role.TokenType, errs.ErrInvalidValue))
 		}
 	}
@@ -90,19 +140,30 @@ func (p *Provider) pathRolesWrite(ctx context.Context, req *logical.Request, dat
 	var skipFields []string
 
 	switch tokenType {
-	case token.TypePersonal, token.TypeUserServiceAccount, token.TypeGroupServiceAccount, token.TypeProjectServiceAccount:
+	case token.TypePersonal, token.TypeImpersonation, token.TypeUserServiceAccount, token.TypeGroupServiceAccount, token.TypeProjectServiceAccount,
+		token.TypeEphemeralGroupServiceAccount, token.TypeEphemeralUserServiceAccount:
 		skipFields = []string{"config_name", "access_level"}
 	case token.TypeGroup, token.TypeProject:
 		skipFields = []string{"config_name"}
 	case token.TypePipelineProjectTrigger:
 		skipFields = []string{"config_name", "access_level", "scopes"}
+	case token.TypeMembership:
+		skipFields = []string{"config_name", "scopes"}
+	case token.TypeGroupRunner, token.TypeProjectRunner, token.TypeInstanceRunner, token.TypeClusterAgent, token.TypeProjectDeployKey, token.TypeUserSSHKey:
+		skipFields = []string{"config_name", "access_level", "scopes"}
 	case token.TypeProjectDeploy, token.TypeGroupDeploy:
 		noEmptyScopes = true
 		skipFields = []string{"config_name", "access_level"}
+	case token.TypeProjectJobTokenAllowlist, token.TypeGroupJobTokenAllowlist:
+		skipFields = []string{"config_name", "access_level", "scopes"}
+	case token.TypeOAuthApplication:
+		noEmptyScopes = role.OAuthMode == token.OAuthApplicationModeCreate
+		skipFields = []string{"config_name", "access_level", "scopes"}
 	}
 
 	// always skip these fields
 	skipFields = append(skipFields, "dynamic_path")
+	skipFields = slices.AppendSeq(skipFields, maps.Keys(tokenTypeFields))
 
 	var invalidScopes []string
 
@@ -113,8 +174,8 @@ func (p *Provider) pathRolesWrite(ctx context.Context, req *logical.Request, dat
 		}
 
 		val, ok, _ := data.GetOkErr(name)
-		if (tokenType == token.TypePersonal && name == "access_level") ||
-			name == "gitlab_revokes_token" {
+		if (slices.Contains([]token.Type{token.TypePersonal, token.TypeImpersonation}, tokenType) && name == "access_level") ||
+			name == "gitlab_revokes_token" || name == "max_ttl" {
 			continue
 		}
 
@@ -142,12 +203,22 @@ func (p *Provider) pathRolesWrite(ctx context.Context, req *logical.Request, dat
 		}
 	}
 
+	if role.MaxTTL > 0 {
+		if role.MaxTTL < role.TTL {
+			err = multierror.Append(err, fmt.Errorf("max_ttl = %s [max_ttl >= ttl = %s]: %w", role.MaxTTL, role.TTL, errs.ErrInvalidValue))
+		}
+		if role.MaxTTL > backend.DefaultAccessTokenMaxPossibleTTL {
+			err = multierror.Append(err, fmt.Errorf("max_ttl = %s [max_ttl <= %s]: %w", role.MaxTTL, backend.DefaultAccessTokenMaxPossibleTTL, errs.ErrInvalidValue))
+		}
+	}
+
 	if !accessLevelApplicable {
 		// Token type does not take an access_level — only the empty value is allowed.
 		if accessLevel != token.AccessLevelUnknown {
 			err = multierror.Append(err, fmt.Errorf("access_level='%s', should be one of %v: %w", data.Get("access_level").(string), allowedAccessLevels, errs.ErrFieldInvalidValue))
 		}
-	} else if !token.IsAccessLevelAllowed(tokenType, accessLevel, gitlabVersion) {
+	} else if (accessLevel != token.AccessLevelUnknown || !token.IsAccessLevelOptional(tokenType)) &&
+		!token.IsAccessLevelAllowed(tokenType, accessLevel, gitlabVersion) {
 		err = multierror.Append(err, fmt.Errorf("access_level='%s' not allowed for token_type='%s' on gitlab %s, should be one of %v: %w", data.Get("access_level").(string), tokenType, gitlabVersion, allowedAccessLevels, errs.ErrFieldInvalidValue))
 	}
 
@@ -169,7 +240,111 @@ func (p *Provider) pathRolesWrite(ctx context.Context, req *logical.Request, dat
 		}
 	}
 
-	if tokenType == token.TypeUserServiceAccount && (config.Type == gitlabTypes.TypeSaaS || config.Type == gitlabTypes.TypeDedicated) {
+	// the service account or runner is deleted when the lease is revoked, so the plugin has to revoke it
+	if role.GitlabRevokesTokens && token.IsRevokedByVault(tokenType) {
+		err = multierror.Append(err, fmt.Errorf("gitlab_revokes_token cannot be used with token_type='%s': %w", tokenType, errs.ErrFieldInvalidValue))
+	}
+
+	if slices.Contains([]token.Type{token.TypeUserServiceAccount, token.TypeEphemeralUserServiceAccount, token.TypeUserSSHKey, token.TypeImpersonation}, tokenType) && (config.Type == gitlabTypes.TypeSaaS || config.Type == gitlabTypes.TypeDedicated) {
+		err = multierror.Append(err, fmt.Errorf("cannot create %s with %s: %w", tokenType, config.Type, errs.ErrInvalidValue))
+	}
+
+	for name, tokenTypes := range tokenTypeFields {
+		if _, ok := data.Raw[name]; ok && !slices.Contains(tokenTypes, tokenType) {
+			err = multierror.Append(err, fmt.Errorf("%s cannot be used with token_type='%s': %w", name, tokenType, errs.ErrFieldInvalidValue))
+		}
+	}
+
+	if tokenType == token.TypeOAuthApplication {
+		switch role.OAuthMode {
+		case token.OAuthApplicationModeCreate:
+			if len(role.OAuthRedirectURIs) == 0 {
+				err = multierror.Append(err, fmt.Errorf("oauth_redirect_uris: %w", errs.ErrFieldRequired))
+			}
+		case token.OAuthApplicationModeRotate:
+			// the existing application keeps its own settings, only the secret is renewed
+			for _, name := range []string{"scopes", "oauth_redirect_uris", "oauth_confidential"} {
+				if _, ok := data.Raw[name]; ok {
+					err = multierror.Append(err, fmt.Errorf("%s cannot be used with oauth_mode='%s': %w", name, role.OAuthMode, errs.ErrFieldInvalidValue))
+				}
+			}
+			if _, e := strconv.ParseInt(role.Path, 10, 64); !role.DynamicPath && !role.PathTemplated() && e != nil {
+				err = multierror.Append(err, fmt.Errorf("path = %s should be the id of the application with oauth_mode='%s': %w", role.Path, role.OAuthMode, errs.ErrInvalidValue))
+			}
+		default:
+			err = multierror.Append(err, fmt.Errorf("oauth_mode='%s', should be one of %v: %w", role.OAuthMode, token.OAuthApplicationModes, errs.ErrFieldInvalidValue))
+		}
+	}
+
+	if role.UsernameFromEntity() {
+		if role.EntityAliasMountAccessor != "" && role.EntityMetadataKey != "" {
+			err = multierror.Append(err, fmt.Errorf("only one of entity_alias_mount_accessor or entity_metadata_key can be set: %w", errs.ErrFieldInvalidValue))
+		}
+		if role.DynamicPath {
+			err = multierror.Append(err, fmt.Errorf("dynamic_path cannot be used when the username comes from the entity: %w", errs.ErrFieldInvalidValue))
+		}
+	}
+
+	if role.CIVariableKey != "" {
+		if !ciVariableKeyRegex.MatchString(role.CIVariableKey) {
+			err = multierror.Append(err, fmt.Errorf("ci_variable_key = %s should only contain letters, digits and '_' and be at most 255 characters: %w", role.CIVariableKey, errs.ErrFieldInvalidValue))
+		}
+		if !slices.Contains(variable.Targets, role.CIVariableTarget) {
+			err = multierror.Append(err, fmt.Errorf("ci_variable_target='%s', should be one of %v: %w", role.CIVariableTarget, variable.Targets, errs.ErrFieldInvalidValue))
+		}
+		if role.CIVariablePath == "" {
+			err = multierror.Append(err, fmt.Errorf("ci_variable_path: %w", errs.ErrFieldRequired))
+		}
+		if role.CIVariableEnvironmentScope == "" {
+			err = multierror.Append(err, fmt.Errorf("ci_variable_environment_scope: %w", errs.ErrFieldRequired))
+		}
+		if role.CIVariableValue == "" {
+			err = multierror.Append(err, fmt.Errorf("ci_variable_value: %w", errs.ErrFieldRequired))
+		}
+	} else {
+		for _, name := range ciVariableFields {
+			if _, ok := data.Raw[name]; ok {
+				err = multierror.Append(err, fmt.Errorf("%s cannot be used without ci_variable_key: %w", name, errs.ErrFieldInvalidValue))
+			}
+		}
+	}
+
+	if role.Pooled() {
+		if role.PoolSize > backend.MaxRolePoolSize {
+			err = multierror.Append(err, fmt.Errorf("pool_size = %d [pool_size <= %d]: %w", role.PoolSize, backend.MaxRolePoolSize, errs.ErrFieldInvalidValue))
+		}
+		if role.PoolMaxAge < time.Minute || role.GetMaxTTL()+role.PoolMaxAge > backend.DefaultAccessTokenMaxPossibleTTL {
+			err = multierror.Append(err, fmt.Errorf("pool_max_age = %s [1m <= pool_max_age <= %s - max_ttl]: %w", role.PoolMaxAge, backend.DefaultAccessTokenMaxPossibleTTL, errs.ErrFieldInvalidValue))
+		}
+		// a pooled token is created before the request, so its path can't depend on the request
+		if role.DynamicPath || role.PathTemplated() || role.UsernameFromEntity() {
+			err = multierror.Append(err, fmt.Errorf("pool_size cannot be used when the path is resolved for each request: %w", errs.ErrFieldInvalidValue))
+		}
+		// the lease of a pooled token starts when it is handed out, GitLab would expire it earlier
+		if role.GitlabRevokesTokens {
+			err = multierror.Append(err, fmt.Errorf("pool_size cannot be used with gitlab_revokes_token: %w", errs.ErrFieldInvalidValue))
+		}
+	} else if role.PoolSize < 0 {
+		err = multierror.Append(err, fmt.Errorf("pool_size = %d [pool_size >= 0]: %w", role.PoolSize, errs.ErrFieldInvalidValue))
+	} else if _, ok := data.Raw["pool_max_age"]; ok {
+		err = multierror.Append(err, fmt.Errorf("pool_max_age cannot be used without pool_size: %w", errs.ErrFieldInvalidValue))
+	}
+
+	if role.ReuseWindow > 0 {
+		if role.ReuseWindow >= role.GetMaxTTL() {
+			err = multierror.Append(err, fmt.Errorf("reuse_window = %s [reuse_window < max_ttl]: %w", role.ReuseWindow, errs.ErrFieldInvalidValue))
+		}
+		// a shared token outlives the leases that GitLab would expire it for
+		if role.GitlabRevokesTokens {
+			err = multierror.Append(err, fmt.Errorf("reuse_window cannot be used with gitlab_revokes_token: %w", errs.ErrFieldInvalidValue))
+		}
+		// the variable is restored when a lease is revoked, while the other leases still use the token
+		if role.CIVariableKey != "" {
+			err = multierror.Append(err, fmt.Errorf("reuse_window cannot be used with ci_variable_key: %w", errs.ErrFieldInvalidValue))
+		}
+	}
+
+	if slices.Contains([]token.Type{token.TypeInstanceRunner, token.TypeOAuthApplication}, tokenType) && (config.Type == gitlabTypes.TypeSaaS || config.Type == gitlabTypes.TypeDedicated) {
 		err = multierror.Append(err, fmt.Errorf("cannot create %s with %s: %w", tokenType, config.Type, errs.ErrInvalidValue))
 	}
 
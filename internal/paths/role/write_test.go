@@ -3,6 +3,7 @@ package role_test
 import (
 	"context"
 	"errors"
+	"maps"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
 	gitlabTypes "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab/types"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 )
 
@@ -63,6 +65,18 @@ func TestPathRolesWrite_HappyPath(t *testing.T) {
 		require.False(t, resp.IsError())
 	})
 
+	t.Run("max ttl", func(t *testing.T) {
+		raw := personalRaw()
+		raw["max_ttl"] = 4 * 3600
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(raw))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, int64(3600), resp.Data["ttl"])
+		assert.Equal(t, int64(4*3600), resp.Data["max_ttl"])
+	})
+
 	t.Run("pipeline trigger without TTL", func(t *testing.T) {
 		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
 			t.Context(), newRequest(), newFieldData(map[string]interface{}{
@@ -77,6 +91,221 @@ func TestPathRolesWrite_HappyPath(t *testing.T) {
 		assert.Equal(t, int64(0), resp.Data["ttl"])
 	})
 
+	t.Run("ephemeral service account with and without membership", func(t *testing.T) {
+		for _, accessLevel := range []string{"", token.AccessLevelDeveloperPermissions.String()} {
+			resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+				t.Context(), newRequest(), newFieldData(map[string]interface{}{
+					"role_name":    "ephemeral-role",
+					"path":         "my-group/my-project",
+					"name":         "ephemeral-token",
+					"token_type":   token.TypeEphemeralGroupServiceAccount.String(),
+					"access_level": accessLevel,
+					"scopes":       token.ScopeApi.String(),
+					"ttl":          3600,
+				}))
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+			require.False(t, resp.IsError())
+		}
+	})
+
+	t.Run("membership", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":    "membership-role",
+				"path":         "my-group/my-project/alice",
+				"name":         "membership",
+				"token_type":   token.TypeMembership.String(),
+				"access_level": token.AccessLevelMaintainerPermissions.String(),
+				"ttl":          3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+	})
+
+	t.Run("runner", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":           "runner-role",
+				"path":                "my-group/my-project",
+				"name":                "runner",
+				"token_type":          token.TypeProjectRunner.String(),
+				"runner_tags":         "docker,linux",
+				"runner_run_untagged": true,
+				"runner_description":  "project runner",
+				"ttl":                 3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, "docker, linux", resp.Data["runner_tags"])
+		assert.Equal(t, true, resp.Data["runner_run_untagged"])
+		assert.Empty(t, resp.Warnings)
+	})
+
+	t.Run("cluster agent", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":            "agent-role",
+				"path":                 "my-group/my-project/production",
+				"name":                 "agent",
+				"token_type":           token.TypeClusterAgent.String(),
+				"cluster_agent_create": true,
+				"ttl":                  3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, true, resp.Data["cluster_agent_create"])
+	})
+
+	t.Run("project deploy key", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":  "deploy-key-role",
+				"path":       "my-group/my-project",
+				"name":       "deploy-key",
+				"token_type": token.TypeProjectDeployKey.String(),
+				"can_push":   true,
+				"ttl":        3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, true, resp.Data["can_push"])
+	})
+
+	t.Run("job token allowlist", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":  "allowlist-role",
+				"path":       "my-group/my-project:my-group/consumer",
+				"name":       "allowlist",
+				"token_type": token.TypeProjectJobTokenAllowlist.String(),
+				"ttl":        3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, "my-group/my-project:my-group/consumer", resp.Data["path"])
+	})
+
+	t.Run("personal with the username from the entity", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":                   "self-service",
+				"path":                        "^[a-z][a-z0-9-]*$",
+				"name":                        "self-service",
+				"token_type":                  token.TypePersonal.String(),
+				"scopes":                      token.ScopeReadApi.String(),
+				"entity_alias_mount_accessor": "auth_oidc_5678",
+				"ttl":                         3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, "auth_oidc_5678", resp.Data["entity_alias_mount_accessor"])
+		assert.Equal(t, "^[a-z][a-z0-9-]*$", resp.Data["path"])
+	})
+
+	t.Run("identity template in path", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":    "team-group",
+				"path":         "{{identity.entity.metadata.gitlab_group}}",
+				"name":         "team",
+				"token_type":   token.TypeGroup.String(),
+				"access_level": token.AccessLevelDeveloperPermissions.String(),
+				"scopes":       token.ScopeReadApi.String(),
+				"ttl":          3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, "{{identity.entity.metadata.gitlab_group}}", resp.Data["path"])
+	})
+
+	t.Run("ci variable", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":                     "ci-variable-role",
+				"path":                          "my-group/my-project",
+				"name":                          "deploy",
+				"token_type":                    token.TypeProjectDeploy.String(),
+				"scopes":                        token.ScopeReadRepository.String(),
+				"ci_variable_key":               "DEPLOY_TOKEN",
+				"ci_variable_path":              "my-group/consumer",
+				"ci_variable_environment_scope": "production",
+				"ttl":                           3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, "DEPLOY_TOKEN", resp.Data["ci_variable_key"])
+		assert.Equal(t, variable.TargetProject, resp.Data["ci_variable_target"])
+		assert.Equal(t, "production", resp.Data["ci_variable_environment_scope"])
+		assert.Equal(t, true, resp.Data["ci_variable_protected"])
+		assert.Equal(t, true, resp.Data["ci_variable_masked"])
+		assert.Equal(t, "token", resp.Data["ci_variable_value"])
+	})
+
+	t.Run("pool", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(poolRaw(nil)))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Empty(t, resp.Warnings)
+		assert.Equal(t, 5, resp.Data["pool_size"])
+		assert.EqualValues(t, 3600, resp.Data["pool_max_age"], "defaults to an hour")
+	})
+
+	t.Run("reuse window", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(reuseRaw(map[string]interface{}{"path": "^my-group/.*$", "dynamic_path": true})))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Empty(t, resp.Warnings)
+		assert.EqualValues(t, 60, resp.Data["reuse_window"])
+	})
+
+	t.Run("oauth application", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":           "oauth-role",
+				"path":                "dashboard",
+				"name":                "dashboard",
+				"token_type":          token.TypeOAuthApplication.String(),
+				"scopes":              "openid,profile",
+				"oauth_redirect_uris": "https://dashboard.example.com/callback",
+				"ttl":                 3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, token.OAuthApplicationModeCreate, resp.Data["oauth_mode"])
+		assert.Equal(t, true, resp.Data["oauth_confidential"])
+		assert.Equal(t, "https://dashboard.example.com/callback", resp.Data["oauth_redirect_uris"])
+	})
+
+	t.Run("oauth application rotate", func(t *testing.T) {
+		resp, err := writeHandler(&mockRoleBackend{config: testConfig()})(
+			t.Context(), newRequest(), newFieldData(map[string]interface{}{
+				"role_name":  "oauth-role",
+				"path":       "42",
+				"name":       "dashboard",
+				"token_type": token.TypeOAuthApplication.String(),
+				"oauth_mode": token.OAuthApplicationModeRotate,
+				"ttl":        3600,
+			}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.Equal(t, token.OAuthApplicationModeRotate, resp.Data["oauth_mode"])
+	})
+
 	t.Run("dynamic path with valid regex", func(t *testing.T) {
 		raw := personalRaw()
 		raw["path"] = "test-.*123$"
@@ -175,6 +404,36 @@ func TestPathRolesWrite_VersionGating(t *testing.T) {
 	})
 }
 
+func poolRaw(extra map[string]interface{}) map[string]interface{} {
+	raw := map[string]interface{}{
+		"role_name":    "pool-role",
+		"path":         "my-group/my-project",
+		"name":         "pooled",
+		"token_type":   token.TypeProject.String(),
+		"access_level": token.AccessLevelDeveloperPermissions.String(),
+		"scopes":       token.ScopeApi.String(),
+		"ttl":          3600,
+		"pool_size":    5,
+	}
+	maps.Copy(raw, extra)
+	return raw
+}
+
+func reuseRaw(extra map[string]interface{}) map[string]interface{} {
+	raw := map[string]interface{}{
+		"role_name":    "reuse-role",
+		"path":         "my-group/my-project",
+		"name":         "reused",
+		"token_type":   token.TypeProject.String(),
+		"access_level": token.AccessLevelDeveloperPermissions.String(),
+		"scopes":       token.ScopeApi.String(),
+		"ttl":          3600,
+		"reuse_window": 60,
+	}
+	maps.Copy(raw, extra)
+	return raw
+}
+
 func TestPathRolesWrite_ValidationErrors(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -223,6 +482,24 @@ func TestPathRolesWrite_ValidationErrors(t *testing.T) {
 			}(),
 			errContains: "max_ttl",
 		},
+		{
+			name: "max TTL below TTL",
+			raw: func() map[string]interface{} {
+				r := personalRaw()
+				r["max_ttl"] = 3599
+				return r
+			}(),
+			errContains: "max_ttl",
+		},
+		{
+			name: "max TTL exceeds max",
+			raw: func() map[string]interface{} {
+				r := personalRaw()
+				r["max_ttl"] = 365*24*3600 + 3600
+				return r
+			}(),
+			errContains: "max_ttl",
+		},
 		{
 			name: "TTL below 1h when vault revokes",
 			raw: func() map[string]interface{} {
@@ -282,6 +559,428 @@ func TestPathRolesWrite_ValidationErrors(t *testing.T) {
 			}(),
 			errContains: "invalid template",
 		},
+		{
+			name: "membership without access level",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "my-group/alice",
+				"name":       "membership",
+				"token_type": token.TypeMembership.String(),
+				"ttl":        3600,
+			},
+			errContains: "access_level",
+		},
+		{
+			name: "membership with scopes",
+			raw: map[string]interface{}{
+				"role_name":    "test-role",
+				"path":         "my-group/alice",
+				"name":         "membership",
+				"token_type":   token.TypeMembership.String(),
+				"access_level": token.AccessLevelDeveloperPermissions.String(),
+				"scopes":       token.ScopeApi.String(),
+				"ttl":          3600,
+			},
+			errContains: "does not support scopes",
+		},
+		{
+			name: "runner fields on a project token",
+			raw: map[string]interface{}{
+				"role_name":    "test-role",
+				"path":         "my-group/my-project",
+				"name":         "project-token",
+				"token_type":   token.TypeProject.String(),
+				"access_level": token.AccessLevelDeveloperPermissions.String(),
+				"scopes":       token.ScopeApi.String(),
+				"runner_tags":  "docker",
+				"ttl":          3600,
+			},
+			errContains: "runner_tags cannot be used",
+		},
+		{
+			name: "cluster agent fields on a runner",
+			raw: map[string]interface{}{
+				"role_name":            "test-role",
+				"path":                 "my-group",
+				"name":                 "runner",
+				"token_type":           token.TypeGroupRunner.String(),
+				"cluster_agent_create": true,
+				"ttl":                  3600,
+			},
+			errContains: "cluster_agent_create cannot be used",
+		},
+		{
+			name: "cluster agent with an invalid agent name",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "my-group/my-project/Production",
+				"name":       "agent",
+				"token_type": token.TypeClusterAgent.String(),
+				"ttl":        3600,
+			},
+			errContains: "invalid path",
+		},
+		{
+			name: "can_push on a project deploy token",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "my-group/my-project",
+				"name":       "deploy",
+				"token_type": token.TypeProjectDeploy.String(),
+				"scopes":     token.ScopeReadRepository.String(),
+				"can_push":   true,
+				"ttl":        3600,
+			},
+			errContains: "can_push cannot be used",
+		},
+		{
+			name: "username from both the entity alias and metadata",
+			raw: map[string]interface{}{
+				"role_name":                   "test-role",
+				"path":                        ".*",
+				"name":                        "personal",
+				"token_type":                  token.TypePersonal.String(),
+				"scopes":                      token.ScopeApi.String(),
+				"entity_alias_mount_accessor": "auth_oidc_5678",
+				"entity_metadata_key":         "gitlab_username",
+				"ttl":                         3600,
+			},
+			errContains: "only one of entity_alias_mount_accessor or entity_metadata_key",
+		},
+		{
+			name: "username from the entity with a dynamic path",
+			raw: map[string]interface{}{
+				"role_name":           "test-role",
+				"path":                ".*",
+				"name":                "personal",
+				"token_type":          token.TypePersonal.String(),
+				"scopes":              token.ScopeApi.String(),
+				"entity_metadata_key": "gitlab_username",
+				"dynamic_path":        true,
+				"ttl":                 3600,
+			},
+			errContains: "dynamic_path cannot be used when the username comes from the entity",
+		},
+		{
+			name: "username from the entity with an invalid regex",
+			raw: map[string]interface{}{
+				"role_name":           "test-role",
+				"path":                "[a-z",
+				"name":                "personal",
+				"token_type":          token.TypePersonal.String(),
+				"scopes":              token.ScopeApi.String(),
+				"entity_metadata_key": "gitlab_username",
+				"ttl":                 3600,
+			},
+			errContains: "invalid regexp",
+		},
+		{
+			name: "unbalanced identity template in path",
+			raw: map[string]interface{}{
+				"role_name":    "test-role",
+				"path":         "{{identity.entity.metadata.gitlab_group}/app",
+				"name":         "project",
+				"token_type":   token.TypeProject.String(),
+				"access_level": token.AccessLevelDeveloperPermissions.String(),
+				"scopes":       token.ScopeApi.String(),
+				"ttl":          3600,
+			},
+			errContains: "invalid identity template in path",
+		},
+		{
+			name: "identity template in an invalid path",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "{{identity.entity.metadata.gitlab_group}}/service-account/extra",
+				"name":       "service-account",
+				"token_type": token.TypeGroupServiceAccount.String(),
+				"scopes":     token.ScopeApi.String(),
+				"ttl":        3600,
+			},
+			errContains: "invalid path",
+		},
+		{
+			name: "username from the entity on a project token",
+			raw: map[string]interface{}{
+				"role_name":           "test-role",
+				"path":                "my-group/my-project",
+				"name":                "project",
+				"token_type":          token.TypeProject.String(),
+				"access_level":        token.AccessLevelDeveloperPermissions.String(),
+				"scopes":              token.ScopeApi.String(),
+				"entity_metadata_key": "gitlab_username",
+				"ttl":                 3600,
+			},
+			errContains: "entity_metadata_key cannot be used with token_type='project'",
+		},
+		{
+			name: "ci variable with an invalid key",
+			raw: map[string]interface{}{
+				"role_name":        "test-role",
+				"path":             "my-group/my-project",
+				"name":             "project",
+				"token_type":       token.TypeProject.String(),
+				"access_level":     token.AccessLevelDeveloperPermissions.String(),
+				"scopes":           token.ScopeApi.String(),
+				"ci_variable_key":  "DEPLOY-TOKEN",
+				"ci_variable_path": "my-group/consumer",
+				"ttl":              3600,
+			},
+			errContains: "ci_variable_key = DEPLOY-TOKEN",
+		},
+		{
+			name: "ci variable without a path",
+			raw: map[string]interface{}{
+				"role_name":       "test-role",
+				"path":            "my-group/my-project",
+				"name":            "project",
+				"token_type":      token.TypeProject.String(),
+				"access_level":    token.AccessLevelDeveloperPermissions.String(),
+				"scopes":          token.ScopeApi.String(),
+				"ci_variable_key": "DEPLOY_TOKEN",
+				"ttl":             3600,
+			},
+			errContains: "ci_variable_path",
+		},
+		{
+			name: "ci variable with an invalid target",
+			raw: map[string]interface{}{
+				"role_name":          "test-role",
+				"path":               "my-group/my-project",
+				"name":               "project",
+				"token_type":         token.TypeProject.String(),
+				"access_level":       token.AccessLevelDeveloperPermissions.String(),
+				"scopes":             token.ScopeApi.String(),
+				"ci_variable_key":    "DEPLOY_TOKEN",
+				"ci_variable_path":   "my-group/consumer",
+				"ci_variable_target": "instance",
+				"ttl":                3600,
+			},
+			errContains: "ci_variable_target='instance'",
+		},
+		{
+			name: "ci variable fields without a key",
+			raw: map[string]interface{}{
+				"role_name":        "test-role",
+				"path":             "my-group/my-project",
+				"name":             "project",
+				"token_type":       token.TypeProject.String(),
+				"access_level":     token.AccessLevelDeveloperPermissions.String(),
+				"scopes":           token.ScopeApi.String(),
+				"ci_variable_path": "my-group/consumer",
+				"ttl":              3600,
+			},
+			errContains: "ci_variable_path cannot be used without ci_variable_key",
+		},
+		{
+			name: "ci variable on a membership",
+			raw: map[string]interface{}{
+				"role_name":        "test-role",
+				"path":             "my-group/alice",
+				"name":             "membership",
+				"token_type":       token.TypeMembership.String(),
+				"access_level":     token.AccessLevelDeveloperPermissions.String(),
+				"ci_variable_key":  "DEPLOY_TOKEN",
+				"ci_variable_path": "my-group/consumer",
+				"ttl":              3600,
+			},
+			errContains: "ci_variable_key cannot be used with token_type='membership'",
+		},
+		{
+			name: "impersonation with SaaS config",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "alice",
+				"name":       "impersonation",
+				"token_type": token.TypeImpersonation.String(),
+				"scopes":     token.ScopeApi.String(),
+				"ttl":        3600,
+			},
+			config: func() *mockRoleBackend {
+				cfg := testConfig()
+				cfg.Type = gitlabTypes.TypeSaaS
+				return &mockRoleBackend{config: cfg}
+			},
+			errContains: "cannot create",
+		},
+		{
+			name: "user ssh key with SaaS config",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "alice",
+				"name":       "ssh-key",
+				"token_type": token.TypeUserSSHKey.String(),
+				"ttl":        3600,
+			},
+			config: func() *mockRoleBackend {
+				cfg := testConfig()
+				cfg.Type = gitlabTypes.TypeSaaS
+				return &mockRoleBackend{config: cfg}
+			},
+			errContains: "cannot create",
+		},
+		{
+			name: "job token allowlist without the allowed group",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "my-group/my-project",
+				"name":       "allowlist",
+				"token_type": token.TypeGroupJobTokenAllowlist.String(),
+				"ttl":        3600,
+			},
+			errContains: "invalid path",
+		},
+		{
+			name: "job token allowlist with scopes",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "my-group/my-project:my-group",
+				"name":       "allowlist",
+				"token_type": token.TypeGroupJobTokenAllowlist.String(),
+				"scopes":     token.ScopeApi.String(),
+				"ttl":        3600,
+			},
+			errContains: "does not support scopes",
+		},
+		{
+			name: "oauth application without redirect uris",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "dashboard",
+				"name":       "dashboard",
+				"token_type": token.TypeOAuthApplication.String(),
+				"scopes":     token.ScopeOpenID.String(),
+				"ttl":        3600,
+			},
+			errContains: "oauth_redirect_uris",
+		},
+		{
+			name: "oauth application without scopes",
+			raw: map[string]interface{}{
+				"role_name":           "test-role",
+				"path":                "dashboard",
+				"name":                "dashboard",
+				"token_type":          token.TypeOAuthApplication.String(),
+				"oauth_redirect_uris": "https://dashboard.example.com/callback",
+				"ttl":                 3600,
+			},
+			errContains: "should be one or more of",
+		},
+		{
+			name: "oauth application rotate with a path that is not an id",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "dashboard",
+				"name":       "dashboard",
+				"token_type": token.TypeOAuthApplication.String(),
+				"oauth_mode": token.OAuthApplicationModeRotate,
+				"ttl":        3600,
+			},
+			errContains: "should be the id of the application",
+		},
+		{
+			name: "oauth application rotate with scopes",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "42",
+				"name":       "dashboard",
+				"token_type": token.TypeOAuthApplication.String(),
+				"oauth_mode": token.OAuthApplicationModeRotate,
+				"scopes":     token.ScopeOpenID.String(),
+				"ttl":        3600,
+			},
+			errContains: "scopes cannot be used with oauth_mode='rotate'",
+		},
+		{
+			name: "oauth_mode on a project token",
+			raw: map[string]interface{}{
+				"role_name":    "test-role",
+				"path":         "my-group/my-project",
+				"name":         "project",
+				"token_type":   token.TypeProject.String(),
+				"access_level": token.AccessLevelDeveloperPermissions.String(),
+				"scopes":       token.ScopeApi.String(),
+				"oauth_mode":   token.OAuthApplicationModeCreate,
+				"ttl":          3600,
+			},
+			errContains: "oauth_mode cannot be used",
+		},
+		{
+			name: "oauth application with SaaS config",
+			raw: map[string]interface{}{
+				"role_name":           "test-role",
+				"path":                "dashboard",
+				"name":                "dashboard",
+				"token_type":          token.TypeOAuthApplication.String(),
+				"scopes":              token.ScopeOpenID.String(),
+				"oauth_redirect_uris": "https://dashboard.example.com/callback",
+				"ttl":                 3600,
+			},
+			config: func() *mockRoleBackend {
+				cfg := testConfig()
+				cfg.Type = gitlabTypes.TypeSaaS
+				return &mockRoleBackend{config: cfg}
+			},
+			errContains: "cannot create",
+		},
+		{
+			name: "runner revoked by gitlab",
+			raw: map[string]interface{}{
+				"role_name":            "test-role",
+				"path":                 "my-group",
+				"name":                 "runner",
+				"token_type":           token.TypeGroupRunner.String(),
+				"gitlab_revokes_token": true,
+				"ttl":                  86400,
+			},
+			errContains: "gitlab_revokes_token",
+		},
+		{
+			name: "instance runner with SaaS config",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "instance",
+				"name":       "runner",
+				"token_type": token.TypeInstanceRunner.String(),
+				"ttl":        3600,
+			},
+			config: func() *mockRoleBackend {
+				cfg := testConfig()
+				cfg.Type = gitlabTypes.TypeSaaS
+				return &mockRoleBackend{config: cfg}
+			},
+			errContains: "cannot create",
+		},
+		{
+			name: "ephemeral service account revoked by gitlab",
+			raw: map[string]interface{}{
+				"role_name":            "test-role",
+				"path":                 "my-group",
+				"name":                 "ephemeral-token",
+				"token_type":           token.TypeEphemeralGroupServiceAccount.String(),
+				"scopes":               token.ScopeApi.String(),
+				"gitlab_revokes_token": true,
+				"ttl":                  86400,
+			},
+			errContains: "gitlab_revokes_token",
+		},
+		{
+			name: "ephemeral user service account with SaaS config",
+			raw: map[string]interface{}{
+				"role_name":  "test-role",
+				"path":       "my-group",
+				"name":       "ephemeral-token",
+				"token_type": token.TypeEphemeralUserServiceAccount.String(),
+				"scopes":     token.ScopeApi.String(),
+				"ttl":        3600,
+			},
+			config: func() *mockRoleBackend {
+				cfg := testConfig()
+				cfg.Type = gitlabTypes.TypeSaaS
+				return &mockRoleBackend{config: cfg}
+			},
+			errContains: "cannot create",
+		},
 		{
 			name: "user service account with SaaS config",
 			raw: map[string]interface{}{
@@ -298,6 +997,19 @@ func TestPathRolesWrite_ValidationErrors(t *testing.T) {
 				return &mockRoleBackend{config: cfg}
 			},
 		},
+		{name: "pool too large", raw: poolRaw(map[string]interface{}{"pool_size": 51}), errContains: "pool_size = 51"},
+		{name: "pool of negative size", raw: poolRaw(map[string]interface{}{"pool_size": -1}), errContains: "pool_size = -1"},
+		{name: "pool max age too short", raw: poolRaw(map[string]interface{}{"pool_max_age": 30}), errContains: "pool_max_age"},
+		{name: "pool max age past the longest token", raw: poolRaw(map[string]interface{}{"max_ttl": 365 * 86400}), errContains: "pool_max_age"},
+		{name: "pool max age without a pool", raw: poolRaw(map[string]interface{}{"pool_size": 0, "pool_max_age": 60}), errContains: "pool_max_age cannot be used without pool_size"},
+		{name: "pool with a dynamic path", raw: poolRaw(map[string]interface{}{"path": "^my-group/.*$", "dynamic_path": true}), errContains: "resolved for each request"},
+		{name: "pool with a templated path", raw: poolRaw(map[string]interface{}{"path": "my-group/{{identity.entity.name}}"}), errContains: "resolved for each request"},
+		{name: "pool revoked by gitlab", raw: poolRaw(map[string]interface{}{"gitlab_revokes_token": true, "ttl": 86400}), errContains: "pool_size cannot be used with gitlab_revokes_token"},
+		{name: "reuse window as long as the token", raw: reuseRaw(map[string]interface{}{"reuse_window": 3600}), errContains: "reuse_window = 1h0m0s"},
+		{name: "reuse window revoked by gitlab", raw: reuseRaw(map[string]interface{}{"gitlab_revokes_token": true, "ttl": 86400}), errContains: "reuse_window cannot be used with gitlab_revokes_token"},
+		{name: "reuse window with a ci variable", raw: reuseRaw(map[string]interface{}{"ci_variable_key": "TOKEN", "ci_variable_path": "my-group/my-project"}), errContains: "reuse_window cannot be used with ci_variable_key"},
+		{name: "reuse window of memberships", raw: reuseRaw(map[string]interface{}{"token_type": token.TypeMembership.String(), "scopes": ""}), errContains: "reuse_window cannot be used with token_type='membership'"},
+		{name: "pool of memberships", raw: poolRaw(map[string]interface{}{"token_type": token.TypeMembership.String(), "scopes": ""}), errContains: "pool_size cannot be used with token_type='membership'"},
 	}
 
 	for _, tt := range tests {
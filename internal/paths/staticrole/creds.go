@@ -0,0 +1,85 @@
+package staticrole
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+)
+
+const (
+	pathStaticCredsHelpSyn  = `Read the current token of a static role.`
+	pathStaticCredsHelpDesc = `
+This path returns the current value of the GitLab token managed by the static role. The value changes every time
+the token is rotated, next_rotation tells you when that is going to happen.`
+)
+
+func (p *Provider) pathStaticCreds() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathStaticCredsHelpSyn),
+		HelpDescription: strings.TrimSpace(pathStaticCredsHelpDesc),
+		Pattern:         fmt.Sprintf("%s/%s$", PathStaticCreds, framework.GenericNameRegex("role_name")),
+		Fields: map[string]*framework.FieldSchema{
+			"role_name": FieldSchemaStaticRoles["role_name"],
+		},
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "static-credentials",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: p.pathStaticCredsRead,
+				Summary:  "Read the current token of a static role",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "read",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+					}},
+				},
+			},
+		},
+	}
+}
+
+func (p *Provider) pathStaticCredsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	var roleName = data.Get("role_name").(string)
+
+	lock := p.b.LockForKey("static-role", roleName)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	role, err := p.b.GetStaticRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return logical.ErrorResponse("error reading static role"), err
+	}
+
+	if role == nil {
+		return nil, nil
+	}
+
+	p.b.Logger().Debug("Static credentials read", "role", roleName)
+
+	return &logical.Response{
+		Data: map[string]any{
+			"role_name":        role.RoleName,
+			"config_name":      role.ConfigName,
+			"token_type":       role.TokenType.String(),
+			"path":             role.Path,
+			"name":             role.Name,
+			"token":            role.Token,
+			"token_id":         role.TokenID,
+			"scopes":           role.Scopes,
+			"token_expires_at": role.TokenExpiresAt.Format(time.RFC3339),
+			"last_rotated_at":  role.LastRotatedAt.Format(time.RFC3339),
+			"next_rotation":    role.NextRotation().Format(time.RFC3339),
+		},
+	}, nil
+}
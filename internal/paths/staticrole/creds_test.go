@@ -0,0 +1,48 @@
+package staticrole_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func TestPathStaticCredsRead(t *testing.T) {
+	fd := func(mb *mockStaticRoleBackend) *framework.FieldData {
+		return fieldData(mb, 3, map[string]interface{}{"role_name": "static"})
+	}
+
+	t.Run("found", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{}}
+		req := newRequest()
+		_, err := handler(mb, 1, logical.CreateOperation)(utils.WithStaticTime(t.Context(), testNow), req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+
+		resp, err := handler(mb, 3, logical.ReadOperation)(t.Context(), req, fd(mb))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "glpat-rotated-1", resp.Data["token"])
+		assert.EqualValues(t, 101, resp.Data["token_id"])
+		assert.Equal(t, testNow.Add(168*time.Hour).Format(time.RFC3339), resp.Data["next_rotation"])
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{}
+		resp, err := handler(mb, 3, logical.ReadOperation)(t.Context(), newRequest(), fd(mb))
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{roleErr: errors.New("storage failure")}
+		resp, err := handler(mb, 3, logical.ReadOperation)(t.Context(), newRequest(), fd(mb))
+		require.Error(t, err)
+		require.True(t, resp.IsError())
+	})
+}
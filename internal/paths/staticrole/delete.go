@@ -0,0 +1,38 @@
+package staticrole
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+)
+
+func (p *Provider) pathStaticRolesDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	var roleName = data.Get("role_name").(string)
+	lock := p.b.LockForKey("static-role", roleName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	_, err := p.b.GetStaticRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting static role: %w", err)
+	}
+
+	// the token itself is left untouched in GitLab, Vault only releases the ownership
+	err = req.Storage.Delete(ctx, fmt.Sprintf("%s/%s", backend.PathStaticRoleStorage, roleName))
+	if err != nil {
+		return nil, fmt.Errorf("error deleting static role: %w", err)
+	}
+
+	_ = p.b.SendEvent(ctx, eventDelete, map[string]string{
+		"path":      backend.PathStaticRoleStorage,
+		"role_name": roleName,
+	})
+
+	p.b.Logger().Debug("Static role deleted", "role", roleName)
+
+	return nil, nil
+}
@@ -0,0 +1,35 @@
+package staticrole_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathStaticRolesDelete(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{}}
+		req := newRequest()
+		_, err := handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+
+		resp, err := handler(mb, 1, logical.DeleteOperation)(t.Context(), req, fieldData(mb, 1, map[string]interface{}{"role_name": "static"}))
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+		assert.Equal(t, "static-role-delete", mb.events[len(mb.events)-1].String())
+
+		role, err := mb.GetStaticRole(t.Context(), req.Storage, "static")
+		require.NoError(t, err)
+		assert.Nil(t, role)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{roleErr: errors.New("storage failure")}
+		resp, err := handler(mb, 1, logical.DeleteOperation)(t.Context(), newRequest(), fieldData(mb, 1, map[string]interface{}{"role_name": "static"}))
+		require.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
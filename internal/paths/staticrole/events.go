@@ -0,0 +1,9 @@
+package staticrole
+
+import "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+
+var (
+	eventWrite       = event.MustEventType("static-role-write")
+	eventDelete      = event.MustEventType("static-role-delete")
+	eventTokenRotate = event.MustEventType("static-role-token-rotate")
+)
@@ -0,0 +1,163 @@
+package staticrole_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	gitlabTypes "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab/types"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	mt "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	pathStaticRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/staticrole"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mockStaticRoleBackend is a hand-written mock satisfying the staticRoleBackend interface,
+// static roles are persisted in the request storage.
+type mockStaticRoleBackend struct {
+	config    *modelConfig.EntryConfig
+	configErr error
+	roleErr   error
+	client    gitlab.Client
+	clientErr error
+	events    []event.EventType
+	metadata  []map[string]string
+}
+
+func (m *mockStaticRoleBackend) Logger() hclog.Logger { return hclog.NewNullLogger() }
+func (m *mockStaticRoleBackend) LockForKey(_, _ string) *locksutil.LockEntry {
+	return locksutil.CreateLocks()[0]
+}
+func (m *mockStaticRoleBackend) GetConfig(_ context.Context, _ logical.Storage, _ string) (*modelConfig.EntryConfig, error) {
+	return m.config, m.configErr
+}
+func (m *mockStaticRoleBackend) SaveConfig(_ context.Context, _ logical.Storage, _ *modelConfig.EntryConfig) error {
+	return nil
+}
+func (m *mockStaticRoleBackend) GetStaticRole(ctx context.Context, s logical.Storage, name string) (*modelRole.StaticRole, error) {
+	if m.roleErr != nil {
+		return nil, m.roleErr
+	}
+	return model.Get[modelRole.StaticRole](ctx, s, fmt.Sprintf("%s/%s", backend.PathStaticRoleStorage, name))
+}
+func (m *mockStaticRoleBackend) SaveStaticRole(ctx context.Context, s logical.Storage, r *modelRole.StaticRole) error {
+	return model.Save(ctx, s, backend.PathStaticRoleStorage, r)
+}
+func (m *mockStaticRoleBackend) GetClientByName(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+	return m.client, m.clientErr
+}
+func (m *mockStaticRoleBackend) SendEvent(_ context.Context, eventType event.EventType, metadata map[string]string) error {
+	m.events = append(m.events, eventType)
+	m.metadata = append(m.metadata, metadata)
+	return nil
+}
+
+// mockGitlabClient implements the rotate methods used by static roles.
+type mockGitlabClient struct {
+	gitlab.Client
+	rotateErr error
+	rotated   int
+}
+
+func (m *mockGitlabClient) base(typ tk.Type, tokenId int64, expiresAt time.Time) mt.Token {
+	m.rotated++
+	return mt.Token{
+		TokenID: tokenId + 100, Token: fmt.Sprintf("glpat-rotated-%d", m.rotated), Name: "static",
+		TokenType: typ, CreatedAt: &testNow, ExpiresAt: &expiresAt,
+	}
+}
+
+func (m *mockGitlabClient) GetUserIdByUsername(_ context.Context, _ string) (int64, error) {
+	return 1, m.rotateErr
+}
+func (m *mockGitlabClient) RotatePersonalAccessToken(_ context.Context, _ string, tokenId int64, expiresAt time.Time) (*mt.TokenPersonal, error) {
+	if m.rotateErr != nil {
+		return nil, m.rotateErr
+	}
+	return &mt.TokenPersonal{TokenWithScopes: mt.TokenWithScopes{Token: m.base(tk.TypePersonal, tokenId, expiresAt), Scopes: []string{"api"}}}, nil
+}
+func (m *mockGitlabClient) RotateGroupAccessToken(_ context.Context, _ string, tokenId int64, expiresAt time.Time) (*mt.TokenGroup, error) {
+	if m.rotateErr != nil {
+		return nil, m.rotateErr
+	}
+	return &mt.TokenGroup{TokenWithScopesAndAccessLevel: mt.TokenWithScopesAndAccessLevel{Token: m.base(tk.TypeGroup, tokenId, expiresAt), Scopes: []string{"api"}}}, nil
+}
+func (m *mockGitlabClient) RotateProjectAccessToken(_ context.Context, _ string, tokenId int64, expiresAt time.Time) (*mt.TokenProject, error) {
+	if m.rotateErr != nil {
+		return nil, m.rotateErr
+	}
+	return &mt.TokenProject{TokenWithScopesAndAccessLevel: mt.TokenWithScopesAndAccessLevel{Token: m.base(tk.TypeProject, tokenId, expiresAt), Scopes: []string{"api"}}}, nil
+}
+func (m *mockGitlabClient) RotateGroupServiceAccountAccessToken(_ context.Context, _ string, _ string, userId int64, tokenId int64, expiresAt time.Time) (*mt.TokenGroupServiceAccount, error) {
+	if m.rotateErr != nil {
+		return nil, m.rotateErr
+	}
+	return &mt.TokenGroupServiceAccount{TokenWithScopes: mt.TokenWithScopes{Token: m.base(tk.TypeGroupServiceAccount, tokenId, expiresAt), Scopes: []string{"api"}}, UserID: userId}, nil
+}
+func (m *mockGitlabClient) RotateProjectServiceAccountAccessToken(_ context.Context, _ string, _ string, userId int64, tokenId int64, expiresAt time.Time) (*mt.TokenProjectServiceAccount, error) {
+	if m.rotateErr != nil {
+		return nil, m.rotateErr
+	}
+	return &mt.TokenProjectServiceAccount{TokenWithScopes: mt.TokenWithScopes{Token: m.base(tk.TypeProjectServiceAccount, tokenId, expiresAt), Scopes: []string{"api"}}, UserID: userId}, nil
+}
+
+// testConfig returns a minimal EntryConfig for test use.
+func testConfig() *modelConfig.EntryConfig {
+	return &modelConfig.EntryConfig{
+		BaseURL: "https://gitlab.example.com",
+		Token:   "glpat-test-token-value",
+		Type:    gitlabTypes.TypeSelfManaged,
+		Name:    "default",
+	}
+}
+
+func staticRoleRaw() map[string]interface{} {
+	return map[string]interface{}{
+		"role_name":       "static",
+		"path":            "group/project",
+		"token_id":        1,
+		"token_type":      tk.TypeProject.String(),
+		"ttl":             "720h",
+		"rotation_period": "168h",
+	}
+}
+
+func handler(mb *mockStaticRoleBackend, idx int, op logical.Operation) framework.OperationFunc {
+	return pathStaticRole.New(mb).Paths()[idx].Operations[op].Handler()
+}
+
+func fieldData(mb *mockStaticRoleBackend, idx int, raw map[string]interface{}) *framework.FieldData {
+	return &framework.FieldData{Raw: raw, Schema: pathStaticRole.New(mb).Paths()[idx].Fields}
+}
+
+// newRequest creates a minimal logical.Request with in-memory storage.
+func newRequest() *logical.Request {
+	return &logical.Request{Storage: &logical.InmemStorage{}}
+}
+
+// failingStorage is an in-memory storage that fails the next failPuts writes.
+type failingStorage struct {
+	logical.InmemStorage
+	failPuts int
+}
+
+func (s *failingStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	if s.failPuts > 0 {
+		s.failPuts--
+		return errors.New("storage error")
+	}
+	return s.InmemStorage.Put(ctx, entry)
+}
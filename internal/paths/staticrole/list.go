@@ -0,0 +1,61 @@
+package staticrole
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+)
+
+func (p *Provider) pathStaticRolesList(ctx context.Context, req *logical.Request, data *framework.FieldData) (l *logical.Response, err error) {
+	var roles []string
+	defer func() {
+		p.b.Logger().Debug("Available", "static-roles", roles, "err", err)
+	}()
+	l = logical.ErrorResponse("Error listing static roles")
+	if roles, err = req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathStaticRoleStorage)); err == nil {
+		l = logical.ListResponse(roles)
+	}
+	return l, err
+}
+
+func (p *Provider) pathListStaticRoles() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathListStaticRolesHelpSyn),
+		HelpDescription: strings.TrimSpace(pathListStaticRolesHelpDesc),
+		Pattern:         fmt.Sprintf("%s?/?$", backend.PathStaticRoleStorage),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "static-roles",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: p.pathStaticRolesList,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "list",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+						Fields: map[string]*framework.FieldSchema{
+							"role_name": FieldSchemaStaticRoles["role_name"],
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+const (
+	pathListStaticRolesHelpSyn  = `Lists existing static roles`
+	pathListStaticRolesHelpDesc = `
+This path allows you to list all static roles. Each static role manages an already existing GitLab token and 
+rotates it on a schedule.`
+)
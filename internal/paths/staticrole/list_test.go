@@ -0,0 +1,25 @@
+package staticrole_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathStaticRolesList(t *testing.T) {
+	mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{}}
+	req := newRequest()
+
+	resp, err := handler(mb, 0, logical.ListOperation)(t.Context(), req, fieldData(mb, 0, map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, resp.Data["keys"])
+
+	_, err = handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, staticRoleRaw()))
+	require.NoError(t, err)
+
+	resp, err = handler(mb, 0, logical.ListOperation)(t.Context(), req, fieldData(mb, 0, map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"static"}, resp.Data["keys"])
+}
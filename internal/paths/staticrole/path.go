@@ -0,0 +1,203 @@
+package staticrole
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+const (
+	// PathStaticCreds is the path prefix used to read the current value of a static role token.
+	PathStaticCreds = "static-creds"
+
+	pathStaticRolesHelpSyn  = `Manage an existing GitLab token and rotate it on a schedule.`
+	pathStaticRolesHelpDesc = `
+This path allows you to take ownership of an already existing GitLab token by its ID. The token is rotated
+immediately so Vault knows its value, and from then on it is rotated every rotation_period. The current value
+can be read from the ^static-creds/(?P<role_name>\w(([\w-.@]+)?\w)?)$ path. Deleting the static role does not
+revoke the token in GitLab.`
+)
+
+// StaticTokenTypes lists the token types that can be managed by a static role,
+// these are the token types GitLab can rotate by ID.
+var StaticTokenTypes = []string{
+	token.TypePersonal.String(),
+	token.TypeProject.String(),
+	token.TypeGroup.String(),
+	token.TypeUserServiceAccount.String(),
+	token.TypeGroupServiceAccount.String(),
+	token.TypeProjectServiceAccount.String(),
+}
+
+var (
+	FieldSchemaStaticRoles = map[string]*framework.FieldSchema{
+		"role_name": {
+			Type:        framework.TypeString,
+			Description: "Role name",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Role Name",
+			},
+		},
+		"path": {
+			Type:        framework.TypeString,
+			Description: "The owner of the token, the format is the same as for roles with the same token type.",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "path",
+			},
+		},
+		"token_id": {
+			Type:        framework.TypeInt64,
+			Description: "The ID of the existing token in GitLab that Vault should take ownership of.",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Token ID",
+			},
+		},
+		"token_type": {
+			Type:          framework.TypeString,
+			Description:   "access token type",
+			Required:      true,
+			AllowedValues: utils.ToAny(StaticTokenTypes...),
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Token Type",
+			},
+		},
+		"ttl": {
+			Type:        framework.TypeDurationSecond,
+			Description: "The lifetime of the token in GitLab after each rotation.",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Token TTL",
+			},
+		},
+		"rotation_period": {
+			Type:        framework.TypeDurationSecond,
+			Description: "How often the token is rotated, should be shorter than ttl.",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Rotation period",
+			},
+		},
+		"config_name": {
+			Type:        framework.TypeString,
+			Default:     backend.DefaultConfigName,
+			Required:    false,
+			Description: "The config we use when interacting with the static role, this can be specified if you want to use a specific config for the role, otherwise it uses the default one.",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Configuration.",
+			},
+		},
+	}
+)
+
+// staticRoleBackend defines the narrow interface this provider needs.
+type staticRoleBackend interface {
+	backend.Logging
+	backend.Locker
+	backend.ConfigStore
+	backend.StaticRoleStore
+	backend.ClientReader
+	backend.EventSender
+}
+
+// Provider implements backend.PathProvider for static role paths.
+type Provider struct {
+	b staticRoleBackend
+}
+
+func (p *Provider) Name() string { return "static-role" }
+
+// New creates a new static role path provider.
+func New(b staticRoleBackend) *Provider {
+	return &Provider{b: b}
+}
+
+// Paths returns all static role related framework paths.
+func (p *Provider) Paths() []*framework.Path {
+	return []*framework.Path{
+		p.pathListStaticRoles(),
+		p.pathStaticRoles(),
+		p.pathStaticRoleRotate(),
+		p.pathStaticCreds(),
+	}
+}
+
+func (p *Provider) pathStaticRoles() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathStaticRolesHelpSyn),
+		HelpDescription: strings.TrimSpace(pathStaticRolesHelpDesc),
+		Pattern:         fmt.Sprintf("%s/%s$", backend.PathStaticRoleStorage, framework.GenericNameRegex("role_name")),
+		Fields:          FieldSchemaStaticRoles,
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "static-role",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: p.pathStaticRolesDelete,
+				Summary:  "Deletes a static role, the token is not revoked in GitLab",
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: http.StatusText(http.StatusNoContent),
+					}},
+				},
+			},
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: p.pathStaticRolesWrite,
+				Summary:  "Creates a new static role and takes ownership of the token",
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: http.StatusText(http.StatusNoContent),
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: p.pathStaticRolesWrite,
+				Summary:  "Updates an existing static role",
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: http.StatusText(http.StatusNoContent),
+					}},
+				},
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: p.pathStaticRolesRead,
+				Summary:  "Reads an existing static role",
+				Responses: map[int][]framework.Response{
+					http.StatusNotFound: {{
+						Description: http.StatusText(http.StatusNotFound),
+					}},
+					http.StatusOK: {{
+						Fields: FieldSchemaStaticRoles,
+					}},
+				},
+			},
+		},
+		ExistenceCheck: p.pathStaticRoleExistenceCheck,
+	}
+}
+
+func (p *Provider) pathStaticRoleExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	name := data.Get("role_name").(string)
+	role, err := p.b.GetStaticRole(ctx, req.Storage, name)
+	if err != nil {
+		if strings.Contains(err.Error(), logical.ErrReadOnly.Error()) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("error reading static role: %w", err)
+	}
+
+	return role != nil, nil
+}
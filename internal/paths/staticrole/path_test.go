@@ -0,0 +1,31 @@
+package staticrole_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pathStaticRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/staticrole"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := pathStaticRole.New(&mockStaticRoleBackend{})
+	assert.Equal(t, "static-role", p.Name())
+}
+
+func TestProvider_Paths(t *testing.T) {
+	p := pathStaticRole.New(&mockStaticRoleBackend{})
+	paths := p.Paths()
+	require.Len(t, paths, 4)
+
+	assert.NotNil(t, paths[0].Operations[logical.ListOperation])
+	assert.NotNil(t, paths[1].Operations[logical.CreateOperation])
+	assert.NotNil(t, paths[1].Operations[logical.UpdateOperation])
+	assert.NotNil(t, paths[1].Operations[logical.ReadOperation])
+	assert.NotNil(t, paths[1].Operations[logical.DeleteOperation])
+	assert.NotNil(t, paths[1].ExistenceCheck)
+	assert.NotNil(t, paths[2].Operations[logical.UpdateOperation])
+	assert.NotNil(t, paths[3].Operations[logical.ReadOperation])
+}
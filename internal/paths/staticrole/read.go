@@ -0,0 +1,31 @@
+package staticrole
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (p *Provider) pathStaticRolesRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	var roleName = data.Get("role_name").(string)
+
+	lock := p.b.LockForKey("static-role", roleName)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	role, err := p.b.GetStaticRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return logical.ErrorResponse("error reading static role"), err
+	}
+
+	if role == nil {
+		return nil, nil
+	}
+
+	p.b.Logger().Debug("Static role read", "role", roleName)
+
+	return &logical.Response{
+		Data: role.LogicalResponseData(false),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package staticrole_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathStaticRolesRead(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{}}
+		req := newRequest()
+		_, err := handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+
+		resp, err := handler(mb, 1, logical.ReadOperation)(t.Context(), req, fieldData(mb, 1, map[string]interface{}{"role_name": "static"}))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "static", resp.Data["role_name"])
+		assert.NotContains(t, resp.Data, "token")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{}
+		resp, err := handler(mb, 1, logical.ReadOperation)(t.Context(), newRequest(), fieldData(mb, 1, map[string]interface{}{"role_name": "static"}))
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{roleErr: errors.New("storage failure")}
+		resp, err := handler(mb, 1, logical.ReadOperation)(t.Context(), newRequest(), fieldData(mb, 1, map[string]interface{}{"role_name": "static"}))
+		require.Error(t, err)
+		require.True(t, resp.IsError())
+	})
+}
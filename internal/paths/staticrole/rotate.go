@@ -0,0 +1,221 @@
+package staticrole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+const pathStaticRoleRotateHelpSynopsis = `Rotate the token managed by the static role.`
+
+const pathStaticRoleRotateHelpDescription = `
+This endpoint rotates the GitLab token managed by the static role immediately, regardless of the rotation schedule.
+The new value can be read from the static-creds path.`
+
+const (
+	// staticRoleSaveAttempts is how many times a rotated token is stored before giving up.
+	staticRoleSaveAttempts = 3
+	// staticRoleSaveWait is the delay before the first retry of storing a rotated token, it grows with each attempt.
+	staticRoleSaveWait = 100 * time.Millisecond
+)
+
+func (p *Provider) pathStaticRoleRotate() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathStaticRoleRotateHelpSynopsis),
+		HelpDescription: strings.TrimSpace(pathStaticRoleRotateHelpDescription),
+		Pattern:         fmt.Sprintf("%s/%s/rotate$", backend.PathStaticRoleStorage, framework.GenericNameRegex("role_name")),
+		Fields: map[string]*framework.FieldSchema{
+			"role_name": FieldSchemaStaticRoles["role_name"],
+		},
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "static-role",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback:     p.pathStaticRoleRotateHandler,
+				DisplayAttrs: &framework.DisplayAttributes{OperationVerb: "rotate"},
+				Summary:      "Rotate the token of the static role.",
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+					}},
+				},
+			},
+		},
+	}
+}
+
+func (p *Provider) pathStaticRoleRotateHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	var roleName = data.Get("role_name").(string)
+
+	lock := p.b.LockForKey("static-role", roleName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	role, err := p.b.GetStaticRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return logical.ErrorResponse("error reading static role"), err
+	}
+
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("static role %s not found", roleName)), fmt.Errorf("static role %s: %w", roleName, errs.ErrNotFound)
+	}
+
+	var tokenId = role.TokenID
+	if err = p.rotateStaticRole(ctx, req.Storage, role); err != nil {
+		if role.TokenID != tokenId {
+			// the token was rotated but not stored, the new token id is needed to recover it
+			return logical.ErrorResponse("failed to store the rotated token_id %d", role.TokenID), err
+		}
+		return logical.ErrorResponse("failed to rotate the token"), err
+	}
+
+	return &logical.Response{Data: role.LogicalResponseData(false)}, nil
+}
+
+// rotateStaticRole rotates the token managed by the static role in GitLab and stores the new value.
+// The caller must hold the static role lock.
+func (p *Provider) rotateStaticRole(ctx context.Context, s logical.Storage, role *modelRole.StaticRole) (err error) {
+	var client gitlab.Client
+	var rotated *modelToken.Token
+	var startTime = utils.TimeFromContext(ctx).UTC()
+	var expiresAt time.Time
+
+	p.b.Logger().Debug("Rotating static role token", "role_name", role.RoleName, "token_type", role.TokenType.String(), "token_id", role.TokenID)
+
+	if client, err = p.b.GetClientByName(ctx, s, role.ConfigName); err != nil {
+		return err
+	}
+
+	_, expiresAt, _ = utils.CalculateGitlabTTL(role.TTL, startTime)
+
+	switch role.TokenType {
+	case token.TypePersonal, token.TypeUserServiceAccount:
+		var et *modelToken.TokenPersonal
+		if et, err = client.RotatePersonalAccessToken(ctx, role.Path, role.TokenID, expiresAt); err == nil {
+			rotated, role.Scopes = &et.Token, et.Scopes
+		}
+	case token.TypeProject:
+		var et *modelToken.TokenProject
+		if et, err = client.RotateProjectAccessToken(ctx, role.Path, role.TokenID, expiresAt); err == nil {
+			rotated, role.Scopes = &et.Token, et.Scopes
+		}
+	case token.TypeGroup:
+		var et *modelToken.TokenGroup
+		if et, err = client.RotateGroupAccessToken(ctx, role.Path, role.TokenID, expiresAt); err == nil {
+			rotated, role.Scopes = &et.Token, et.Scopes
+		}
+	case token.TypeGroupServiceAccount:
+		groupId, serviceAccount, _ := strings.Cut(role.Path, "/")
+		var userId int64
+		if userId, err = client.GetUserIdByUsername(ctx, serviceAccount); err == nil {
+			var et *modelToken.TokenGroupServiceAccount
+			if et, err = client.RotateGroupServiceAccountAccessToken(ctx, role.Path, groupId, userId, role.TokenID, expiresAt); err == nil {
+				rotated, role.Scopes = &et.Token, et.Scopes
+			}
+		}
+	case token.TypeProjectServiceAccount:
+		projectId, serviceAccount, _ := strings.Cut(role.Path, "/")
+		var userId int64
+		if userId, err = client.GetUserIdByUsername(ctx, serviceAccount); err == nil {
+			var et *modelToken.TokenProjectServiceAccount
+			if et, err = client.RotateProjectServiceAccountAccessToken(ctx, role.Path, projectId, userId, role.TokenID, expiresAt); err == nil {
+				rotated, role.Scopes = &et.Token, et.Scopes
+			}
+		}
+	default:
+		return fmt.Errorf("%s: %w", role.TokenType.String(), errs.ErrUnknownTokenType)
+	}
+
+	if err != nil {
+		p.b.Logger().Error("Failed to rotate static role token", "role_name", role.RoleName, "err", err)
+		return err
+	}
+
+	if rotated == nil {
+		return fmt.Errorf("%w: rotated token is nil", errs.ErrNilValue)
+	}
+
+	var previousTokenId = role.TokenID
+	role.TokenID = rotated.TokenID
+	role.Token = rotated.Token
+	role.Name = rotated.Name
+	role.TokenCreatedAt = rotated.GetCreatedAt()
+	role.TokenExpiresAt = rotated.GetExpiresAt()
+	role.LastRotatedAt = startTime
+
+	// GitLab already revoked the previous token, the new one is lost if it can't be stored
+	for attempt := 1; ; attempt++ {
+		if err = p.b.SaveStaticRole(ctx, s, role); err == nil || attempt == staticRoleSaveAttempts {
+			break
+		}
+		time.Sleep(time.Duration(attempt) * staticRoleSaveWait)
+	}
+	if err != nil {
+		p.b.Logger().Error("Failed to store the rotated static role token", "role_name", role.RoleName, "token_id", role.TokenID, "previous_token_id", previousTokenId, "err", err)
+		return fmt.Errorf("token %d was rotated to token %d which could not be stored, write token_id=%d to the static role to take ownership of it again: %w", previousTokenId, role.TokenID, role.TokenID, err)
+	}
+
+	_ = p.b.SendEvent(ctx, eventTokenRotate, map[string]string{
+		"path":              fmt.Sprintf("%s/%s", backend.PathStaticRoleStorage, role.RoleName),
+		"role_name":         role.RoleName,
+		"config_name":       role.ConfigName,
+		"token_type":        role.TokenType.String(),
+		"token_id":          strconv.FormatInt(role.TokenID, 10),
+		"previous_token_id": strconv.FormatInt(previousTokenId, 10),
+		"expires_at":        role.TokenExpiresAt.Format(time.RFC3339),
+	})
+
+	return nil
+}
+
+// PeriodicFunc implements backend.PeriodicHandler.
+// It rotates the tokens of all static roles that are due for rotation.
+func (p *Provider) PeriodicFunc(ctx context.Context, req *logical.Request) (err error) {
+	var roles []string
+	roles, err = req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathStaticRoleStorage))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range roles {
+		err = errors.Join(err, p.checkAndRotateStaticRole(ctx, req.Storage, name))
+	}
+
+	return err
+}
+
+func (p *Provider) checkAndRotateStaticRole(ctx context.Context, s logical.Storage, name string) error {
+	lock := p.b.LockForKey("static-role", name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	role, err := p.b.GetStaticRole(ctx, s, name)
+	if err != nil || role == nil {
+		return err
+	}
+
+	if !role.NeedsRotation(utils.TimeFromContext(ctx)) {
+		return nil
+	}
+
+	p.b.Logger().Debug("Trying to rotate the static role", "name", name)
+	return p.rotateStaticRole(ctx, s, role)
+}
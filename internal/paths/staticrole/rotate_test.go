@@ -0,0 +1,132 @@
+package staticrole_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	pathStaticRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/staticrole"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func TestPathStaticRoleRotate(t *testing.T) {
+	for _, tt := range []struct {
+		tokenType tk.Type
+		path      string
+	}{
+		{tk.TypePersonal, "user"},
+		{tk.TypeUserServiceAccount, "service_account_user"},
+		{tk.TypeProject, "group/project"},
+		{tk.TypeGroup, "group"},
+		{tk.TypeGroupServiceAccount, "265/service_account_group"},
+		{tk.TypeProjectServiceAccount, "412/service_account_project"},
+	} {
+		t.Run(tt.tokenType.String(), func(t *testing.T) {
+			client := &mockGitlabClient{}
+			mb := &mockStaticRoleBackend{config: testConfig(), client: client}
+			req := newRequest()
+
+			raw := staticRoleRaw()
+			raw["token_type"], raw["path"] = tt.tokenType.String(), tt.path
+			_, err := handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, raw))
+			require.NoError(t, err)
+
+			resp, err := handler(mb, 2, logical.UpdateOperation)(t.Context(), req, fieldData(mb, 2, map[string]interface{}{"role_name": "static"}))
+			require.NoError(t, err)
+			require.False(t, resp.IsError())
+			assert.EqualValues(t, 201, resp.Data["token_id"])
+			assert.Equal(t, 2, client.rotated)
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{}
+		resp, err := handler(mb, 2, logical.UpdateOperation)(t.Context(), newRequest(), fieldData(mb, 2, map[string]interface{}{"role_name": "static"}))
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		require.True(t, resp.IsError())
+	})
+
+	t.Run("storing the rotated token is retried", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{}}
+		storage := &failingStorage{}
+		req := &logical.Request{Storage: storage}
+		_, err := handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+
+		storage.failPuts = 2
+		resp, err := handler(mb, 2, logical.UpdateOperation)(t.Context(), req, fieldData(mb, 2, map[string]interface{}{"role_name": "static"}))
+		require.NoError(t, err)
+		require.False(t, resp.IsError())
+
+		role, err := mb.GetStaticRole(t.Context(), storage, "static")
+		require.NoError(t, err)
+		assert.EqualValues(t, 201, role.TokenID)
+	})
+
+	t.Run("storing the rotated token fails", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{}}
+		storage := &failingStorage{}
+		req := &logical.Request{Storage: storage}
+		_, err := handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+		mb.events = nil
+
+		storage.failPuts = 3
+		resp, err := handler(mb, 2, logical.UpdateOperation)(t.Context(), req, fieldData(mb, 2, map[string]interface{}{"role_name": "static"}))
+		require.ErrorContains(t, err, "write token_id=201 to the static role")
+		require.True(t, resp.IsError())
+		// the new token id is surfaced, so the operator can take ownership of the rotated token again
+		assert.Equal(t, "failed to store the rotated token_id 201", resp.Error().Error())
+		assert.Empty(t, mb.events)
+
+		role, err := mb.GetStaticRole(t.Context(), storage, "static")
+		require.NoError(t, err)
+		assert.EqualValues(t, 101, role.TokenID)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{}}
+		req := newRequest()
+		_, err := handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+
+		mb.clientErr = errors.New("client error")
+		resp, err := handler(mb, 2, logical.UpdateOperation)(t.Context(), req, fieldData(mb, 2, map[string]interface{}{"role_name": "static"}))
+		require.ErrorContains(t, err, "client error")
+		require.True(t, resp.IsError())
+	})
+}
+
+func TestPeriodicFunc(t *testing.T) {
+	client := &mockGitlabClient{}
+	mb := &mockStaticRoleBackend{config: testConfig(), client: client}
+	req := newRequest()
+	p := pathStaticRole.New(mb)
+
+	_, err := handler(mb, 1, logical.CreateOperation)(utils.WithStaticTime(t.Context(), testNow), req, fieldData(mb, 1, staticRoleRaw()))
+	require.NoError(t, err)
+	require.Equal(t, 1, client.rotated)
+
+	// not due yet
+	require.NoError(t, p.PeriodicFunc(utils.WithStaticTime(t.Context(), testNow.Add(167*time.Hour)), req))
+	assert.Equal(t, 1, client.rotated)
+
+	// due for rotation
+	require.NoError(t, p.PeriodicFunc(utils.WithStaticTime(t.Context(), testNow.Add(168*time.Hour)), req))
+	assert.Equal(t, 2, client.rotated)
+
+	role, err := mb.GetStaticRole(t.Context(), req.Storage, "static")
+	require.NoError(t, err)
+	assert.Equal(t, testNow.Add(168*time.Hour), role.LastRotatedAt)
+	assert.Equal(t, "glpat-rotated-2", role.Token)
+
+	// rotation errors are reported
+	client.rotateErr = errors.New("rotate failed")
+	require.ErrorContains(t, p.PeriodicFunc(utils.WithStaticTime(t.Context(), testNow.Add(400*time.Hour)), req), "rotate failed")
+}
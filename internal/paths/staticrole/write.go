@@ -0,0 +1,121 @@
+package staticrole
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func (p *Provider) pathStaticRolesWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	var roleName = data.Get("role_name").(string)
+
+	lock := p.b.LockForKey("static-role", roleName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	existing, err := p.b.GetStaticRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return logical.ErrorResponse("error reading static role"), err
+	}
+
+	var role = modelRole.StaticRole{RoleName: roleName, ConfigName: backend.DefaultConfigName}
+	if existing != nil {
+		role = *existing
+	}
+
+	if val, ok := data.GetOk("config_name"); ok {
+		role.ConfigName = cmp.Or(val.(string), backend.DefaultConfigName)
+	}
+	if val, ok := data.GetOk("token_type"); ok {
+		role.TokenType, _ = token.ParseType(val.(string))
+	}
+	if val, ok := data.GetOk("path"); ok {
+		role.Path = val.(string)
+	}
+	if val, ok := data.GetOk("token_id"); ok {
+		role.TokenID = val.(int64)
+	}
+	if val, ok := data.GetOk("ttl"); ok {
+		role.TTL = time.Duration(val.(int)) * time.Second
+	}
+	if val, ok := data.GetOk("rotation_period"); ok {
+		role.RotationPeriod = time.Duration(val.(int)) * time.Second
+	}
+
+	config, err := p.b.GetConfig(ctx, req.Storage, role.ConfigName)
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("missing %s configuration for gitlab", role.ConfigName)), err
+	}
+
+	if config == nil {
+		return logical.ErrorResponse(errs.ErrBackendNotConfigured.Error()), nil
+	}
+
+	if !slices.Contains(StaticTokenTypes, role.TokenType.String()) {
+		err = multierror.Append(err, fmt.Errorf("token_type='%s', should be one of %v: %w", role.TokenType, StaticTokenTypes, errs.ErrFieldInvalidValue))
+	} else if !token.IsValidPath(role.Path, role.TokenType) {
+		err = multierror.Append(err, fmt.Errorf("invalid path %s for token type %s: %w", role.Path, role.TokenType, errs.ErrInvalidValue))
+	}
+
+	if role.TokenID <= 0 {
+		err = multierror.Append(err, fmt.Errorf("token_id: %w", errs.ErrFieldRequired))
+	}
+
+	if role.TTL < backend.DefaultAccessTokenMinTTL || role.TTL > backend.DefaultAccessTokenMaxPossibleTTL {
+		err = multierror.Append(err, fmt.Errorf("ttl = %s [%s <= ttl <= %s]: %w", role.TTL, backend.DefaultAccessTokenMinTTL, backend.DefaultAccessTokenMaxPossibleTTL, errs.ErrInvalidValue))
+	}
+
+	if role.RotationPeriod < time.Hour || role.RotationPeriod > role.TTL {
+		err = multierror.Append(err, fmt.Errorf("rotation_period = %s [1h <= rotation_period <= ttl]: %w", role.RotationPeriod, errs.ErrInvalidValue))
+	}
+
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), err
+	}
+
+	// a new static role, or one that now points to a different token, has to take ownership of the
+	// token first, the only way to learn the value of an existing token is to rotate it
+	if existing == nil ||
+		existing.TokenID != role.TokenID ||
+		existing.TokenType != role.TokenType ||
+		existing.Path != role.Path ||
+		existing.ConfigName != role.ConfigName {
+		var tokenId = role.TokenID
+		if err = p.rotateStaticRole(ctx, req.Storage, &role); err != nil {
+			if role.TokenID != tokenId {
+				// the token was rotated but not stored, the new token id is needed to recover it
+				return logical.ErrorResponse("failed to store the rotated token_id %d", role.TokenID), err
+			}
+			return logical.ErrorResponse("failed to take ownership of the token"), err
+		}
+	} else if err = p.b.SaveStaticRole(ctx, req.Storage, &role); err != nil {
+		return nil, err
+	}
+
+	_ = p.b.SendEvent(ctx, eventWrite, map[string]string{
+		"path":        backend.PathStaticRoleStorage,
+		"role_name":   roleName,
+		"config_name": role.ConfigName,
+		"role_path":   role.Path,
+		"token_type":  role.TokenType.String(),
+		"token_id":    strconv.FormatInt(role.TokenID, 10),
+	})
+
+	p.b.Logger().Debug("Static role written", "role", roleName)
+
+	return &logical.Response{
+		Data: role.LogicalResponseData(false),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package staticrole_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func TestPathStaticRolesWrite(t *testing.T) {
+	t.Run("takes ownership of the token", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockStaticRoleBackend{config: testConfig(), client: client}
+		req := newRequest()
+		ctx := utils.WithStaticTime(t.Context(), testNow)
+
+		resp, err := handler(mb, 1, logical.CreateOperation)(ctx, req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.False(t, resp.IsError())
+		assert.NotContains(t, resp.Data, "token")
+		assert.EqualValues(t, 101, resp.Data["token_id"])
+		assert.Equal(t, 1, client.rotated)
+
+		require.Len(t, mb.events, 2)
+		assert.Equal(t, "static-role-token-rotate", mb.events[0].String())
+		assert.Equal(t, "1", mb.metadata[0]["previous_token_id"])
+		assert.Equal(t, "static-role-write", mb.events[1].String())
+
+		role, err := mb.GetStaticRole(ctx, req.Storage, "static")
+		require.NoError(t, err)
+		require.NotNil(t, role)
+		assert.Equal(t, "glpat-rotated-1", role.Token)
+		assert.Equal(t, testNow, role.LastRotatedAt)
+		assert.Equal(t, testNow.Add(7*24*time.Hour), role.NextRotation())
+	})
+
+	t.Run("updating the schedule does not rotate", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockStaticRoleBackend{config: testConfig(), client: client}
+		req := newRequest()
+		ctx := utils.WithStaticTime(t.Context(), testNow)
+
+		_, err := handler(mb, 1, logical.CreateOperation)(ctx, req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+
+		resp, err := handler(mb, 1, logical.UpdateOperation)(ctx, req, fieldData(mb, 1, map[string]interface{}{
+			"role_name":       "static",
+			"rotation_period": "24h",
+		}))
+		require.NoError(t, err)
+		require.False(t, resp.IsError())
+		assert.EqualValues(t, 24*3600, resp.Data["rotation_period"])
+		assert.EqualValues(t, 101, resp.Data["token_id"])
+		assert.Equal(t, 1, client.rotated)
+	})
+
+	t.Run("changing the token takes ownership again", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockStaticRoleBackend{config: testConfig(), client: client}
+		req := newRequest()
+
+		_, err := handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+
+		resp, err := handler(mb, 1, logical.UpdateOperation)(t.Context(), req, fieldData(mb, 1, map[string]interface{}{
+			"role_name": "static",
+			"token_id":  5,
+		}))
+		require.NoError(t, err)
+		require.False(t, resp.IsError())
+		assert.EqualValues(t, 105, resp.Data["token_id"])
+		assert.Equal(t, 2, client.rotated)
+	})
+
+	t.Run("rotation failure does not store the role", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{rotateErr: errors.New("rotate failed")}}
+		req := newRequest()
+
+		resp, err := handler(mb, 1, logical.CreateOperation)(t.Context(), req, fieldData(mb, 1, staticRoleRaw()))
+		require.ErrorContains(t, err, "rotate failed")
+		require.True(t, resp.IsError())
+
+		role, err := mb.GetStaticRole(t.Context(), req.Storage, "static")
+		require.NoError(t, err)
+		assert.Nil(t, role)
+	})
+
+	t.Run("config not found", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{}
+		resp, err := handler(mb, 1, logical.CreateOperation)(t.Context(), newRequest(), fieldData(mb, 1, staticRoleRaw()))
+		require.NoError(t, err)
+		require.True(t, resp.IsError())
+	})
+
+	t.Run("config error", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{configErr: errors.New("config error")}
+		resp, err := handler(mb, 1, logical.CreateOperation)(t.Context(), newRequest(), fieldData(mb, 1, staticRoleRaw()))
+		require.Error(t, err)
+		require.True(t, resp.IsError())
+	})
+
+	t.Run("role error", func(t *testing.T) {
+		mb := &mockStaticRoleBackend{roleErr: errors.New("storage failure")}
+		resp, err := handler(mb, 1, logical.CreateOperation)(t.Context(), newRequest(), fieldData(mb, 1, staticRoleRaw()))
+		require.Error(t, err)
+		require.True(t, resp.IsError())
+	})
+}
+
+func TestPathStaticRolesWrite_ValidationErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		field       string
+		value       any
+		errContains string
+	}{
+		{"unsupported token type", "token_type", "project-deploy", "token_type"},
+		{"invalid path", "path", "group/sa/extra", "invalid path"},
+		{"missing token id", "token_id", 0, "token_id"},
+		{"ttl too short", "ttl", "23h", "ttl"},
+		{"ttl too long", "ttl", "8761h", "ttl"},
+		{"rotation period too short", "rotation_period", "59m", "rotation_period"},
+		{"rotation period longer than ttl", "rotation_period", "721h", "rotation_period"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := staticRoleRaw()
+			raw[tt.field] = tt.value
+			if tt.field == "path" {
+				raw["token_type"] = "group-service-account"
+			}
+			mb := &mockStaticRoleBackend{config: testConfig(), client: &mockGitlabClient{}}
+			resp, err := handler(mb, 1, logical.CreateOperation)(t.Context(), newRequest(), fieldData(mb, 1, raw))
+			require.ErrorContains(t, err, tt.errContains)
+			require.True(t, resp.IsError())
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package sweep
+
+import "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+
+var (
+	eventSweep  = event.MustEventType("token-sweep")
+	eventRevoke = event.MustEventType("token-revoke")
+)
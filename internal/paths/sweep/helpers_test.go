@@ -0,0 +1,167 @@
+package sweep_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	modelIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	modelRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	pathSweep "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/sweep"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mockSweepBackend is a hand-written mock satisfying the sweepBackend interface,
+// everything is persisted in the request storage.
+type mockSweepBackend struct {
+	client      gitlab.Client
+	clientErr   error
+	issuedSince time.Time
+	events      []event.EventType
+	metadata    []map[string]string
+}
+
+func (m *mockSweepBackend) Logger() hclog.Logger { return hclog.NewNullLogger() }
+func (m *mockSweepBackend) GetClientByName(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+	return m.client, m.clientErr
+}
+func (m *mockSweepBackend) GetConfig(ctx context.Context, s logical.Storage, name string) (*modelConfig.EntryConfig, error) {
+	return model.Get[modelConfig.EntryConfig](ctx, s, fmt.Sprintf("%s/%s", backend.PathConfigStorage, name))
+}
+func (m *mockSweepBackend) SaveConfig(ctx context.Context, s logical.Storage, cfg *modelConfig.EntryConfig) error {
+	return model.Save(ctx, s, backend.PathConfigStorage, cfg)
+}
+func (m *mockSweepBackend) GetRole(ctx context.Context, s logical.Storage, name string) (*modelRole.Role, error) {
+	return model.Get[modelRole.Role](ctx, s, fmt.Sprintf("%s/%s", backend.PathRoleStorage, name))
+}
+func (m *mockSweepBackend) GetStaticRole(ctx context.Context, s logical.Storage, name string) (*modelRole.StaticRole, error) {
+	return model.Get[modelRole.StaticRole](ctx, s, fmt.Sprintf("%s/%s", backend.PathStaticRoleStorage, name))
+}
+func (m *mockSweepBackend) SaveStaticRole(ctx context.Context, s logical.Storage, r *modelRole.StaticRole) error {
+	return model.Save(ctx, s, backend.PathStaticRoleStorage, r)
+}
+func (m *mockSweepBackend) GetIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) (*modelIssued.Token, error) {
+	return model.Get[modelIssued.Token](ctx, s, fmt.Sprintf("%s/%s/%s/%d", backend.PathIssuedStorage, configName, roleName, tokenId))
+}
+func (m *mockSweepBackend) SaveIssuedToken(ctx context.Context, s logical.Storage, t *modelIssued.Token) error {
+	return model.Save(ctx, s, fmt.Sprintf("%s/%s/%s", backend.PathIssuedStorage, t.ConfigName, t.RoleName), t)
+}
+func (m *mockSweepBackend) DeleteIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s/%s/%d", backend.PathIssuedStorage, configName, roleName, tokenId))
+}
+func (m *mockSweepBackend) IssuedSince(_ context.Context, _ logical.Storage) (time.Time, error) {
+	return m.issuedSince, nil
+}
+func (m *mockSweepBackend) GetDeferredRevocation(ctx context.Context, s logical.Storage, name string) (*modelRevocation.Entry, error) {
+	return model.Get[modelRevocation.Entry](ctx, s, fmt.Sprintf("%s/%s", backend.PathRevocationQueueStorage, name))
+}
+func (m *mockSweepBackend) SaveDeferredRevocation(ctx context.Context, s logical.Storage, e *modelRevocation.Entry) error {
+	return model.Save(ctx, s, backend.PathRevocationQueueStorage, e)
+}
+func (m *mockSweepBackend) DeleteDeferredRevocation(ctx context.Context, s logical.Storage, name string) error {
+	return model.Delete(ctx, s, fmt.Sprintf("%s/%s", backend.PathRevocationQueueStorage, name))
+}
+func (m *mockSweepBackend) SendEvent(_ context.Context, eventType event.EventType, metadata map[string]string) error {
+	m.events = append(m.events, eventType)
+	m.metadata = append(m.metadata, metadata)
+	return nil
+}
+
+// mockGitlabClient implements the list and revoke methods used by the tests.
+type mockGitlabClient struct {
+	gitlab.Client
+	personal  []*modelToken.TokenPersonal
+	project   []*modelToken.TokenProject
+	group     []*modelToken.TokenGroup
+	listErr   error
+	revokeErr error
+	revoked   []int64
+}
+
+func (m *mockGitlabClient) GetUserIdByUsername(_ context.Context, _ string) (int64, error) {
+	return 1, nil
+}
+func (m *mockGitlabClient) ListPersonalAccessTokens(_ context.Context, _ string, _ int64) ([]*modelToken.TokenPersonal, error) {
+	return m.personal, m.listErr
+}
+func (m *mockGitlabClient) ListProjectAccessTokens(_ context.Context, _ string) ([]*modelToken.TokenProject, error) {
+	return m.project, m.listErr
+}
+func (m *mockGitlabClient) ListGroupAccessTokens(_ context.Context, _ string) ([]*modelToken.TokenGroup, error) {
+	return m.group, m.listErr
+}
+func (m *mockGitlabClient) RevokePersonalAccessToken(_ context.Context, tokenId int64) error {
+	return m.revoke(tokenId)
+}
+func (m *mockGitlabClient) RevokeProjectAccessToken(_ context.Context, tokenId int64, _ string) error {
+	return m.revoke(tokenId)
+}
+func (m *mockGitlabClient) RevokeGroupAccessToken(_ context.Context, tokenId int64, _ string) error {
+	return m.revoke(tokenId)
+}
+func (m *mockGitlabClient) revoke(tokenId int64) error {
+	if m.revokeErr != nil {
+		return m.revokeErr
+	}
+	m.revoked = append(m.revoked, tokenId)
+	return nil
+}
+
+func projectToken(id int64, name string, createdAt time.Time) *modelToken.TokenProject {
+	return &modelToken.TokenProject{
+		TokenWithScopesAndAccessLevel: modelToken.TokenWithScopesAndAccessLevel{
+			Token: modelToken.Token{
+				TokenID:   id,
+				ParentID:  "group/project",
+				Path:      "group/project",
+				Name:      name,
+				TokenType: tk.TypeProject,
+				CreatedAt: &createdAt,
+			},
+		},
+	}
+}
+
+func saveRole(ctx context.Context, s logical.Storage, role *modelRole.Role) error {
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf("%s/%s", backend.PathRoleStorage, role.RoleName), role)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func projectRole(name string) *modelRole.Role {
+	return &modelRole.Role{
+		RoleName:   name,
+		Path:       "group/project",
+		Name:       "vault-{{ .role_name }}-{{ randHexString 4 }}",
+		TokenType:  tk.TypeProject,
+		ConfigName: "default",
+	}
+}
+
+func handler(mb *mockSweepBackend, idx int, op logical.Operation) framework.OperationFunc {
+	return pathSweep.New(mb).Paths()[idx].Operations[op].Handler()
+}
+
+func fieldData(mb *mockSweepBackend, idx int, raw map[string]interface{}) *framework.FieldData {
+	return &framework.FieldData{Raw: raw, Schema: pathSweep.New(mb).Paths()[idx].Fields}
+}
+
+// newRequest creates a minimal logical.Request with in-memory storage.
+func newRequest() *logical.Request {
+	return &logical.Request{Storage: &logical.InmemStorage{}}
+}
@@ -0,0 +1,112 @@
+package sweep
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+)
+
+const (
+	// PathSweep is the path used to look for orphaned tokens in GitLab.
+	PathSweep = "sweep"
+
+	// GracePeriod is how old a token in GitLab must be before it can be considered orphaned,
+	// it covers tokens that are still being issued and the rollback of failed issues.
+	GracePeriod = time.Hour
+
+	pathSweepHelpSyn  = `Find tokens in GitLab that were created by the plugin but are no longer tracked by Vault.`
+	pathSweepHelpDesc = `
+This path lists the personal, project and group access tokens in GitLab for every role that uses the
+configuration and matches them against the name template of the role. Tokens that match but have no issued
+token record, are not waiting in the revocation queue and are not owned by a static role or the configuration
+itself are reported as orphaned. With revoke=true the orphaned tokens are also revoked in GitLab.`
+)
+
+var (
+	FieldSchemaSweep = map[string]*framework.FieldSchema{
+		"config_name": {
+			Type:        framework.TypeString,
+			Description: "The configuration to sweep.",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Config name",
+			},
+		},
+		"revoke": {
+			Type:        framework.TypeBool,
+			Default:     false,
+			Description: "Revoke the orphaned tokens in GitLab, otherwise they are only reported.",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Name: "Revoke",
+			},
+		},
+	}
+)
+
+// sweepBackend defines the narrow interface this provider needs.
+type sweepBackend interface {
+	backend.Logging
+	backend.ConfigStore
+	backend.RoleStore
+	backend.StaticRoleStore
+	backend.IssuedTokenStore
+	backend.IssuedSinceReader
+	backend.RevocationQueueStore
+	backend.ClientReader
+	backend.EventSender
+}
+
+// Provider implements backend.PathProvider and backend.PeriodicHandler for the orphaned token sweeper.
+type Provider struct {
+	b sweepBackend
+
+	mu        sync.Mutex
+	lastSweep map[string]time.Time
+}
+
+func (p *Provider) Name() string { return "sweep" }
+
+// New creates a new sweep path provider.
+func New(b sweepBackend) *Provider {
+	return &Provider{b: b, lastSweep: make(map[string]time.Time)}
+}
+
+// Paths returns all sweep related framework paths.
+func (p *Provider) Paths() []*framework.Path {
+	return []*framework.Path{
+		p.pathSweep(),
+	}
+}
+
+func (p *Provider) pathSweep() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathSweepHelpSyn),
+		HelpDescription: strings.TrimSpace(pathSweepHelpDesc),
+		Pattern:         fmt.Sprintf("%s/%s$", PathSweep, framework.GenericNameRegex("config_name")),
+		Fields:          FieldSchemaSweep,
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "orphaned-tokens",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback:     p.pathSweepWrite,
+				DisplayAttrs: &framework.DisplayAttributes{OperationVerb: "sweep"},
+				Summary:      "Report, and optionally revoke, the orphaned tokens in GitLab.",
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+					}},
+				},
+			},
+		},
+	}
+}
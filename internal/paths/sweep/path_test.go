@@ -0,0 +1,30 @@
+package sweep_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	pathSweep "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/sweep"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := pathSweep.New(&mockSweepBackend{})
+	assert.Equal(t, "sweep", p.Name())
+}
+
+func TestProvider_Paths(t *testing.T) {
+	p := pathSweep.New(&mockSweepBackend{})
+	paths := p.Paths()
+	require.Len(t, paths, 1)
+	assert.NotNil(t, paths[0].Operations[logical.UpdateOperation])
+}
+
+func TestProvider_PeriodicHandler(t *testing.T) {
+	var p any = pathSweep.New(&mockSweepBackend{})
+	_, ok := p.(backend.PeriodicHandler)
+	assert.True(t, ok)
+}
@@ -0,0 +1,60 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// PeriodicFunc implements backend.PeriodicHandler.
+// It sweeps every configuration that has a sweep_interval once the interval has passed since the last sweep.
+// The time of the last sweep is kept in memory, so the first periodic run after a restart sweeps right away.
+func (p *Provider) PeriodicFunc(ctx context.Context, req *logical.Request) (err error) {
+	var now = utils.TimeFromContext(ctx).UTC()
+	var configs []string
+	if configs, err = req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathConfigStorage)); err != nil {
+		return err
+	}
+
+	for _, name := range configs {
+		config, cfgErr := p.b.GetConfig(ctx, req.Storage, name)
+		if cfgErr != nil {
+			err = errors.Join(err, cfgErr)
+			continue
+		}
+
+		if config == nil || config.SweepInterval <= 0 || !p.due(name, now, config.SweepInterval) {
+			continue
+		}
+
+		p.b.Logger().Debug("Sweeping orphaned tokens", "config_name", name, "revoke", config.SweepRevoke)
+		orphans, sweepErr := p.sweep(ctx, req.Storage, name, config.SweepRevoke)
+		if sweepErr != nil {
+			err = errors.Join(err, sweepErr)
+			continue
+		}
+
+		p.mu.Lock()
+		p.lastSweep[name] = now
+		p.mu.Unlock()
+
+		if len(orphans) > 0 {
+			p.b.Logger().Warn("Found orphaned tokens in GitLab", "config_name", name, "orphans", len(orphans), "revoke", config.SweepRevoke)
+		}
+	}
+
+	return err
+}
+
+func (p *Provider) due(name string, now time.Time, interval time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	last, ok := p.lastSweep[name]
+	return !ok || !now.Before(last.Add(interval))
+}
@@ -0,0 +1,57 @@
+package sweep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	pathSweep "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/sweep"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func TestPeriodicFunc(t *testing.T) {
+	var ctx = utils.WithStaticTime(t.Context(), testNow)
+
+	t.Run("disabled", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+
+		require.NoError(t, pathSweep.New(mb).PeriodicFunc(ctx, req))
+		assert.Empty(t, client.revoked)
+		assert.Empty(t, mb.events)
+	})
+
+	t.Run("sweeps once per interval", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+		require.NoError(t, mb.SaveConfig(ctx, req.Storage, &modelConfig.EntryConfig{Name: "default", TokenId: 99, SweepInterval: time.Hour, SweepRevoke: true}))
+
+		p := pathSweep.New(mb)
+		require.NoError(t, p.PeriodicFunc(ctx, req))
+		assert.Equal(t, []int64{10}, client.revoked)
+
+		require.NoError(t, p.PeriodicFunc(utils.WithStaticTime(t.Context(), testNow.Add(30*time.Minute)), req))
+		assert.Equal(t, []int64{10}, client.revoked)
+
+		// the mock keeps listing revoked tokens, and token 13 is now past the grace period
+		require.NoError(t, p.PeriodicFunc(utils.WithStaticTime(t.Context(), testNow.Add(time.Hour)), req))
+		assert.Equal(t, []int64{10, 10, 13}, client.revoked)
+	})
+
+	t.Run("report only", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+		require.NoError(t, mb.SaveConfig(ctx, req.Storage, &modelConfig.EntryConfig{Name: "default", TokenId: 99, SweepInterval: time.Hour}))
+
+		require.NoError(t, pathSweep.New(mb).PeriodicFunc(ctx, req))
+		assert.Empty(t, client.revoked)
+		require.Len(t, mb.events, 1)
+		assert.Equal(t, "token-sweep", mb.events[0].String())
+	})
+}
@@ -0,0 +1,284 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// SweepTokenTypes are the token types that can be listed in GitLab and are looked at by the sweeper.
+var SweepTokenTypes = gitlab.ListableTokenTypes
+
+// orphan is a token found in GitLab that matches a role but is not tracked by Vault.
+type orphan struct {
+	entry     *modelRevocation.Entry
+	expiresAt time.Time
+	revoked   bool
+	err       error
+}
+
+func (o orphan) LogicalResponseData() map[string]any {
+	var expiresAt, lastError = "", ""
+	if !o.expiresAt.IsZero() {
+		expiresAt = o.expiresAt.Format(time.RFC3339)
+	}
+	if o.err != nil {
+		lastError = o.err.Error()
+	}
+	return map[string]any{
+		"role_name":  o.entry.RoleName,
+		"token_id":   o.entry.TokenID,
+		"token_type": o.entry.TokenType.String(),
+		"path":       o.entry.Path,
+		"name":       o.entry.Name,
+		"created_at": o.entry.CreatedAt.Format(time.RFC3339),
+		"expires_at": expiresAt,
+		"revoked":    o.revoked,
+		"error":      lastError,
+	}
+}
+
+// target is an owner of tokens in GitLab together with the roles that create tokens for it.
+type target struct {
+	tokenType token.Type
+	path      string
+	roles     []*modelRole.Role
+	names     []*regexp.Regexp
+}
+
+func (p *Provider) pathSweepWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	var configName = data.Get("config_name").(string)
+	var revoke = data.Get("revoke").(bool)
+
+	orphans, err := p.sweep(ctx, req.Storage, configName, revoke)
+	if err != nil {
+		if errors.Is(err, errs.ErrBackendNotConfigured) {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		return logical.ErrorResponse("failed to sweep the orphaned tokens"), err
+	}
+
+	var tokens = make([]map[string]any, 0, len(orphans))
+	for _, o := range orphans {
+		tokens = append(tokens, o.LogicalResponseData())
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			"config_name": configName,
+			"revoke":      revoke,
+			"orphans":     tokens,
+		},
+	}, nil
+}
+
+// sweep finds the orphaned tokens for the configuration and revokes them if asked to. Failures to revoke a single
+// token are reported on the token and do not stop the sweep.
+func (p *Provider) sweep(ctx context.Context, s logical.Storage, configName string, revoke bool) (orphans []*orphan, err error) {
+	var now = utils.TimeFromContext(ctx).UTC()
+
+	config, err := p.b.GetConfig(ctx, s, configName)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, fmt.Errorf("%s: %w", configName, errs.ErrBackendNotConfigured)
+	}
+
+	var client gitlab.Client
+	if client, err = p.b.GetClientByName(ctx, s, configName); err != nil {
+		return nil, err
+	}
+
+	var targets []*target
+	if targets, err = p.targets(ctx, s, configName); err != nil {
+		return nil, err
+	}
+
+	var owned []int64
+	if owned, err = p.ownedTokenIds(ctx, s, configName); err != nil {
+		return nil, err
+	}
+	owned = append(owned, config.TokenId)
+
+	// the tokens created before the inventory started, or while nothing has been recorded yet, may have a valid lease
+	// without an issued token record
+	var issuedSince time.Time
+	if issuedSince, err = p.b.IssuedSince(ctx, s); err != nil {
+		return nil, err
+	}
+
+	for _, tgt := range targets {
+		var tokens []*modelToken.Token
+		if tokens, err = gitlab.ListTokens(ctx, client, tgt.tokenType, tgt.path); err != nil {
+			return orphans, fmt.Errorf("listing %s tokens of %s: %w", tgt.tokenType, tgt.path, err)
+		}
+
+		for _, tok := range tokens {
+			if now.Sub(tok.GetCreatedAt()) < GracePeriod || slices.Contains(owned, tok.TokenID) {
+				continue
+			}
+			if issuedSince.IsZero() || tok.GetCreatedAt().Before(issuedSince) {
+				continue
+			}
+
+			var o *orphan
+			if o, err = p.checkToken(ctx, s, configName, tgt, tok); err != nil {
+				return orphans, err
+			}
+			if o != nil {
+				orphans = append(orphans, o)
+			}
+		}
+	}
+
+	for _, o := range orphans {
+		if !revoke {
+			continue
+		}
+
+		o.err = secret.RevokeToken(ctx, client, o.entry)
+		if errors.Is(o.err, errs.ErrAccessTokenNotFound) {
+			o.err = nil
+		}
+		o.revoked = o.err == nil
+		if !o.revoked {
+			p.b.Logger().Warn("Failed to revoke orphaned token", "config_name", configName, "token_id", o.entry.TokenID, "err", o.err)
+			continue
+		}
+
+		_ = p.b.SendEvent(ctx, eventRevoke, map[string]string{
+			"path":        o.entry.Path,
+			"name":        o.entry.Name,
+			"role_name":   o.entry.RoleName,
+			"token_id":    strconv.FormatInt(o.entry.TokenID, 10),
+			"token_type":  o.entry.TokenType.String(),
+			"config_name": configName,
+			"swept":       "true",
+		})
+	}
+
+	_ = p.b.SendEvent(ctx, eventSweep, map[string]string{
+		"config_name": configName,
+		"orphans":     strconv.Itoa(len(orphans)),
+		"revoke":      strconv.FormatBool(revoke),
+	})
+
+	p.b.Logger().Debug("Swept orphaned tokens", "config_name", configName, "orphans", len(orphans), "revoke", revoke)
+	return orphans, nil
+}
+
+// checkToken returns the token as an orphan if it matches the name template of a role and Vault has no record of it.
+func (p *Provider) checkToken(ctx context.Context, s logical.Storage, configName string, tgt *target, tok *modelToken.Token) (o *orphan, err error) {
+	for idx, rx := range tgt.names {
+		if !rx.MatchString(tok.Name) {
+			continue
+		}
+
+		var role = tgt.roles[idx]
+		if issued, err := p.b.GetIssuedToken(ctx, s, configName, role.RoleName, tok.TokenID); err != nil || issued != nil {
+			return nil, err
+		}
+
+		if o == nil {
+			o = &orphan{
+				entry: &modelRevocation.Entry{
+					ConfigName: configName,
+					RoleName:   role.RoleName,
+					TokenID:    tok.TokenID,
+					TokenType:  tok.TokenType,
+					ParentID:   tok.ParentID,
+					Path:       tok.Path,
+					Name:       tok.Name,
+					CreatedAt:  tok.GetCreatedAt(),
+				},
+				expiresAt: tok.GetExpiresAt(),
+			}
+		}
+	}
+
+	if o == nil {
+		return nil, nil
+	}
+
+	// a queued revocation is already taking care of the token
+	if deferred, err := p.b.GetDeferredRevocation(ctx, s, o.entry.GetName()); err != nil || deferred != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// targets returns the token owners in GitLab for all roles of the configuration that can be swept.
+func (p *Provider) targets(ctx context.Context, s logical.Storage, configName string) (targets []*target, err error) {
+	var names []string
+	if names, err = s.List(ctx, fmt.Sprintf("%s/", backend.PathRoleStorage)); err != nil {
+		return nil, err
+	}
+
+	var byOwner = make(map[string]*target)
+	for _, name := range names {
+		var role *modelRole.Role
+		if role, err = p.b.GetRole(ctx, s, name); err != nil {
+			return nil, err
+		}
+
+		// roles with a dynamic path have a regular expression as the path, the owners can't be listed
+		if role == nil || role.ConfigName != configName || role.DynamicPath || !slices.Contains(SweepTokenTypes, role.TokenType) {
+			continue
+		}
+
+		rx, rxErr := utils.TokenNameRegexp(role)
+		if rxErr != nil {
+			p.b.Logger().Debug("Skipping role for the sweep", "role_name", role.RoleName, "err", rxErr)
+			continue
+		}
+
+		var key = fmt.Sprintf("%s/%s", role.TokenType, role.Path)
+		if _, ok := byOwner[key]; !ok {
+			byOwner[key] = &target{tokenType: role.TokenType, path: role.Path}
+			targets = append(targets, byOwner[key])
+		}
+		byOwner[key].roles = append(byOwner[key].roles, role)
+		byOwner[key].names = append(byOwner[key].names, rx)
+	}
+
+	return targets, nil
+}
+
+// ownedTokenIds returns the IDs of the tokens managed by the static roles of the configuration.
+func (p *Provider) ownedTokenIds(ctx context.Context, s logical.Storage, configName string) (ids []int64, err error) {
+	var names []string
+	if names, err = s.List(ctx, fmt.Sprintf("%s/", backend.PathStaticRoleStorage)); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		var role *modelRole.StaticRole
+		if role, err = p.b.GetStaticRole(ctx, s, name); err != nil {
+			return nil, err
+		}
+		if role != nil && role.ConfigName == configName {
+			ids = append(ids, role.TokenID)
+		}
+	}
+
+	return ids, nil
+}
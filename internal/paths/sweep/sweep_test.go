@@ -0,0 +1,185 @@
+package sweep_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	modelConfig "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/config"
+	modelIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	modelRevocation "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// setupSweep stores a config with a role and a set of tokens in GitLab of which only token 10 is orphaned.
+func setupSweep(t *testing.T, ctx context.Context, mb *mockSweepBackend, s logical.Storage) *mockGitlabClient {
+	t.Helper()
+	var old = testNow.Add(-24 * time.Hour)
+	mb.issuedSince = old.Add(-time.Hour)
+
+	require.NoError(t, mb.SaveConfig(ctx, s, &modelConfig.EntryConfig{Name: "default", TokenId: 99}))
+	require.NoError(t, saveRole(ctx, s, projectRole("ci")))
+
+	var otherConfig = projectRole("other")
+	otherConfig.ConfigName = "other"
+	require.NoError(t, saveRole(ctx, s, otherConfig))
+
+	var dynamic = projectRole("dynamic")
+	dynamic.DynamicPath = true
+	dynamic.Path = "group/.*"
+	require.NoError(t, saveRole(ctx, s, dynamic))
+
+	require.NoError(t, mb.SaveStaticRole(ctx, s, &modelRole.StaticRole{RoleName: "static", ConfigName: "default", TokenID: 50}))
+	require.NoError(t, mb.SaveIssuedToken(ctx, s, &modelIssued.Token{ConfigName: "default", RoleName: "ci", TokenID: 11}))
+	require.NoError(t, mb.SaveDeferredRevocation(ctx, s, &modelRevocation.Entry{ConfigName: "default", TokenType: tk.TypeProject, TokenID: 12}))
+
+	client := &mockGitlabClient{
+		project: []*modelToken.TokenProject{
+			projectToken(10, "vault-ci-abcdabcd", old),
+			projectToken(11, "vault-ci-11111111", old),
+			projectToken(12, "vault-ci-12121212", old),
+			projectToken(13, "vault-ci-13131313", testNow.Add(-time.Minute)),
+			projectToken(14, "manually-created", old),
+			projectToken(50, "vault-ci-50505050", old),
+			projectToken(99, "vault-ci-99999999", old),
+		},
+	}
+	mb.client = client
+	return client
+}
+
+func TestPathSweep(t *testing.T) {
+	var ctx = utils.WithStaticTime(t.Context(), testNow)
+
+	t.Run("not configured", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		resp, err := handler(mb, 0, logical.UpdateOperation)(ctx, newRequest(), fieldData(mb, 0, map[string]interface{}{"config_name": "default"}))
+		require.NoError(t, err)
+		require.True(t, resp.IsError())
+	})
+
+	t.Run("report only", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+
+		resp, err := handler(mb, 0, logical.UpdateOperation)(ctx, req, fieldData(mb, 0, map[string]interface{}{"config_name": "default"}))
+		require.NoError(t, err)
+		require.False(t, resp.IsError())
+
+		orphans := resp.Data["orphans"].([]map[string]any)
+		require.Len(t, orphans, 1)
+		assert.EqualValues(t, 10, orphans[0]["token_id"])
+		assert.Equal(t, "ci", orphans[0]["role_name"])
+		assert.False(t, orphans[0]["revoked"].(bool))
+		assert.Empty(t, client.revoked)
+
+		require.Len(t, mb.events, 1)
+		assert.Equal(t, "token-sweep", mb.events[0].String())
+		assert.Equal(t, "1", mb.metadata[0]["orphans"])
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+
+		resp, err := handler(mb, 0, logical.UpdateOperation)(ctx, req, fieldData(mb, 0, map[string]interface{}{"config_name": "default", "revoke": true}))
+		require.NoError(t, err)
+		require.False(t, resp.IsError())
+
+		orphans := resp.Data["orphans"].([]map[string]any)
+		require.Len(t, orphans, 1)
+		assert.True(t, orphans[0]["revoked"].(bool))
+		assert.Equal(t, []int64{10}, client.revoked)
+
+		require.Len(t, mb.events, 2)
+		assert.Equal(t, "token-revoke", mb.events[0].String())
+		assert.Equal(t, "true", mb.metadata[0]["swept"])
+		assert.Equal(t, "token-sweep", mb.events[1].String())
+	})
+
+	t.Run("revoke fails", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+		client.revokeErr = errors.New("gitlab unavailable")
+
+		resp, err := handler(mb, 0, logical.UpdateOperation)(ctx, req, fieldData(mb, 0, map[string]interface{}{"config_name": "default", "revoke": true}))
+		require.NoError(t, err)
+
+		orphans := resp.Data["orphans"].([]map[string]any)
+		require.Len(t, orphans, 1)
+		assert.False(t, orphans[0]["revoked"].(bool))
+		assert.Equal(t, "gitlab unavailable", orphans[0]["error"])
+	})
+
+	t.Run("already revoked", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+		client.revokeErr = fmt.Errorf("project: %w", errs.ErrAccessTokenNotFound)
+
+		resp, err := handler(mb, 0, logical.UpdateOperation)(ctx, req, fieldData(mb, 0, map[string]interface{}{"config_name": "default", "revoke": true}))
+		require.NoError(t, err)
+
+		orphans := resp.Data["orphans"].([]map[string]any)
+		require.Len(t, orphans, 1)
+		assert.True(t, orphans[0]["revoked"].(bool))
+	})
+
+	t.Run("tokens created before the inventory", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+		mb.issuedSince = testNow.Add(-2 * time.Hour)
+
+		resp, err := handler(mb, 0, logical.UpdateOperation)(ctx, req, fieldData(mb, 0, map[string]interface{}{"config_name": "default", "revoke": true}))
+		require.NoError(t, err)
+		assert.Empty(t, resp.Data["orphans"])
+		assert.Empty(t, client.revoked)
+	})
+
+	t.Run("inventory not started", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+		mb.issuedSince = time.Time{}
+
+		resp, err := handler(mb, 0, logical.UpdateOperation)(ctx, req, fieldData(mb, 0, map[string]interface{}{"config_name": "default", "revoke": true}))
+		require.NoError(t, err)
+		assert.Empty(t, resp.Data["orphans"])
+		assert.Empty(t, client.revoked)
+	})
+
+	t.Run("listing fails", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		client := setupSweep(t, ctx, mb, req.Storage)
+		client.listErr = errors.New("gitlab unavailable")
+
+		resp, err := handler(mb, 0, logical.UpdateOperation)(ctx, req, fieldData(mb, 0, map[string]interface{}{"config_name": "default"}))
+		require.Error(t, err)
+		require.True(t, resp.IsError())
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mb := &mockSweepBackend{}
+		req := newRequest()
+		setupSweep(t, ctx, mb, req.Storage)
+		mb.clientErr = errors.New("no client")
+
+		_, err := handler(mb, 0, logical.UpdateOperation)(ctx, req, fieldData(mb, 0, map[string]interface{}{"config_name": "default"}))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,287 @@
+package token
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+const (
+	// MaxBatchPaths is the most paths a single batch request can create tokens for.
+	MaxBatchPaths = 100
+
+	// BatchConcurrency is the number of tokens that are created in GitLab at the same time for a config, shared by
+	// all the batch requests that use the config.
+	BatchConcurrency = 5
+
+	// pathTokenBatch is the last segment of the batch path, it can't be used as the dynamic path of a role.
+	pathTokenBatch = "batch"
+
+	pathTokenRolesBatchHelpSyn  = `Generate access tokens for multiple paths of a role with a dynamic path`
+	pathTokenRolesBatchHelpDesc = `
+This path creates a token for each of the requested paths of a role with a dynamic path, and returns them under a
+single lease. The tokens are created concurrently, if any of them fails the tokens that were already created are
+revoked and nothing is returned.`
+)
+
+// FieldSchemaTokenRoleBatch defines the fields of a batch request, the ttl, scopes and access_level narrow every
+// token of the batch.
+var FieldSchemaTokenRoleBatch = map[string]*framework.FieldSchema{
+	"role_name": FieldSchemaTokenRole["role_name"],
+	"paths": {
+		Type:        framework.TypeCommaStringSlice,
+		Description: "The paths to create tokens for, each has to match the dynamic path of the role",
+		Required:    true,
+	},
+	"ttl":          FieldSchemaTokenRole["ttl"],
+	"scopes":       FieldSchemaTokenRole["scopes"],
+	"access_level": FieldSchemaTokenRole["access_level"],
+}
+
+func (p *Provider) pathTokenRolesBatch() *framework.Path {
+	return &framework.Path{
+		HelpSynopsis:    strings.TrimSpace(pathTokenRolesBatchHelpSyn),
+		HelpDescription: strings.TrimSpace(pathTokenRolesBatchHelpDesc),
+		Pattern:         fmt.Sprintf("%s/%s/%s$", PathTokenRoleStorage, framework.GenericNameRegex("role_name"), pathTokenBatch),
+		Fields:          FieldSchemaTokenRoleBatch,
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: paths.OperationPrefixGitlabAccessTokens,
+			OperationSuffix: "generate-batch",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: p.pathTokenRoleBatchCreate,
+				Summary:  "Create access tokens for multiple paths of a role under a single lease",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "generate",
+					OperationSuffix: "batch-credentials",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: http.StatusText(http.StatusOK),
+						Fields:      secret.FieldSchemaBatchAccessTokens,
+					}},
+				},
+			},
+		},
+	}
+}
+
+func (p *Provider) pathTokenRoleBatchCreate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	var resp *logical.Response
+	var err error
+	var role *modelRole.Role
+	var roleName = data.Get("role_name").(string)
+
+	lock := p.b.LockForKey("role", roleName)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	role, err = p.b.GetRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting role: %w", err)
+	}
+	if role == nil {
+		return nil, fmt.Errorf("role %s: %w", roleName, errs.ErrNotFound)
+	}
+
+	if !role.DynamicPath {
+		return logical.ErrorResponse("role doesn't have a dynamic path"), fmt.Errorf("role %s doesn't have a dynamic path: %w", roleName, errs.ErrInvalidValue)
+	}
+	if role.CIVariableKey != "" {
+		// every token of the batch would overwrite the same variable
+		return logical.ErrorResponse("role writes a ci variable"), fmt.Errorf("role %s writes a ci variable and cannot be used for a batch: %w", roleName, errs.ErrInvalidValue)
+	}
+
+	var rolePaths []string
+	for _, rolePath := range data.Get("paths").([]string) {
+		if !slices.Contains(rolePaths, rolePath) {
+			rolePaths = append(rolePaths, rolePath)
+		}
+	}
+	switch {
+	case len(rolePaths) == 0:
+		return logical.ErrorResponse("paths is required"), fmt.Errorf("paths: %w", errs.ErrFieldRequired)
+	case len(rolePaths) > MaxBatchPaths:
+		return logical.ErrorResponse("too many paths"), fmt.Errorf("%d paths [paths <= %d]: %w", len(rolePaths), MaxBatchPaths, errs.ErrFieldInvalidValue)
+	}
+
+	if err = narrowRole(role, data); err != nil {
+		return logical.ErrorResponse(err.Error()), err
+	}
+
+	// every path is validated before anything is created in GitLab
+	var roles = make([]*modelRole.Role, 0, len(rolePaths))
+	for _, rolePath := range rolePaths {
+		var r = *role
+		if resp, err = p.resolveRolePath(req, &r, rolePath); err != nil {
+			return resp, err
+		}
+		roles = append(roles, &r)
+	}
+
+	p.b.Logger().Debug("Creating batch of tokens for role", "role_name", roleName, "token_type", role.TokenType.String(), "paths", len(roles))
+	defer p.b.Logger().Debug("Created batch of tokens for role", "role_name", roleName, "token_type", role.TokenType.String(), "paths", len(roles))
+
+	var client gitlab.Client
+	client, err = p.b.GetClientByName(ctx, req.Storage, role.ConfigName)
+	if err != nil {
+		return nil, err
+	}
+
+	var startTime = utils.TimeFromContext(ctx).UTC()
+	var tokens, walIds, issueErr = p.issueTokens(ctx, req.Storage, client, roles, startTime)
+	if issueErr != nil {
+		p.rollbackTokens(ctx, req, tokens, walIds)
+		return nil, fmt.Errorf("batch: %w", issueErr)
+	}
+
+	var tokensData = make([]map[string]any, 0, len(tokens))
+	var tokensInternal = make([]map[string]any, 0, len(tokens))
+	var names = make([]string, 0, len(tokens))
+	var expiresAt = tokens[0].GetExpiresAt()
+	var ttl = role.TTL
+	for _, token := range tokens {
+		tokensData = append(tokensData, token.Data())
+		tokensInternal = append(tokensInternal, token.Internal())
+		names = append(names, fmt.Sprint(token.Internal()["name"]))
+		if token.GetExpiresAt().Before(expiresAt) {
+			expiresAt = token.GetExpiresAt()
+		}
+		if role.GitlabRevokesTokens {
+			ttl = min(ttl, token.TTL())
+		}
+	}
+
+	resp = p.batchSecret.Response(
+		map[string]any{"tokens": tokensData, "expires_at": expiresAt},
+		map[string]any{
+			"tokens":      tokensInternal,
+			"expires_at":  expiresAt,
+			"path":        strings.Join(rolePaths, ","),
+			"name":        strings.Join(names, ","),
+			"role_name":   role.RoleName,
+			"config_name": cmp.Or(role.ConfigName, backend.DefaultConfigName),
+			"token_type":  role.TokenType.String(),
+		},
+	)
+	resp.Secret.MaxTTL = role.GetMaxTTL()
+	resp.Secret.TTL = ttl
+	resp.Secret.IssueTime = startTime
+
+	// from here on the tokens are owned by the lease
+	for _, walId := range walIds {
+		if err = framework.DeleteWAL(ctx, req.Storage, walId); err != nil {
+			p.rollbackTokens(ctx, req, tokens, walIds)
+			return nil, fmt.Errorf("delete wal entry: %w", err)
+		}
+	}
+
+	for _, token := range tokens {
+		_ = p.b.SendEvent(
+			ctx, eventWrite,
+			token.Event(map[string]string{"path": fmt.Sprintf("%s/%s", backend.PathRoleStorage, roleName)}),
+		)
+	}
+	return resp, nil
+}
+
+// issueTokens creates the tokens of the roles concurrently, bounded by the slots of the config. The first failure
+// stops the tokens that haven't started yet, the ones that are being created are left to finish, as an interrupted
+// request could still create the token in GitLab. The tokens and WAL entries that were created are returned either
+// way.
+func (p *Provider) issueTokens(ctx context.Context, s logical.Storage, client gitlab.Client, roles []*modelRole.Role, startTime time.Time) (tokens []t.Token, walIds []string, err error) {
+	var slots = p.configSlots(cmp.Or(roles[0].ConfigName, backend.DefaultConfigName))
+	var errList = make([]error, len(roles))
+	tokens, walIds = make([]t.Token, len(roles)), make([]string, len(roles))
+
+	failed, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, role := range roles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-failed.Done():
+				return
+			}
+			if failed.Err() != nil {
+				return
+			}
+
+			if tokens[i], walIds[i], errList[i] = p.issueToken(ctx, s, client, role, startTime); errList[i] != nil {
+				errList[i] = fmt.Errorf("path %s: %w", role.Path, errList[i])
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err = errors.Join(errList...); err == nil {
+		// the request itself was cancelled before all the tokens were created
+		err = ctx.Err()
+	}
+	return tokens, walIds, err
+}
+
+// rollbackTokens revokes the tokens that were created but never handed over to a lease. A token that fails to roll
+// back keeps its WAL entry, and is retried by the periodic rollback of Vault.
+func (p *Provider) rollbackTokens(ctx context.Context, req *logical.Request, tokens []t.Token, walIds []string) {
+	for i, walId := range walIds {
+		if walId == "" {
+			continue
+		}
+
+		var data any
+		if tokens[i] != nil {
+			data = revocation.New(tokens[i])
+		} else if entry, err := framework.GetWAL(ctx, req.Storage, walId); err == nil && entry != nil {
+			// the token was created but not recorded, the WAL entry identifies it
+			data = entry.Data
+		}
+
+		if data != nil {
+			if err := p.WALRollback(ctx, req, walKindToken, data); err != nil {
+				p.b.Logger().Warn("Failed to roll back token", "wal_id", walId, "err", err)
+				continue
+			}
+		}
+		_ = framework.DeleteWAL(ctx, req.Storage, walId)
+	}
+}
+
+// configSlots returns the semaphore that bounds the number of tokens created at the same time for the config.
+func (p *Provider) configSlots(configName string) chan struct{} {
+	p.slotsMu.Lock()
+	defer p.slotsMu.Unlock()
+	if p.slots == nil {
+		p.slots = make(map[string]chan struct{})
+	}
+	if _, ok := p.slots[configName]; !ok {
+		p.slots[configName] = make(chan struct{}, BatchConcurrency)
+	}
+	return p.slots[configName]
+}
@@ -0,0 +1,111 @@
+package token_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	pathtoken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/token"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func callBatch(t *testing.T, mb *mockTokenBackend, raw map[string]any, storage logical.Storage) (*logical.Response, error) {
+	t.Helper()
+	p := pathtoken.New(mb, &framework.Secret{Type: "access_tokens"}, &framework.Secret{Type: "memberships"}, &framework.Secret{Type: "job_token_allowlists"}, &framework.Secret{Type: "batch_access_tokens"}).Paths()[0]
+	fd := &framework.FieldData{Raw: raw, Schema: p.Fields}
+	ctx := utils.WithStaticTime(t.Context(), testNow)
+	return p.Operations[logical.UpdateOperation].Handler()(ctx, &logical.Request{Storage: storage}, fd)
+}
+
+func batchBackend() *mockTokenBackend {
+	r := role(tk.TypeProject, "^example/[a-z0-9-]+$")
+	r.DynamicPath = true
+	return &mockTokenBackend{role: r, client: &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}}
+}
+
+func TestPathTokenRoleBatchCreate(t *testing.T) {
+	var paths []string
+	for i := range 20 {
+		paths = append(paths, fmt.Sprintf("example/app-%d", i))
+	}
+
+	t.Run("creates a token for every path", func(t *testing.T) {
+		mb := batchBackend()
+		storage := &logical.InmemStorage{}
+		resp, err := callBatch(t, mb, map[string]any{"role_name": "r", "paths": append(paths, paths[0])}, storage)
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.NotNil(t, resp.Secret)
+
+		tokens, ok := resp.Data["tokens"].([]map[string]any)
+		require.True(t, ok)
+		require.Len(t, tokens, len(paths), "duplicate paths are created once")
+		for i, token := range tokens {
+			assert.Equal(t, paths[i], token["path"], "tokens are returned in the order of the paths")
+		}
+		assert.Len(t, resp.Secret.InternalData["tokens"], len(paths))
+		assert.ElementsMatch(t, paths, mb.client.(*mockGitlabClient).paths)
+		assert.Len(t, mb.issued, len(paths))
+
+		walIds, err := framework.ListWAL(t.Context(), storage)
+		require.NoError(t, err)
+		assert.Empty(t, walIds)
+	})
+
+	t.Run("failure rolls back the created tokens", func(t *testing.T) {
+		mb := batchBackend()
+		client := mb.client.(*mockGitlabClient)
+		client.failPath = paths[len(paths)-1]
+		storage := &logical.InmemStorage{}
+		_, err := callBatch(t, mb, map[string]any{"role_name": "r", "paths": paths}, storage)
+		require.ErrorIs(t, err, errTest)
+
+		// every token that was created is revoked and removed from the issued tokens
+		assert.Len(t, client.revoked, len(client.paths)-1)
+		assert.Len(t, mb.deleted, len(client.paths)-1)
+
+		walIds, err := framework.ListWAL(t.Context(), storage)
+		require.NoError(t, err)
+		assert.Empty(t, walIds)
+	})
+
+	tests := []struct {
+		name     string
+		modify   func(mb *mockTokenBackend)
+		raw      map[string]any
+		expected error
+	}{
+		{name: "role without a dynamic path", modify: func(mb *mockTokenBackend) { mb.role.DynamicPath = false }, raw: map[string]any{"paths": "example/app"}, expected: errs.ErrInvalidValue},
+		{name: "role with a ci variable", modify: func(mb *mockTokenBackend) { mb.role.CIVariableKey = "TOKEN" }, raw: map[string]any{"paths": "example/app"}, expected: errs.ErrInvalidValue},
+		{name: "no paths", raw: map[string]any{"paths": ""}, expected: errs.ErrFieldRequired},
+		{name: "too many paths", raw: map[string]any{"paths": make([]string, pathtoken.MaxBatchPaths+1)}, expected: errs.ErrFieldInvalidValue},
+		{name: "path doesn't match the role", raw: map[string]any{"paths": "example/app,other/app"}, expected: errs.ErrInvalidValue},
+		{name: "request above the role", raw: map[string]any{"paths": "example/app", "ttl": "2h"}, expected: errs.ErrFieldInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := batchBackend()
+			if tt.modify != nil {
+				tt.modify(mb)
+			}
+			tt.raw["role_name"] = "r"
+			if ps, ok := tt.raw["paths"].([]string); ok {
+				for i := range ps {
+					ps[i] = fmt.Sprintf("example/app-%d", i)
+				}
+			}
+			resp, err := callBatch(t, mb, tt.raw, &logical.InmemStorage{})
+			require.ErrorIs(t, err, tt.expected)
+			require.NotNil(t, resp)
+			assert.True(t, resp.IsError())
+			assert.Empty(t, mb.client.(*mockGitlabClient).paths, "nothing is created in GitLab")
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// injectCIVariable writes the configured field of the issued token to the CI/CD variable of the role. The returned
+// injection holds the variable that was replaced, so it can be restored when the lease is revoked. A variable that
+// holds the value of another active lease is not kept, the injection takes over the variable that lease replaced,
+// so revoking the leases in any order never writes back a revoked value.
+func (p *Provider) injectCIVariable(ctx context.Context, s logical.Storage, client gitlab.Client, role *modelRole.Role, token t.Token) (inj *variable.Injection, err error) {
+	var value, _ = token.Data()[role.CIVariableValue].(string)
+	if value == "" {
+		return nil, fmt.Errorf("ci_variable_value '%s' is not a value of token type %s: %w", role.CIVariableValue, role.TokenType, errs.ErrInvalidValue)
+	}
+
+	inj = &variable.Injection{
+		Target:           role.CIVariableTarget,
+		Path:             role.CIVariablePath,
+		Key:              role.CIVariableKey,
+		EnvironmentScope: role.CIVariableEnvironmentScope,
+		ValueHash:        variable.Hash(value),
+	}
+	var v = &variable.Variable{
+		Key:              role.CIVariableKey,
+		Value:            value,
+		EnvironmentScope: role.CIVariableEnvironmentScope,
+		Protected:        role.CIVariableProtected,
+		Masked:           role.CIVariableMasked,
+		Raw:              true,
+		VariableType:     variable.TypeEnvVar,
+	}
+
+	lock := p.b.LockForKey("ci-variable", inj.GetName())
+	lock.Lock()
+	defer lock.Unlock()
+
+	p.b.Logger().Debug("Writing CI/CD variable for role", "role_name", role.RoleName, "target", inj.Target, "path", inj.Path, "key", inj.Key, "environmentScope", inj.EnvironmentScope)
+	var last *variable.Injection
+	if last, err = p.b.GetCIVariableInjection(ctx, s, inj.GetName()); err != nil {
+		return nil, fmt.Errorf("get ci variable: %w", err)
+	}
+
+	var current *variable.Variable
+	current, err = client.GetCIVariable(ctx, inj.Target, inj.Path, inj.Key, inj.EnvironmentScope)
+	var exists = err == nil
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		err = nil
+	case err != nil:
+	case current == nil:
+		err = fmt.Errorf("%w: ci variable is nil", errs.ErrNilValue)
+	case current.Hidden:
+		// the value of a hidden variable cannot be read, so it could never be restored
+		err = fmt.Errorf("ci variable %s is hidden and cannot be restored: %w", inj.Key, errs.ErrInvalidValue)
+	case last != nil && last.Holds(current.Value):
+		// the value belongs to another active lease, the variable it replaced is the one to restore
+		inj.Previous = last.Previous
+	default:
+		inj.Previous = current
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the injection is stored before the variable is written, so the variable never holds a value the next
+	// injection doesn't know about
+	if err = p.b.SaveCIVariableInjection(ctx, s, inj); err != nil {
+		return nil, fmt.Errorf("save ci variable: %w", err)
+	}
+
+	if exists {
+		err = client.UpdateCIVariable(ctx, inj.Target, inj.Path, v)
+	} else {
+		err = client.CreateCIVariable(ctx, inj.Target, inj.Path, v)
+	}
+	if err != nil {
+		// the variable still holds the value of the last injection, if any
+		if last != nil {
+			_ = p.b.SaveCIVariableInjection(ctx, s, last)
+		} else {
+			_ = p.b.DeleteCIVariableInjection(ctx, s, inj.GetName())
+		}
+		return nil, err
+	}
+	return inj, nil
+}
@@ -0,0 +1,149 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	mt "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func ciVariableRole(tokenType tk.Type, path string) *modelRole.Role {
+	r := role(tokenType, path)
+	r.CIVariableKey = "DEPLOY_TOKEN"
+	r.CIVariableTarget = variable.TargetProject
+	r.CIVariablePath = "example/app"
+	r.CIVariableEnvironmentScope = "production"
+	r.CIVariableProtected = true
+	r.CIVariableMasked = true
+	r.CIVariableValue = "token"
+	return r
+}
+
+func TestPathTokenRoleCreate_CIVariable(t *testing.T) {
+	const variableKey = "project/example/app/DEPLOY_TOKEN/production"
+
+	t.Run("creates the variable", func(t *testing.T) {
+		client := &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{role: ciVariableRole(tk.TypeProject, "p"), client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+
+		require.Contains(t, client.variables, variableKey)
+		assert.Equal(t, &variable.Variable{Key: "DEPLOY_TOKEN", Value: "glpat-test", EnvironmentScope: "production", Protected: true, Masked: true, Raw: true, VariableType: variable.TypeEnvVar}, client.variables[variableKey])
+		assert.Equal(t, "DEPLOY_TOKEN", resp.Data["ci_variable_key"])
+		assert.Equal(t, "example/app", resp.Data["ci_variable_path"])
+
+		inj, ok := resp.Secret.InternalData["ci_variable"].(*variable.Injection)
+		require.True(t, ok)
+		assert.Nil(t, inj.Previous)
+		assert.True(t, inj.Holds("glpat-test"))
+	})
+
+	t.Run("replaces an existing variable", func(t *testing.T) {
+		previous := &variable.Variable{Key: "DEPLOY_TOKEN", Value: "old-value", EnvironmentScope: "production", Masked: true, VariableType: variable.TypeFile, Description: "deploy"}
+		client := &mockGitlabClient{
+			token:     newToken(tk.TypeProject, testNow, testExpiresAt),
+			variables: map[string]*variable.Variable{variableKey: previous},
+		}
+		mb := &mockTokenBackend{role: ciVariableRole(tk.TypeProject, "p"), client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "glpat-test", client.variables[variableKey].Value)
+		inj := resp.Secret.InternalData["ci_variable"].(*variable.Injection)
+		assert.Equal(t, previous, inj.Previous)
+	})
+
+	t.Run("overlapping leases keep the original variable", func(t *testing.T) {
+		original := &variable.Variable{Key: "DEPLOY_TOKEN", Value: "old-value", EnvironmentScope: "production", VariableType: variable.TypeEnvVar}
+		client := &mockGitlabClient{
+			token:     newToken(tk.TypeProject, testNow, testExpiresAt),
+			variables: map[string]*variable.Variable{variableKey: original},
+		}
+		mb := &mockTokenBackend{role: ciVariableRole(tk.TypeProject, "p"), client: client}
+		storage := &logical.InmemStorage{}
+
+		first, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, storage)
+		require.NoError(t, err)
+
+		second := newToken(tk.TypeProject, testNow, testExpiresAt).(*mt.TokenProject)
+		second.Token.Token = "glpat-second"
+		client.token = second
+		resp, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, storage)
+		require.NoError(t, err)
+		assert.Equal(t, "glpat-second", client.variables[variableKey].Value)
+
+		// the second lease replaced the value of the first, it has to restore the original instead
+		assert.Equal(t, original, first.Secret.InternalData["ci_variable"].(*variable.Injection).Previous)
+		inj := resp.Secret.InternalData["ci_variable"].(*variable.Injection)
+		assert.Equal(t, original, inj.Previous)
+		assert.True(t, inj.Holds("glpat-second"))
+
+		last, err := mb.GetCIVariableInjection(t.Context(), storage, inj.GetName())
+		require.NoError(t, err)
+		assert.Equal(t, inj, last)
+	})
+
+	t.Run("writes another field of the secret", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		r := ciVariableRole(tk.TypeOAuthApplication, "dashboard")
+		r.OAuthMode = tk.OAuthApplicationModeCreate
+		r.CIVariableValue = "client_secret"
+		mb := &mockTokenBackend{role: r, client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		assert.Equal(t, "gloas-test", client.variables[variableKey].Value)
+	})
+
+	for _, tc := range []struct {
+		name     string
+		client   *mockGitlabClient
+		value    string
+		expected error
+	}{
+		{
+			name:     "unknown field of the secret",
+			client:   &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)},
+			value:    "client_secret",
+			expected: errs.ErrInvalidValue,
+		},
+		{
+			name: "hidden variable cannot be restored",
+			client: &mockGitlabClient{
+				token:     newToken(tk.TypeProject, testNow, testExpiresAt),
+				variables: map[string]*variable.Variable{variableKey: {Key: "DEPLOY_TOKEN", Hidden: true}},
+			},
+			value:    "token",
+			expected: errs.ErrInvalidValue,
+		},
+		{
+			name:     "writing the variable fails",
+			client:   &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt), variableErr: errTest},
+			value:    "token",
+			expected: errTest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := ciVariableRole(tk.TypeProject, "p")
+			r.CIVariableValue = tc.value
+			mb := &mockTokenBackend{role: r, client: tc.client}
+			storage := &logical.InmemStorage{}
+			_, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, storage)
+			require.ErrorIs(t, err, tc.expected)
+
+			// the token was created, the rollback has to revoke it
+			walIds, err := framework.ListWAL(t.Context(), storage)
+			require.NoError(t, err)
+			assert.Len(t, walIds, 1)
+		})
+	}
+}
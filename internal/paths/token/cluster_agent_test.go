@@ -0,0 +1,48 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestPathTokenRoleCreate_ClusterAgent(t *testing.T) {
+	t.Run("creates a token for an existing agent", func(t *testing.T) {
+		client := &mockGitlabClient{agents: map[string]int64{"production": 9}}
+		mb := &mockTokenBackend{role: role(tk.TypeClusterAgent, "group/project/production"), client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "glagent-test", resp.Data["token"])
+		assert.EqualValues(t, 9, resp.Secret.InternalData["agent_id"])
+		assert.Equal(t, "production", resp.Data["agent_name"])
+		assert.Equal(t, "1", resp.Secret.InternalData["parent_id"])
+		require.Len(t, mb.issued, 1)
+	})
+
+	t.Run("registers a missing agent", func(t *testing.T) {
+		r := role(tk.TypeClusterAgent, "group/project/staging")
+		r.ClusterAgentCreate = true
+		mb := &mockTokenBackend{role: r, client: &mockGitlabClient{}}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, resp.Secret.InternalData["agent_id"])
+	})
+
+	t.Run("missing agent", func(t *testing.T) {
+		mb := &mockTokenBackend{role: role(tk.TypeClusterAgent, "group/project/staging"), client: &mockGitlabClient{}}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errs.ErrNotFound)
+		assert.Empty(t, mb.issued)
+	})
+
+	t.Run("project lookup fails", func(t *testing.T) {
+		mb := &mockTokenBackend{role: role(tk.TypeClusterAgent, "group/project/production"), client: &mockGitlabClient{lookupErr: errTest}}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+	})
+}
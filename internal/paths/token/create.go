@@ -3,8 +3,10 @@ package token
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,7 +16,12 @@ import (
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
 	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
 	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
 )
@@ -37,13 +44,137 @@ func (p *Provider) pathTokenRoleCreate(ctx context.Context, req *logical.Request
 		return nil, fmt.Errorf("role %s: %w", roleName, errs.ErrNotFound)
 	}
 
+	if resp, err = p.resolveRolePath(req, role, data.Get("path").(string)); err != nil {
+		return resp, err
+	}
+
+	if err = narrowRole(role, data); err != nil {
+		return logical.ErrorResponse(err.Error()), err
+	}
+
+	var startTime = utils.TimeFromContext(ctx).UTC()
+
+	// identical requests of an entity share a token within the reuse window of the role, the lock is held until the
+	// token is shared so concurrent requests don't each create one
+	var reuseKey string
+	if role.ReuseWindow > 0 && req.EntityID != "" {
+		reuseKey = reuse.Key(req.EntityID, *role)
+		reuseLock := p.b.LockForKey("reuse", reuseKey)
+		reuseLock.Lock()
+		defer reuseLock.Unlock()
+
+		if resp, err = p.joinSharedToken(ctx, req.Storage, role, reuseKey, startTime); resp != nil || err != nil {
+			return resp, err
+		}
+	}
+
+	p.b.Logger().Debug("Creating token for role", "role_name", roleName, "token_type", role.TokenType.String())
+	defer p.b.Logger().Debug("Created token for role", "role_name", roleName, "token_type", role.TokenType.String())
+
+	var client gitlab.Client
+	client, err = p.b.GetClientByName(ctx, req.Storage, role.ConfigName)
+	if err != nil {
+		return nil, err
+	}
+
+	var token t.Token
+	var walId string
+	if role.Pooled() {
+		token, walId, err = p.takePooledToken(ctx, req.Storage, role, startTime)
+	}
+	if token == nil && err == nil {
+		token, walId, err = p.issueToken(ctx, req.Storage, client, role, startTime)
+	}
+	if err != nil {
+		if errors.Is(err, errs.ErrUnknownTokenType) {
+			return logical.ErrorResponse("invalid token type"), err
+		}
+		return nil, err
+	}
+
+	var leaseSecret = p.secret
+	switch {
+	case role.TokenType == t.TypeMembership:
+		leaseSecret = p.membershipSecret
+	case slices.Contains(t.JobTokenAllowlistTypes, role.TokenType):
+		leaseSecret = p.allowlistSecret
+	}
+	resp = leaseSecret.Response(token.Data(), token.Internal())
+
+	resp.Secret.MaxTTL = role.GetMaxTTL()
+	resp.Secret.TTL = role.TTL
+	resp.Secret.IssueTime = startTime
+	if role.GitlabRevokesTokens {
+		resp.Secret.TTL = token.TTL()
+	}
+
+	// the variable is written last, if it fails the rollback revokes the token that was never delivered
+	var inj *variable.Injection
+	if role.CIVariableKey != "" {
+		if inj, err = p.injectCIVariable(ctx, req.Storage, client, role, token); err != nil {
+			return nil, fmt.Errorf("write ci variable: %w", err)
+		}
+		resp.Secret.InternalData["ci_variable"] = inj
+		resp.Data["ci_variable_target"] = inj.Target
+		resp.Data["ci_variable_path"] = inj.Path
+		resp.Data["ci_variable_key"] = inj.Key
+		resp.Data["ci_variable_environment_scope"] = inj.EnvironmentScope
+	}
+
+	var shared *reuse.Entry
+	if reuseKey != "" {
+		shared = reuse.New(reuseKey, token, startTime)
+		if err = p.b.SaveSharedToken(ctx, req.Storage, shared); err != nil {
+			return nil, fmt.Errorf("save shared token: %w", err)
+		}
+		resp.Secret.InternalData["reuse_key"] = reuseKey
+	}
+
+	// from here on the token is owned by the lease
+	if err = framework.DeleteWAL(ctx, req.Storage, walId); err != nil {
+		if inj != nil {
+			// the rollback revokes the token, so the variable must not keep it
+			_ = secret.RestoreCIVariable(ctx, client, inj)
+		}
+		if shared != nil {
+			// nor can the token be shared with the next request
+			_ = p.b.DeleteSharedToken(ctx, req.Storage, shared.Key, shared.GetName())
+		}
+		return nil, fmt.Errorf("delete wal entry: %w", err)
+	}
+
+	_ = p.b.SendEvent(
+		ctx, eventWrite,
+		token.Event(map[string]string{"path": fmt.Sprintf("%s/%s", backend.PathRoleStorage, roleName)}),
+	)
+	return resp, nil
+}
+
+// resolveRolePath resolves the path of the role for the request, from the identity templates of the role, the path
+// of the request when the role has a dynamic path, or the username of the entity that made the request.
+func (p *Provider) resolveRolePath(req *logical.Request, role *modelRole.Role, rolePath string) (_ *logical.Response, err error) {
+	// the identity templates are resolved before anything else, so the path, or the regex of a dynamic
+	// path, is the one of the entity that made the request
+	if role.PathTemplated() {
+		var regex = role.DynamicPath || role.UsernameFromEntity()
+		if role.Path, err = p.resolvePathTemplate(req.EntityID, role, regex); err != nil {
+			return logical.ErrorResponse(err.Error()), err
+		}
+		if !regex && !t.IsValidPath(role.Path, role.TokenType) {
+			return logical.ErrorResponse("invalid path"), fmt.Errorf("path '%s' is not valid for token type %s: %w", role.Path, role.TokenType, errs.ErrInvalidValue)
+		}
+	}
+
 	// The regexp is always valid, as it is checked during role creation.
 	// We only need to validate that the path is correct and matches the regexp.
 	// If DynamicPath is false, the path is already validated during role creation,
 	// so no additional path validation is required here.
 	if role.DynamicPath {
+		if rolePath == pathTokenBatch {
+			// token/<role_name>/batch is matched by the batch path, so a token for it could never be requested
+			return logical.ErrorResponse("path is reserved"), fmt.Errorf("path '%s' is reserved for batches: %w", rolePath, errs.ErrInvalidValue)
+		}
 		rx, _ := regexp.Compile(role.Path)
-		rolePath := data.Get("path").(string)
 		if !t.IsValidPath(rolePath, role.TokenType) {
 			return logical.ErrorResponse("invalid path"), fmt.Errorf("path '%s' is not valid for token type %s: %w", rolePath, role.TokenType, errs.ErrInvalidValue)
 		}
@@ -53,28 +184,59 @@ func (p *Provider) pathTokenRoleCreate(ctx context.Context, req *logical.Request
 		role.Path = rolePath
 	}
 
-	p.b.Logger().Debug("Creating token for role", "role_name", roleName, "token_type", role.TokenType.String())
-	defer p.b.Logger().Debug("Created token for role", "role_name", roleName, "token_type", role.TokenType.String())
+	// the username is never taken from the request, only from the entity that made it,
+	// so a requester can't mint a token for someone else
+	if role.UsernameFromEntity() {
+		var username string
+		if username, err = p.entityUsername(req.EntityID, role); err != nil {
+			return logical.ErrorResponse(err.Error()), err
+		}
+		rx, _ := regexp.Compile(role.Path)
+		if !t.IsValidPath(username, role.TokenType) {
+			return logical.ErrorResponse("invalid username"), fmt.Errorf("username '%s' is not valid for token type %s: %w", username, role.TokenType, errs.ErrInvalidValue)
+		}
+		if !rx.MatchString(username) {
+			return logical.ErrorResponse("username doesn't match regex"), fmt.Errorf("regexp (%s) with username '%s': %w", role.Path, username, errs.ErrInvalidValue)
+		}
+		role.Path = username
+	}
+
+	return nil, nil
+}
 
+// issueToken creates the token of the role in GitLab and records it as issued. A WAL entry guards the token until
+// the caller hands it over to a lease and deletes the returned WAL entry, if the caller fails before that the
+// rollback revokes the token so it is not orphaned.
+func (p *Provider) issueToken(ctx context.Context, s logical.Storage, client gitlab.Client, role *modelRole.Role, startTime time.Time) (token t.Token, walId string, err error) {
 	var name string
-	var token t.Token
 	var expiresAt time.Time
-	var startTime = utils.TimeFromContext(ctx).UTC()
+	var accountRecorded bool
 
 	name, err = utils.TokenName(role)
 	if err != nil {
-		return nil, fmt.Errorf("error generating token name: %w", err)
+		return nil, "", fmt.Errorf("error generating token name: %w", err)
 	}
 
-	var client gitlab.Client
-	var gitlabRevokesTokens = role.GitlabRevokesTokens
 	var vaultRevokesTokens = !role.GitlabRevokesTokens
+	var maxTTL = role.GetMaxTTL()
 
-	_, expiresAt, _ = utils.CalculateGitlabTTL(role.TTL, startTime)
+	// the token in GitLab lives until max_ttl, the lease itself is issued
+	// for ttl and can be renewed until it reaches the GitLab expiry
+	_, expiresAt, _ = utils.CalculateGitlabTTL(maxTTL, startTime)
 
-	client, err = p.b.GetClientByName(ctx, req.Storage, role.ConfigName)
+	// the WAL entry is written before the token is created in GitLab, if the request fails after
+	// GitLab has created the token the rollback revokes it so it is not orphaned
+	var pending = revocation.Entry{
+		ConfigName: cmp.Or(role.ConfigName, backend.DefaultConfigName),
+		RoleName:   role.RoleName,
+		TokenType:  role.TokenType,
+		Path:       role.Path,
+		Name:       name,
+		CreatedAt:  startTime,
+	}
+	walId, err = framework.PutWAL(ctx, s, walKindToken, &pending)
 	if err != nil {
-		return nil, err
+		return nil, "", fmt.Errorf("write wal entry: %w", err)
 	}
 
 	switch role.TokenType {
@@ -91,6 +253,12 @@ func (p *Provider) pathTokenRoleCreate(ctx context.Context, req *logical.Request
 			p.b.Logger().Debug("Creating personal access token for role", "path", role.Path, "userId", userId, "name", name, "expiresAt", expiresAt, "scopes", role.Scopes)
 			token, err = client.CreatePersonalAccessToken(ctx, role.Path, userId, name, expiresAt, role.Scopes)
 		}
+	case t.TypeImpersonation:
+		var userId int64
+		if userId, err = client.GetUserIdByUsername(ctx, role.Path); err == nil {
+			p.b.Logger().Debug("Creating impersonation token for role", "path", role.Path, "userId", userId, "name", name, "expiresAt", expiresAt, "scopes", role.Scopes)
+			token, err = client.CreateImpersonationToken(ctx, role.Path, userId, name, expiresAt, role.Scopes)
+		}
 	case t.TypeUserServiceAccount:
 		var userId int64
 		if userId, err = client.GetUserIdByUsername(ctx, role.Path); err == nil {
@@ -131,42 +299,87 @@ func (p *Provider) pathTokenRoleCreate(ctx context.Context, req *logical.Request
 		if groupId, err = client.GetGroupIdByPath(ctx, role.Path); err == nil {
 			token, err = client.CreateGroupDeployToken(ctx, role.Path, groupId, name, &expiresAt, role.Scopes)
 		}
+	case t.TypeProjectDeployKey:
+		token, err = p.createProjectDeployKey(ctx, client, role, name, expiresAt)
+	case t.TypeUserSSHKey:
+		token, err = p.createUserSSHKey(ctx, client, role, name, expiresAt)
 	case t.TypePipelineProjectTrigger:
 		var projectId int64
 		if projectId, err = client.GetProjectIdByPath(ctx, role.Path); err == nil {
 			token, err = client.CreatePipelineProjectTriggerAccessToken(ctx, role.Path, name, projectId, name, &expiresAt)
 		}
+	case t.TypeEphemeralGroupServiceAccount, t.TypeEphemeralUserServiceAccount:
+		p.b.Logger().Debug("Creating ephemeral service account access token for role", "path", role.Path, "name", name, "expiresAt", expiresAt, "scopes", role.Scopes, "accessLevel", role.AccessLevel)
+		token, err = p.createEphemeralServiceAccount(ctx, client, role, name, expiresAt, func(userId int64, groupId string) (err error) {
+			// the service account exists in GitLab from here on, the WAL entry is replaced with one that
+			// identifies it, so the rollback deletes it if the request fails before its cleanup does
+			var entry = pending
+			entry.UserID, entry.ParentID = userId, groupId
+			walId, err = p.replaceWAL(ctx, s, walId, &entry)
+			accountRecorded = err == nil
+			return err
+		})
+	case t.TypeMembership:
+		token, err = p.createMembership(ctx, client, role, name, expiresAt)
+	case t.TypeGroupRunner, t.TypeProjectRunner, t.TypeInstanceRunner:
+		token, err = p.createRunner(ctx, client, role, name)
+	case t.TypeOAuthApplication:
+		token, err = p.createOAuthApplication(ctx, client, role, name, startTime)
+	case t.TypeProjectJobTokenAllowlist, t.TypeGroupJobTokenAllowlist:
+		token, err = p.createJobTokenAllowlistEntry(ctx, client, role, name)
+	case t.TypeClusterAgent:
+		var projectPath, agentName string
+		{
+			idx := strings.LastIndex(role.Path, "/")
+			projectPath, agentName = role.Path[:idx], role.Path[idx+1:]
+		}
+
+		var projectId int64
+		if projectId, err = client.GetProjectIdByPath(ctx, projectPath); err == nil {
+			p.b.Logger().Debug("Creating cluster agent token for role", "path", role.Path, "projectId", projectId, "agentName", agentName, "createAgent", role.ClusterAgentCreate, "name", name)
+			token, err = client.CreateClusterAgentToken(ctx, role.Path, projectId, agentName, role.ClusterAgentCreate, name)
+		}
 	default:
-		return logical.ErrorResponse("invalid token type"), fmt.Errorf("%s: %w", role.TokenType.String(), errs.ErrUnknownTokenType)
+		err = fmt.Errorf("%s: %w", role.TokenType.String(), errs.ErrUnknownTokenType)
+	}
+
+	if err != nil && (createOutcomeUnknown(err) || accountRecorded) {
+		// GitLab may have created the token before the request failed, the WAL entry stays so the rollback
+		// can look for the token by its name and revoke it, or delete the service account that was created
+		return nil, "", err
 	}
 
 	if err != nil || token == nil {
-		return nil, cmp.Or(err, fmt.Errorf("%w: token is nil", errs.ErrNilValue))
+		// nothing to roll back, there is no token in GitLab
+		_ = framework.DeleteWAL(ctx, s, walId)
+		return nil, "", cmp.Or(err, fmt.Errorf("%w: token is nil", errs.ErrNilValue))
 	}
 
 	token.SetConfigName(cmp.Or(role.ConfigName, backend.DefaultConfigName))
 	token.SetRoleName(role.RoleName)
 	token.SetGitlabRevokesToken(role.GitlabRevokesTokens)
 
+	if walId, err = p.updateWAL(ctx, s, walId, token, startTime); err != nil {
+		return nil, walId, err
+	}
+
 	if vaultRevokesTokens {
 		// since vault is controlling the expiry, we need to override here
 		// and make the expiry time accurate
-		expiresAt = startTime.Add(role.TTL)
+		expiresAt = startTime.Add(maxTTL)
 		token.SetExpiresAt(&expiresAt)
 	}
 
-	resp = p.secret.Response(token.Data(), token.Internal())
-
-	resp.Secret.MaxTTL = role.TTL
-	resp.Secret.TTL = role.TTL
-	resp.Secret.IssueTime = startTime
-	if gitlabRevokesTokens {
-		resp.Secret.TTL = token.TTL()
+	if err = p.b.SaveIssuedToken(ctx, s, issued.New(token, startTime)); err != nil {
+		p.b.Logger().Error("Failed to record the issued token", "role_name", role.RoleName, "err", err)
+		return nil, walId, err
 	}
 
-	_ = p.b.SendEvent(
-		ctx, eventWrite,
-		token.Event(map[string]string{"path": fmt.Sprintf("%s/%s", backend.PathRoleStorage, roleName)}),
-	)
-	return resp, nil
+	return token, walId, nil
+}
+
+// createOutcomeUnknown reports if GitLab may have created the token even though the request failed, because the
+// connection broke, the request timed out or was cancelled, or GitLab failed before the response reached us.
+func createOutcomeUnknown(err error) bool {
+	return gitlab.IsTransientError(err) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
 }
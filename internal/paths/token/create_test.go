@@ -26,12 +26,24 @@ var (
 )
 
 func callCreate(t *testing.T, mb *mockTokenBackend, raw map[string]any) (*logical.Response, error) {
+	t.Helper()
+	return callCreateWithStorage(t, mb, raw, &logical.InmemStorage{})
+}
+
+func callCreateWithStorage(t *testing.T, mb *mockTokenBackend, raw map[string]any, storage logical.Storage) (*logical.Response, error) {
+	t.Helper()
+	return callCreateWithContext(t, t.Context(), mb, raw, storage)
+}
+
+func callCreateWithContext(t *testing.T, ctx context.Context, mb *mockTokenBackend, raw map[string]any, storage logical.Storage) (*logical.Response, error) {
 	t.Helper()
 	s := &framework.Secret{Type: "access_tokens"}
-	p := pathtoken.New(mb, s).Paths()[0]
+	ms := &framework.Secret{Type: "memberships"}
+	as := &framework.Secret{Type: "job_token_allowlists"}
+	p := pathtoken.New(mb, s, ms, as, &framework.Secret{Type: "batch_access_tokens"}).Paths()[1]
 	fd := &framework.FieldData{Raw: raw, Schema: p.Fields}
-	ctx := utils.WithStaticTime(t.Context(), testNow)
-	return p.Operations[logical.ReadOperation].Handler()(ctx, &logical.Request{}, fd)
+	ctx = utils.WithStaticTime(ctx, testNow)
+	return p.Operations[logical.ReadOperation].Handler()(ctx, &logical.Request{Storage: storage}, fd)
 }
 
 func role(tokenType tk.Type, path string) *modelRole.Role {
@@ -53,6 +65,7 @@ func TestPathTokenRoleCreate_Errors(t *testing.T) {
 		{"unknown type", &mockTokenBackend{role: role(tk.Type("invalid"), "p"), client: &mockGitlabClient{}}, "unknown token type"},
 		{"invalid name", &mockTokenBackend{role: badName, client: &mockGitlabClient{}}, "error generating token name"},
 		{"create error", &mockTokenBackend{role: role(tk.TypeProject, "p"), client: &mockGitlabClient{createErr: errTest}}, "test error"},
+		{"issued token error", &mockTokenBackend{role: role(tk.TypeProject, "p"), client: &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}, issuedErr: errTest}, "test error"},
 	}
 
 	for _, tt := range tests {
@@ -70,6 +83,7 @@ func TestPathTokenRoleCreate_LookupError(t *testing.T) {
 		path      string
 	}{
 		{"personal", tk.TypePersonal, "user"},
+		{"impersonation", tk.TypeImpersonation, "user"},
 		{"user-service-account", tk.TypeUserServiceAccount, "user"},
 		{"group-service-account", tk.TypeGroupServiceAccount, "group/sa"},
 		{"project-deploy", tk.TypeProjectDeploy, "g/p"},
@@ -127,6 +141,14 @@ func TestPathTokenRoleCreate_Success(t *testing.T) {
 			assert.Equal(t, "roles/r", sentMetadata["path"])
 			assert.Equal(t, tt.tokenType.String(), sentMetadata["token_type"])
 			assert.Equal(t, "r", sentMetadata["role_name"])
+
+			require.Len(t, mb.issued, 1)
+			assert.Equal(t, "default", mb.issued[0].ConfigName)
+			assert.Equal(t, "r", mb.issued[0].RoleName)
+			assert.EqualValues(t, 1, mb.issued[0].TokenID)
+			assert.Equal(t, tt.tokenType, mb.issued[0].TokenType)
+			assert.Equal(t, testNow, mb.issued[0].IssuedAt)
+			assert.NotContains(t, mb.issued[0].Metadata, "token")
 		})
 	}
 }
@@ -162,6 +184,20 @@ func TestPathTokenRoleCreate_RevocationMode(t *testing.T) {
 	})
 }
 
+func TestPathTokenRoleCreate_MaxTTL(t *testing.T) {
+	r := role(tk.TypeProject, "p")
+	r.MaxTTL = 4 * time.Hour
+	mb := &mockTokenBackend{
+		role:   r,
+		client: &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)},
+	}
+	resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, resp.Secret.TTL, "TTL should be role TTL")
+	assert.Equal(t, 4*time.Hour, resp.Secret.MaxTTL, "MaxTTL should be role MaxTTL")
+	assert.Equal(t, testNow.Add(4*time.Hour), resp.Data["expires_at"].(*time.Time).UTC(), "ExpiresAt should be startTime + role.MaxTTL")
+}
+
 func TestPathTokenRoleCreate_DynamicPath(t *testing.T) {
 	t.Run("invalid path", func(t *testing.T) {
 		r := role(tk.TypeProject, `^allowed/.*$`)
@@ -179,6 +215,14 @@ func TestPathTokenRoleCreate_DynamicPath(t *testing.T) {
 		require.ErrorContains(t, err, "regexp")
 	})
 
+	t.Run("batch is reserved", func(t *testing.T) {
+		r := role(tk.TypeProject, `^.*$`)
+		r.DynamicPath = true
+		mb := &mockTokenBackend{role: r}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r", "path": "batch"})
+		require.ErrorContains(t, err, "reserved")
+	})
+
 	t.Run("success", func(t *testing.T) {
 		r := role(tk.TypeProject, `^allowed/.*$`)
 		r.DynamicPath = true
@@ -0,0 +1,54 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+)
+
+// entityUsername returns the GitLab username of the Vault entity that made the request, either the name of its
+// alias on the auth mount of the role, or the value of the metadata key of the role.
+func (p *Provider) entityUsername(entityId string, role *modelRole.Role) (username string, err error) {
+	var entity *logical.Entity
+	if entity, err = p.requestEntity(entityId); err != nil {
+		return "", err
+	}
+
+	if role.EntityMetadataKey != "" {
+		username = entity.Metadata[role.EntityMetadataKey]
+	} else {
+		for _, alias := range entity.Aliases {
+			if alias.MountAccessor == role.EntityAliasMountAccessor {
+				username = alias.Name
+				break
+			}
+		}
+	}
+
+	if username == "" {
+		return "", fmt.Errorf("entity %s has no username: %w", entityId, logical.ErrPermissionDenied)
+	}
+
+	p.b.Logger().Debug("Resolved username from entity", "role_name", role.RoleName, "entity_id", entityId, "username", username)
+	return username, nil
+}
+
+// requestEntity returns the enabled Vault entity that made the request.
+func (p *Provider) requestEntity(entityId string) (entity *logical.Entity, err error) {
+	if entityId == "" {
+		return nil, fmt.Errorf("request has no entity: %w", logical.ErrPermissionDenied)
+	}
+	if entity, err = p.b.EntityInfo(entityId); err != nil {
+		return nil, fmt.Errorf("entity %s: %w", entityId, err)
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("entity %s: %w", entityId, errs.ErrNotFound)
+	}
+	if entity.Disabled {
+		return nil, fmt.Errorf("entity %s is disabled: %w", entityId, logical.ErrPermissionDenied)
+	}
+	return entity, nil
+}
@@ -0,0 +1,102 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	pathtoken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/token"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func callCreateAsEntity(t *testing.T, mb *mockTokenBackend, entityId string, raw map[string]any) (*logical.Response, error) {
+	t.Helper()
+	p := pathtoken.New(mb, &framework.Secret{Type: "access_tokens"}, &framework.Secret{Type: "memberships"}, &framework.Secret{Type: "job_token_allowlists"}, &framework.Secret{Type: "batch_access_tokens"}).Paths()[1]
+	fd := &framework.FieldData{Raw: raw, Schema: p.Fields}
+	ctx := utils.WithStaticTime(t.Context(), testNow)
+	return p.Operations[logical.ReadOperation].Handler()(ctx, &logical.Request{Storage: &logical.InmemStorage{}, EntityID: entityId}, fd)
+}
+
+func TestPathTokenRoleCreate_UsernameFromEntity(t *testing.T) {
+	var entity = &logical.Entity{
+		ID:       "entity-id",
+		Name:     "alice",
+		Metadata: map[string]string{"gitlab_username": "alice"},
+		Aliases: []*logical.Alias{
+			{MountAccessor: "auth_userpass_1234", Name: "alice-userpass"},
+			{MountAccessor: "auth_oidc_5678", Name: "alice"},
+		},
+	}
+
+	t.Run("from the alias of the entity", func(t *testing.T) {
+		r := role(tk.TypePersonal, "^[a-z]+$")
+		r.EntityAliasMountAccessor = "auth_oidc_5678"
+		client := &mockGitlabClient{token: newToken(tk.TypePersonal, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{role: r, entity: entity, client: client}
+		resp, err := callCreateAsEntity(t, mb, "entity-id", map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, []string{"alice"}, client.usernames)
+	})
+
+	t.Run("from the metadata of the entity", func(t *testing.T) {
+		r := role(tk.TypePersonal, ".*")
+		r.EntityMetadataKey = "gitlab_username"
+		client := &mockGitlabClient{token: newToken(tk.TypePersonal, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{role: r, entity: entity, client: client}
+		resp, err := callCreateAsEntity(t, mb, "entity-id", map[string]any{"role_name": "r", "path": "bob"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, []string{"alice"}, client.usernames, "the path of the request is ignored")
+	})
+
+	tests := []struct {
+		name     string
+		entityId string
+		modify   func(mb *mockTokenBackend)
+		expected error
+	}{
+		{name: "request without an entity", expected: logical.ErrPermissionDenied},
+		{name: "entity lookup fails", entityId: "entity-id", modify: func(mb *mockTokenBackend) { mb.entityErr = errTest }, expected: errTest},
+		{name: "entity not found", entityId: "entity-id", modify: func(mb *mockTokenBackend) { mb.entity = nil }, expected: errs.ErrNotFound},
+		{
+			name:     "entity is disabled",
+			entityId: "entity-id",
+			modify:   func(mb *mockTokenBackend) { mb.entity = &logical.Entity{ID: "entity-id", Disabled: true} },
+			expected: logical.ErrPermissionDenied,
+		},
+		{
+			name:     "entity has no alias on the mount",
+			entityId: "entity-id",
+			modify:   func(mb *mockTokenBackend) { mb.role.EntityAliasMountAccessor = "auth_ldap_0000" },
+			expected: logical.ErrPermissionDenied,
+		},
+		{
+			name:     "username doesn't match the path",
+			entityId: "entity-id",
+			modify:   func(mb *mockTokenBackend) { mb.role.Path = "^bob$" },
+			expected: errs.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := role(tk.TypePersonal, ".*")
+			r.EntityAliasMountAccessor = "auth_oidc_5678"
+			mb := &mockTokenBackend{role: r, entity: entity, client: &mockGitlabClient{token: newToken(tk.TypePersonal, testNow, testExpiresAt)}}
+			if tt.modify != nil {
+				tt.modify(mb)
+			}
+			resp, err := callCreateAsEntity(t, mb, tt.entityId, map[string]any{"role_name": "r"})
+			require.ErrorIs(t, err, tt.expected)
+			require.NotNil(t, resp)
+			assert.True(t, resp.IsError())
+			assert.Empty(t, mb.issued)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// ephemeralCleanupTimeout bounds the deletion of a service account whose token could not be created, it runs even
+// when the request was cancelled.
+const ephemeralCleanupTimeout = 30 * time.Second
+
+// createEphemeralServiceAccount creates a new service account for the lease and a token for it. Group service
+// accounts are created in the top level group of the role path, user service accounts on the instance. If the
+// role has an access level the service account is also added as a member of the role path.
+//
+// The created service account is passed to record before anything else is done with it, so it can be deleted by
+// the rollback if the request never finishes. If anything fails after the service account was created, the service
+// account is deleted again.
+func (p *Provider) createEphemeralServiceAccount(ctx context.Context, client gitlab.Client, role *modelRole.Role, name string, expiresAt time.Time, record func(userId int64, groupId string) error) (token t.Token, err error) {
+	var userId int64
+	var username, groupId string
+
+	switch role.TokenType {
+	case t.TypeEphemeralGroupServiceAccount:
+		groupId, _, _ = strings.Cut(role.Path, "/")
+		userId, username, err = client.CreateGroupServiceAccount(ctx, groupId, name)
+	case t.TypeEphemeralUserServiceAccount:
+		userId, username, err = client.CreateUserServiceAccount(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.b.Logger().Debug("Created ephemeral service account for role", "path", role.Path, "userId", userId, "username", username, "name", name)
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		// the request may have failed because its context is done, the cleanup gets a context of its own
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ephemeralCleanupTimeout)
+		defer cancel()
+		var deleteErr error
+		if role.TokenType == t.TypeEphemeralGroupServiceAccount {
+			deleteErr = client.DeleteGroupServiceAccount(cleanupCtx, groupId, userId)
+		} else {
+			deleteErr = client.DeleteUserServiceAccount(cleanupCtx, userId)
+		}
+		if deleteErr != nil {
+			p.b.Logger().Error("Failed to delete the ephemeral service account", "userId", userId, "username", username, "err", deleteErr)
+			err = errors.Join(err, deleteErr)
+		}
+	}()
+
+	if err = record(userId, groupId); err != nil {
+		return nil, err
+	}
+
+	if role.AccessLevel != t.AccessLevelUnknown {
+		if err = client.AddMember(ctx, role.Path, userId, role.AccessLevel, &expiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	var base *modelToken.TokenWithScopes
+	if role.TokenType == t.TypeEphemeralGroupServiceAccount {
+		var gt *modelToken.TokenGroupServiceAccount
+		if gt, err = client.CreateGroupServiceAccountAccessToken(ctx, role.Path, groupId, userId, name, expiresAt, role.Scopes); err == nil && gt != nil {
+			base = &gt.TokenWithScopes
+		}
+	} else {
+		var pt *modelToken.TokenPersonal
+		if pt, err = client.CreatePersonalAccessToken(ctx, username, userId, name, expiresAt, role.Scopes); err == nil && pt != nil {
+			base = &pt.TokenWithScopes
+		}
+	}
+	if err == nil && base == nil {
+		err = fmt.Errorf("%w: token is nil", errs.ErrNilValue)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	base.TokenType = role.TokenType
+	return &modelToken.TokenEphemeralServiceAccount{
+		TokenWithScopes: *base,
+		UserID:          userId,
+		Username:        username,
+		AccessLevel:     role.AccessLevel,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package token_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pathtoken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/token"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestPathTokenRoleCreate_EphemeralServiceAccount(t *testing.T) {
+	t.Run("group service account with membership", func(t *testing.T) {
+		client := &mockGitlabClient{token: newToken(tk.TypeGroupServiceAccount, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{role: role(tk.TypeEphemeralGroupServiceAccount, "group/project"), client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "glpat-test", resp.Data["token"])
+		assert.Equal(t, tk.TypeEphemeralGroupServiceAccount.String(), resp.Data["token_type"])
+		assert.EqualValues(t, 7, resp.Secret.InternalData["user_id"])
+		assert.Equal(t, "service_account_group_group", resp.Data["username"])
+		assert.Equal(t, map[string]tk.AccessLevel{"group/project": tk.AccessLevelDeveloperPermissions}, client.members)
+		assert.Empty(t, client.deletedAccounts)
+		require.Len(t, mb.issued, 1)
+		assert.Equal(t, tk.TypeEphemeralGroupServiceAccount, mb.issued[0].TokenType)
+	})
+
+	t.Run("user service account without membership", func(t *testing.T) {
+		client := &mockGitlabClient{token: newToken(tk.TypePersonal, testNow, testExpiresAt)}
+		r := role(tk.TypeEphemeralUserServiceAccount, "group")
+		r.AccessLevel = tk.AccessLevelUnknown
+		mb := &mockTokenBackend{role: r, client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, tk.TypeEphemeralUserServiceAccount.String(), resp.Data["token_type"])
+		assert.Equal(t, "service_account", resp.Data["username"])
+		assert.Empty(t, client.members)
+	})
+
+	t.Run("service account cannot be created", func(t *testing.T) {
+		client := &mockGitlabClient{accountErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeEphemeralGroupServiceAccount, "group"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Empty(t, client.deletedAccounts)
+	})
+
+	t.Run("service account is deleted when the membership fails", func(t *testing.T) {
+		client := &mockGitlabClient{memberErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeEphemeralUserServiceAccount, "group"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Equal(t, []int64{7}, client.deletedAccounts)
+	})
+
+	t.Run("service account is deleted when the token fails", func(t *testing.T) {
+		client := &mockGitlabClient{createErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeEphemeralGroupServiceAccount, "group"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Equal(t, []int64{7}, client.deletedAccounts)
+		assert.Empty(t, mb.issued)
+	})
+	t.Run("service account is deleted when the request is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		client := &mockGitlabClient{onMember: cancel, memberErr: context.Canceled}
+		mb := &mockTokenBackend{role: role(tk.TypeEphemeralUserServiceAccount, "group"), client: client}
+		_, err := callCreateWithContext(t, ctx, mb, map[string]any{"role_name": "r"}, &logical.InmemStorage{})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []int64{7}, client.deletedAccounts)
+	})
+
+	t.Run("service account is recorded for the rollback", func(t *testing.T) {
+		s := &logical.InmemStorage{}
+		client := &mockGitlabClient{createErr: context.DeadlineExceeded}
+		mb := &mockTokenBackend{role: role(tk.TypeEphemeralGroupServiceAccount, "group/project"), client: client}
+		_, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, s)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		entries := walEntries(t, s)
+		require.Len(t, entries, 1)
+		data := entries[0].Data.(map[string]any)
+		assert.Equal(t, json.Number("7"), data["user_id"])
+		assert.Equal(t, "group", data["parent_id"])
+
+		// the request failed before its cleanup could delete the service account
+		client.deletedAccounts = nil
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{Storage: s}, "token", entries[0].Data))
+		assert.Equal(t, []int64{7}, client.deletedAccounts)
+		assert.Empty(t, mb.deleted)
+	})
+}
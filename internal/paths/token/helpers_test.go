@@ -2,24 +2,39 @@ package token_test
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/pool"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
 	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
 	mt "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 )
 
 type mockTokenBackend struct {
+	mu        sync.Mutex
 	role      *modelRole.Role
 	roleErr   error
 	client    gitlab.Client
 	clientErr error
+	issued    []*issued.Token
+	issuedErr error
+	deleted   []int64
+	entity    *logical.Entity
+	entityErr error
+	groups    []*logical.Group
 	sendEvent func(ctx context.Context, eventType event.EventType, metadata map[string]string) error
 }
 
@@ -30,9 +45,67 @@ func (m *mockTokenBackend) LockForKey(_, _ string) *locksutil.LockEntry {
 func (m *mockTokenBackend) GetRole(_ context.Context, _ logical.Storage, _ string) (*modelRole.Role, error) {
 	return m.role, m.roleErr
 }
+func (m *mockTokenBackend) GetIssuedToken(_ context.Context, _ logical.Storage, _, _ string, tokenId int64) (*issued.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, it := range m.issued {
+		if it.TokenID == tokenId {
+			return it, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockTokenBackend) SaveIssuedToken(_ context.Context, _ logical.Storage, t *issued.Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.issuedErr != nil {
+		return m.issuedErr
+	}
+	m.issued = append(m.issued, t)
+	return nil
+}
+func (m *mockTokenBackend) DeleteIssuedToken(_ context.Context, _ logical.Storage, _, _ string, tokenId int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, tokenId)
+	return nil
+}
+func (m *mockTokenBackend) GetPooledToken(ctx context.Context, s logical.Storage, roleName, name string) (*pool.Entry, error) {
+	return model.Get[pool.Entry](ctx, s, "pool/"+roleName+"/"+name)
+}
+func (m *mockTokenBackend) SavePooledToken(ctx context.Context, s logical.Storage, e *pool.Entry) error {
+	return model.Save(ctx, s, "pool/"+e.RoleName, e)
+}
+func (m *mockTokenBackend) DeletePooledToken(ctx context.Context, s logical.Storage, roleName, name string) error {
+	return model.Delete(ctx, s, "pool/"+roleName+"/"+name)
+}
+func (m *mockTokenBackend) GetSharedToken(ctx context.Context, s logical.Storage, key, name string) (*reuse.Entry, error) {
+	return model.Get[reuse.Entry](ctx, s, "reuse/"+key+"/"+name)
+}
+func (m *mockTokenBackend) SaveSharedToken(ctx context.Context, s logical.Storage, e *reuse.Entry) error {
+	return model.Save(ctx, s, "reuse/"+e.Key, e)
+}
+func (m *mockTokenBackend) DeleteSharedToken(ctx context.Context, s logical.Storage, key, name string) error {
+	return model.Delete(ctx, s, "reuse/"+key+"/"+name)
+}
+func (m *mockTokenBackend) GetCIVariableInjection(ctx context.Context, s logical.Storage, name string) (*variable.Injection, error) {
+	return model.Get[variable.Injection](ctx, s, "ci-variable/"+name)
+}
+func (m *mockTokenBackend) SaveCIVariableInjection(ctx context.Context, s logical.Storage, inj *variable.Injection) error {
+	return model.Save(ctx, s, "ci-variable", inj)
+}
+func (m *mockTokenBackend) DeleteCIVariableInjection(ctx context.Context, s logical.Storage, name string) error {
+	return model.Delete(ctx, s, "ci-variable/"+name)
+}
 func (m *mockTokenBackend) GetClientByName(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
 	return m.client, m.clientErr
 }
+func (m *mockTokenBackend) EntityInfo(_ string) (*logical.Entity, error) {
+	return m.entity, m.entityErr
+}
+func (m *mockTokenBackend) GroupsForEntity(_ string) ([]*logical.Group, error) {
+	return m.groups, nil
+}
 func (m *mockTokenBackend) SendEvent(ctx context.Context, eventType event.EventType, metadata map[string]string) error {
 	if m.sendEvent != nil {
 		return m.sendEvent(ctx, eventType, metadata)
@@ -42,12 +115,47 @@ func (m *mockTokenBackend) SendEvent(ctx context.Context, eventType event.EventT
 
 type mockGitlabClient struct {
 	gitlab.Client
+	mu        sync.Mutex
+	failPath  string
 	token     tk.Token
 	lookupErr error
 	createErr error
+	// createdErr is returned together with the created token, as when the response is lost after GitLab created it
+	createdErr  error
+	listed      []*mt.TokenProject
+	revokeErr   error
+	revoked     []int64
+	usernames   []string
+	paths       []string
+	scopes      []string
+	accessLevel tk.AccessLevel
+
+	accountErr      error
+	memberErr       error
+	onMember        func()
+	members         map[string]tk.AccessLevel
+	deletedAccounts []int64
+	runners         []*mt.TokenRunner
+	agents          map[string]int64
+	publicKeys      []string
+	renewedApps     []int64
+	allowlist       []int64
+	variables       map[string]*variable.Variable
+	variableErr     error
 }
 
-func (m *mockGitlabClient) GetUserIdByUsername(_ context.Context, _ string) (int64, error) {
+func (m *mockGitlabClient) RevokeProjectAccessToken(_ context.Context, tokenId int64, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.revokeErr != nil {
+		return m.revokeErr
+	}
+	m.revoked = append(m.revoked, tokenId)
+	return nil
+}
+
+func (m *mockGitlabClient) GetUserIdByUsername(_ context.Context, username string) (int64, error) {
+	m.usernames = append(m.usernames, username)
 	return 1, m.lookupErr
 }
 func (m *mockGitlabClient) GetProjectIdByPath(_ context.Context, _ string) (int64, error) {
@@ -57,13 +165,31 @@ func (m *mockGitlabClient) GetGroupIdByPath(_ context.Context, _ string) (int64,
 	return 1, m.lookupErr
 }
 
-func (m *mockGitlabClient) CreateProjectAccessToken(_ context.Context, _ string, _ string, _ time.Time, _ []string, _ tk.AccessLevel) (*mt.TokenProject, error) {
+func (m *mockGitlabClient) CreateProjectAccessToken(_ context.Context, path string, name string, _ time.Time, scopes []string, accessLevel tk.AccessLevel) (*mt.TokenProject, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paths = append(m.paths, path)
+	m.scopes, m.accessLevel = scopes, accessLevel
+	if path == m.failPath {
+		return nil, errTest
+	}
 	if m.createErr != nil || m.token == nil {
 		return nil, m.createErr
 	}
-	return m.token.(*mt.TokenProject), nil
+	// every call creates its own token, the tokens of a batch are created concurrently
+	var token = *m.token.(*mt.TokenProject)
+	token.Path, token.Name, token.TokenID = path, name, int64(len(m.paths))
+	m.listed = append(m.listed, &token)
+	return &token, m.createdErr
 }
-func (m *mockGitlabClient) CreateGroupAccessToken(_ context.Context, _ string, _ string, _ time.Time, _ []string, _ tk.AccessLevel) (*mt.TokenGroup, error) {
+
+func (m *mockGitlabClient) ListProjectAccessTokens(_ context.Context, _ string) ([]*mt.TokenProject, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listed, m.lookupErr
+}
+func (m *mockGitlabClient) CreateGroupAccessToken(_ context.Context, path string, _ string, _ time.Time, _ []string, _ tk.AccessLevel) (*mt.TokenGroup, error) {
+	m.paths = append(m.paths, path)
 	if m.createErr != nil || m.token == nil {
 		return nil, m.createErr
 	}
@@ -75,6 +201,12 @@ func (m *mockGitlabClient) CreatePersonalAccessToken(_ context.Context, _ string
 	}
 	return m.token.(*mt.TokenPersonal), nil
 }
+func (m *mockGitlabClient) CreateImpersonationToken(_ context.Context, _ string, _ int64, _ string, _ time.Time, _ []string) (*mt.TokenImpersonation, error) {
+	if m.createErr != nil || m.token == nil {
+		return nil, m.createErr
+	}
+	return m.token.(*mt.TokenImpersonation), nil
+}
 func (m *mockGitlabClient) CreateUserServiceAccountAccessToken(_ context.Context, _ string, _ int64, _ string, _ time.Time, _ []string) (*mt.TokenUserServiceAccount, error) {
 	if m.createErr != nil || m.token == nil {
 		return nil, m.createErr
@@ -105,6 +237,159 @@ func (m *mockGitlabClient) CreatePipelineProjectTriggerAccessToken(_ context.Con
 	}
 	return m.token.(*mt.TokenPipelineProjectTrigger), nil
 }
+func (m *mockGitlabClient) CreateGroupServiceAccount(_ context.Context, groupId string, name string) (int64, string, error) {
+	return 7, "service_account_group_" + groupId, m.accountErr
+}
+func (m *mockGitlabClient) CreateUserServiceAccount(_ context.Context, name string) (int64, string, error) {
+	return 7, "service_account", m.accountErr
+}
+func (m *mockGitlabClient) DeleteGroupServiceAccount(ctx context.Context, _ string, userId int64) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	m.deletedAccounts = append(m.deletedAccounts, userId)
+	return nil
+}
+func (m *mockGitlabClient) DeleteUserServiceAccount(ctx context.Context, userId int64) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	m.deletedAccounts = append(m.deletedAccounts, userId)
+	return nil
+}
+func (m *mockGitlabClient) AddMember(_ context.Context, path string, _ int64, accessLevel tk.AccessLevel, _ *time.Time) error {
+	if m.onMember != nil {
+		m.onMember()
+	}
+	if m.memberErr != nil {
+		return m.memberErr
+	}
+	if m.members == nil {
+		m.members = make(map[string]tk.AccessLevel)
+	}
+	m.members[path] = accessLevel
+	return nil
+}
+func (m *mockGitlabClient) CreateRunner(_ context.Context, tokenType tk.Type, path string, parentId int64, name string, description string, tags []string, runUntagged bool, locked bool) (*mt.TokenRunner, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	runner := &mt.TokenRunner{
+		Token:       mt.Token{TokenID: 3, ParentID: strconv.FormatInt(parentId, 10), Path: path, Name: name, Token: "glrt-test", TokenType: tokenType},
+		Description: description,
+		Tags:        tags,
+		RunUntagged: runUntagged,
+		Locked:      locked,
+	}
+	m.runners = append(m.runners, runner)
+	return runner, nil
+}
+func (m *mockGitlabClient) CreateClusterAgentToken(_ context.Context, path string, projectId int64, agentName string, createAgent bool, name string) (*mt.TokenClusterAgent, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	agentId, ok := m.agents[agentName]
+	if !ok {
+		if !createAgent {
+			return nil, errs.ErrNotFound
+		}
+		agentId = 3
+	}
+	return &mt.TokenClusterAgent{
+		Token:     mt.Token{TokenID: 5, ParentID: strconv.FormatInt(projectId, 10), Path: path, Name: name, Token: "glagent-test", TokenType: tk.TypeClusterAgent},
+		AgentID:   agentId,
+		AgentName: agentName,
+	}, nil
+}
+func (m *mockGitlabClient) CreateProjectDeployKey(_ context.Context, path string, projectId int64, name string, publicKey string, canPush bool, expiresAt *time.Time) (*mt.TokenProjectDeployKey, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	m.publicKeys = append(m.publicKeys, publicKey)
+	return &mt.TokenProjectDeployKey{
+		TokenSSHKey: mt.TokenSSHKey{
+			Token:     mt.Token{TokenID: 4, ParentID: strconv.FormatInt(projectId, 10), Path: path, Name: name, TokenType: tk.TypeProjectDeployKey, ExpiresAt: expiresAt},
+			PublicKey: publicKey,
+		},
+		CanPush: canPush,
+	}, nil
+}
+func (m *mockGitlabClient) CreateUserSSHKey(_ context.Context, username string, userId int64, name string, publicKey string, expiresAt *time.Time) (*mt.TokenUserSSHKey, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	m.publicKeys = append(m.publicKeys, publicKey)
+	return &mt.TokenUserSSHKey{
+		TokenSSHKey: mt.TokenSSHKey{
+			Token:     mt.Token{TokenID: 6, Path: username, Name: name, TokenType: tk.TypeUserSSHKey, ExpiresAt: expiresAt},
+			PublicKey: publicKey,
+		},
+		UserID:   userId,
+		Username: username,
+	}, nil
+}
+func (m *mockGitlabClient) CreateOAuthApplication(_ context.Context, path string, name string, redirectURIs []string, scopes []string, confidential bool) (*mt.TokenOAuthApplication, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	return &mt.TokenOAuthApplication{
+		TokenWithScopes: mt.TokenWithScopes{
+			Token:  mt.Token{TokenID: 7, Path: path, Name: name, Token: "gloas-test", TokenType: tk.TypeOAuthApplication},
+			Scopes: scopes,
+		},
+		ClientID:     "client-id",
+		RedirectURIs: redirectURIs,
+		Confidential: confidential,
+		Mode:         tk.OAuthApplicationModeCreate,
+	}, nil
+}
+func (m *mockGitlabClient) RenewOAuthApplicationSecret(_ context.Context, path string, applicationId int64) (*mt.TokenOAuthApplication, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	m.renewedApps = append(m.renewedApps, applicationId)
+	return &mt.TokenOAuthApplication{
+		TokenWithScopes: mt.TokenWithScopes{
+			Token: mt.Token{TokenID: applicationId, Path: path, Name: "app", Token: "gloas-renewed", TokenType: tk.TypeOAuthApplication},
+		},
+		ClientID: "client-id",
+		Mode:     tk.OAuthApplicationModeRotate,
+	}, nil
+}
+func (m *mockGitlabClient) AddJobTokenAllowlistEntry(_ context.Context, tokenType tk.Type, path string, projectId int64, allowedId int64) (*mt.TokenJobTokenAllowlist, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	m.allowlist = append(m.allowlist, allowedId)
+	return &mt.TokenJobTokenAllowlist{
+		Token:     mt.Token{TokenID: allowedId, ParentID: strconv.FormatInt(projectId, 10), Path: path, TokenType: tokenType},
+		ProjectID: projectId,
+		AllowedID: allowedId,
+	}, nil
+}
+func (m *mockGitlabClient) GetCIVariable(_ context.Context, target string, path string, key string, environmentScope string) (*variable.Variable, error) {
+	if v, ok := m.variables[target+"/"+path+"/"+key+"/"+environmentScope]; ok {
+		return v, nil
+	}
+	return nil, errs.ErrNotFound
+}
+func (m *mockGitlabClient) CreateCIVariable(_ context.Context, target string, path string, v *variable.Variable) error {
+	if m.variableErr != nil {
+		return m.variableErr
+	}
+	if m.variables == nil {
+		m.variables = make(map[string]*variable.Variable)
+	}
+	m.variables[target+"/"+path+"/"+v.Key+"/"+v.EnvironmentScope] = v
+	return nil
+}
+func (m *mockGitlabClient) UpdateCIVariable(ctx context.Context, target string, path string, v *variable.Variable) error {
+	return m.CreateCIVariable(ctx, target, path, v)
+}
+func (m *mockGitlabClient) DeleteCIVariable(_ context.Context, target string, path string, key string, environmentScope string) error {
+	delete(m.variables, target+"/"+path+"/"+key+"/"+environmentScope)
+	return nil
+}
 
 func newToken(tokenType tk.Type, now, expiresAt time.Time) tk.Token {
 	base := mt.Token{
@@ -121,6 +406,8 @@ func newToken(tokenType tk.Type, now, expiresAt time.Time) tk.Token {
 		return &mt.TokenGroup{TokenWithScopesAndAccessLevel: scopesAL}
 	case tk.TypePersonal:
 		return &mt.TokenPersonal{TokenWithScopes: scopes, UserID: 1}
+	case tk.TypeImpersonation:
+		return &mt.TokenImpersonation{TokenWithScopes: scopes, UserID: 1}
 	case tk.TypeUserServiceAccount:
 		return &mt.TokenUserServiceAccount{TokenWithScopes: scopes}
 	case tk.TypeGroupServiceAccount:
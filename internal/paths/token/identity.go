@@ -0,0 +1,86 @@
+package token
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+)
+
+// resolvePathTemplate returns the path of the role with its identity templates resolved for the Vault entity that
+// made the request. When the path of the role is a regex the resolved values are quoted, so a value can only ever
+// match itself.
+func (p *Provider) resolvePathTemplate(entityId string, role *modelRole.Role, quote bool) (path string, err error) {
+	var entity *logical.Entity
+	if entity, err = p.requestEntity(entityId); err != nil {
+		return "", err
+	}
+
+	var groups []*logical.Group
+	if groups, err = p.b.GroupsForEntity(entityId); err != nil {
+		return "", fmt.Errorf("groups of entity %s: %w", entityId, err)
+	}
+
+	if quote {
+		entity, groups = quoteEntity(entity), quoteGroups(groups)
+	}
+
+	_, path, err = identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		String: role.Path,
+		Entity: entity,
+		Groups: groups,
+		Mode:   identitytpl.ACLTemplating,
+	})
+	if err != nil {
+		// the entity doesn't have the values the role needs, so it is not entitled to a token from it
+		return "", fmt.Errorf("path template of role %s for entity %s: %w: %w", role.RoleName, entityId, err, logical.ErrPermissionDenied)
+	}
+
+	p.b.Logger().Debug("Resolved path template for entity", "role_name", role.RoleName, "entity_id", entityId, "path", path)
+	return path, nil
+}
+
+func quoteEntity(entity *logical.Entity) *logical.Entity {
+	var aliases = make([]*logical.Alias, 0, len(entity.Aliases))
+	for _, alias := range entity.Aliases {
+		aliases = append(aliases, &logical.Alias{
+			MountAccessor:  alias.MountAccessor,
+			ID:             alias.ID,
+			Name:           regexp.QuoteMeta(alias.Name),
+			Metadata:       quoteValues(alias.Metadata),
+			CustomMetadata: quoteValues(alias.CustomMetadata),
+		})
+	}
+	return &logical.Entity{
+		ID:       entity.ID,
+		Name:     regexp.QuoteMeta(entity.Name),
+		Aliases:  aliases,
+		Metadata: quoteValues(entity.Metadata),
+	}
+}
+
+func quoteGroups(groups []*logical.Group) []*logical.Group {
+	var quoted = make([]*logical.Group, 0, len(groups))
+	for _, group := range groups {
+		// the name selects the group in the template and is written by the operator, so it is kept as is
+		quoted = append(quoted, &logical.Group{
+			ID:          group.ID,
+			Name:        group.Name,
+			NamespaceID: group.NamespaceID,
+			Metadata:    quoteValues(group.Metadata),
+		})
+	}
+	return quoted
+}
+
+func quoteValues(values map[string]string) map[string]string {
+	var quoted = maps.Clone(values)
+	for k, v := range quoted {
+		quoted[k] = regexp.QuoteMeta(v)
+	}
+	return quoted
+}
@@ -0,0 +1,119 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestPathTokenRoleCreate_PathTemplate(t *testing.T) {
+	var entity = &logical.Entity{
+		ID:       "entity-id",
+		Name:     "alice",
+		Metadata: map[string]string{"gitlab_group": "team-a"},
+		Aliases: []*logical.Alias{
+			{MountAccessor: "auth_oidc_5678", Name: "alice", Metadata: map[string]string{"project_path": "team-a/app"}},
+		},
+	}
+
+	t.Run("from the metadata of the entity", func(t *testing.T) {
+		client := &mockGitlabClient{token: newToken(tk.TypeGroup, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{role: role(tk.TypeGroup, "{{identity.entity.metadata.gitlab_group}}"), entity: entity, client: client}
+		resp, err := callCreateAsEntity(t, mb, "entity-id", map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, []string{"team-a"}, client.paths)
+	})
+
+	t.Run("from the alias of the entity", func(t *testing.T) {
+		client := &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{role: role(tk.TypeProject, "{{identity.entity.aliases.auth_oidc_5678.metadata.project_path}}"), entity: entity, client: client}
+		resp, err := callCreateAsEntity(t, mb, "entity-id", map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, []string{"team-a/app"}, client.paths)
+	})
+
+	t.Run("from the groups of the entity", func(t *testing.T) {
+		client := &mockGitlabClient{token: newToken(tk.TypeGroup, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{
+			role:   role(tk.TypeGroup, "{{identity.groups.names.platform.metadata.gitlab_group}}"),
+			entity: entity,
+			groups: []*logical.Group{{ID: "group-id", Name: "platform", Metadata: map[string]string{"gitlab_group": "platform"}}},
+			client: client,
+		}
+		resp, err := callCreateAsEntity(t, mb, "entity-id", map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, []string{"platform"}, client.paths)
+	})
+
+	t.Run("dynamic path under the group of the entity", func(t *testing.T) {
+		r := role(tk.TypeProject, "^{{identity.entity.metadata.gitlab_group}}/[a-z-]+$")
+		r.DynamicPath = true
+		client := &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{role: r, entity: entity, client: client}
+		resp, err := callCreateAsEntity(t, mb, "entity-id", map[string]any{"role_name": "r", "path": "team-a/app"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, []string{"team-a/app"}, client.paths)
+	})
+
+	tests := []struct {
+		name     string
+		entityId string
+		path     string
+		entity   *logical.Entity
+		expected error
+	}{
+		{name: "request without an entity", path: "team-a/app", entity: entity, expected: logical.ErrPermissionDenied},
+		{name: "dynamic path of another group", entityId: "entity-id", path: "team-b/app", entity: entity, expected: errs.ErrInvalidValue},
+		{
+			name:     "metadata of the entity is missing",
+			entityId: "entity-id",
+			path:     "team-a/app",
+			entity:   &logical.Entity{ID: "entity-id", Name: "bob"},
+			expected: logical.ErrPermissionDenied,
+		},
+		{
+			// the value is quoted in the regex, so it can't match the path of another group
+			name:     "metadata of the entity is a regex",
+			entityId: "entity-id",
+			path:     "team-b/app",
+			entity:   &logical.Entity{ID: "entity-id", Metadata: map[string]string{"gitlab_group": "team-.*"}},
+			expected: errs.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := role(tk.TypeProject, "^{{identity.entity.metadata.gitlab_group}}/[a-z-]+$")
+			r.DynamicPath = true
+			client := &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}
+			mb := &mockTokenBackend{role: r, entity: tt.entity, client: client}
+			resp, err := callCreateAsEntity(t, mb, tt.entityId, map[string]any{"role_name": "r", "path": tt.path})
+			require.ErrorIs(t, err, tt.expected)
+			require.NotNil(t, resp)
+			assert.True(t, resp.IsError())
+			assert.Empty(t, client.paths)
+		})
+	}
+
+	t.Run("resolved path is not valid", func(t *testing.T) {
+		client := &mockGitlabClient{token: newToken(tk.TypeGroup, testNow, testExpiresAt)}
+		mb := &mockTokenBackend{
+			role:   role(tk.TypeGroup, "{{identity.entity.metadata.gitlab_group}}"),
+			entity: &logical.Entity{ID: "entity-id", Metadata: map[string]string{"gitlab_group": "team a"}},
+			client: client,
+		}
+		resp, err := callCreateAsEntity(t, mb, "entity-id", map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errs.ErrInvalidValue)
+		require.NotNil(t, resp)
+		assert.Empty(t, client.paths)
+	})
+}
@@ -0,0 +1,47 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// createJobTokenAllowlistEntry adds the project or group after the separator in the role path to the CI/CD job token
+// allowlist of the project before it. The entry is removed from the allowlist when the lease is revoked.
+func (p *Provider) createJobTokenAllowlistEntry(ctx context.Context, client gitlab.Client, role *modelRole.Role, name string) (token t.Token, err error) {
+	var projectPath, allowedPath, _ = strings.Cut(role.Path, t.JobTokenAllowlistSeparator)
+
+	var projectId, allowedId int64
+	if projectId, err = client.GetProjectIdByPath(ctx, projectPath); err != nil {
+		return nil, err
+	}
+	switch role.TokenType {
+	case t.TypeProjectJobTokenAllowlist:
+		allowedId, err = client.GetProjectIdByPath(ctx, allowedPath)
+	case t.TypeGroupJobTokenAllowlist:
+		allowedId, err = client.GetGroupIdByPath(ctx, allowedPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.b.Logger().Debug("Adding job token allowlist entry for role", "path", role.Path, "projectId", projectId, "allowedId", allowedId, "tokenType", role.TokenType)
+	var entry *modelToken.TokenJobTokenAllowlist
+	if entry, err = client.AddJobTokenAllowlistEntry(ctx, role.TokenType, role.Path, projectId, allowedId); err == nil && entry == nil {
+		err = fmt.Errorf("%w: token is nil", errs.ErrNilValue)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Name = name
+	entry.ProjectPath = projectPath
+	entry.AllowedPath = allowedPath
+	return entry, nil
+}
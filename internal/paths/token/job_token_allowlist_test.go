@@ -0,0 +1,53 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestPathTokenRoleCreate_JobTokenAllowlist(t *testing.T) {
+	t.Run("adds a project to the allowlist", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockTokenBackend{role: role(tk.TypeProjectJobTokenAllowlist, "group/app:group/consumer"), client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "job_token_allowlists", resp.Secret.InternalData["secret_type"])
+		assert.Equal(t, "group/app", resp.Data["project_path"])
+		assert.Equal(t, "group/consumer", resp.Data["allowed_path"])
+		assert.NotContains(t, resp.Data, "token")
+		assert.Equal(t, "1", resp.Secret.InternalData["parent_id"])
+		assert.Equal(t, []int64{1}, client.allowlist)
+		require.Len(t, mb.issued, 1)
+	})
+
+	t.Run("adds a group to the allowlist", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockTokenBackend{role: role(tk.TypeGroupJobTokenAllowlist, "group/app:other"), client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "other", resp.Data["allowed_path"])
+		assert.Equal(t, tk.TypeGroupJobTokenAllowlist.String(), resp.Data["token_type"])
+	})
+
+	t.Run("project lookup fails", func(t *testing.T) {
+		client := &mockGitlabClient{lookupErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeProjectJobTokenAllowlist, "group/app:group/consumer"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Empty(t, client.allowlist)
+	})
+
+	t.Run("adding the entry fails", func(t *testing.T) {
+		client := &mockGitlabClient{createErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeGroupJobTokenAllowlist, "group/app:other"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Empty(t, mb.issued)
+	})
+}
@@ -0,0 +1,50 @@
+package token
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// createMembership adds the user in the last segment of the role path as a member of the group or project in
+// the rest of the path. GitLab refuses to add a user that is already a member, so an existing membership is
+// never taken over and removed on revoke.
+func (p *Provider) createMembership(ctx context.Context, client gitlab.Client, role *modelRole.Role, name string, expiresAt time.Time) (token t.Token, err error) {
+	var target, username string
+	{
+		idx := strings.LastIndex(role.Path, "/")
+		target, username = role.Path[:idx], role.Path[idx+1:]
+	}
+
+	var userId int64
+	if userId, err = client.GetUserIdByUsername(ctx, username); err != nil {
+		return nil, err
+	}
+
+	p.b.Logger().Debug("Adding membership for role", "path", target, "username", username, "userId", userId, "accessLevel", role.AccessLevel, "expiresAt", expiresAt)
+	if err = client.AddMember(ctx, target, userId, role.AccessLevel, &expiresAt); err != nil {
+		return nil, err
+	}
+
+	var createdAt = utils.TimeFromContext(ctx).UTC()
+	return &modelToken.TokenMembership{
+		Token: modelToken.Token{
+			TokenID:   userId,
+			ParentID:  target,
+			Path:      role.Path,
+			Name:      name,
+			TokenType: t.TypeMembership,
+			CreatedAt: &createdAt,
+			ExpiresAt: &expiresAt,
+		},
+		UserID:      userId,
+		Username:    username,
+		AccessLevel: role.AccessLevel,
+	}, nil
+}
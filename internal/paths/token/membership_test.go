@@ -0,0 +1,43 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestPathTokenRoleCreate_Membership(t *testing.T) {
+	t.Run("adds the user as a member", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockTokenBackend{role: role(tk.TypeMembership, "group/project/alice"), client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "memberships", resp.Secret.InternalData["secret_type"])
+		assert.Equal(t, "alice", resp.Data["username"])
+		assert.Equal(t, "group/project", resp.Secret.InternalData["parent_id"])
+		assert.NotContains(t, resp.Data, "token")
+		assert.Equal(t, map[string]tk.AccessLevel{"group/project": tk.AccessLevelDeveloperPermissions}, client.members)
+		require.Len(t, mb.issued, 1)
+		assert.EqualValues(t, 1, mb.issued[0].TokenID)
+	})
+
+	t.Run("user lookup fails", func(t *testing.T) {
+		client := &mockGitlabClient{lookupErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeMembership, "group/alice"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Empty(t, client.members)
+	})
+
+	t.Run("adding the member fails", func(t *testing.T) {
+		client := &mockGitlabClient{memberErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeMembership, "group/alice"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Empty(t, mb.issued)
+	})
+}
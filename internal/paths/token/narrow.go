@@ -0,0 +1,57 @@
+package token
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// narrowRole applies the ttl, scopes and access_level of the request to the role. The role is the ceiling, a request
+// can only ask for a shorter ttl, a subset of the scopes and the same or a lower access level.
+func narrowRole(role *modelRole.Role, data *framework.FieldData) (err error) {
+	if val, ok := data.GetOk("ttl"); ok {
+		var ttl = time.Duration(val.(int)) * time.Second
+		if ttl <= 0 || ttl > role.TTL {
+			err = multierror.Append(err, fmt.Errorf("ttl = %s [0 < ttl <= %s]: %w", ttl, role.TTL, errs.ErrFieldInvalidValue))
+		} else {
+			// the requested ttl is the whole lifetime of the token, the lease can't be renewed past it
+			role.TTL, role.MaxTTL = ttl, ttl
+		}
+	}
+
+	if val, ok := data.GetOk("scopes"); ok {
+		var scopes = val.([]string)
+		var extra = slices.DeleteFunc(slices.Clone(scopes), func(scope string) bool { return slices.Contains(role.Scopes, scope) })
+		switch {
+		case len(scopes) == 0:
+			err = multierror.Append(err, fmt.Errorf("scopes cannot be empty: %w", errs.ErrFieldInvalidValue))
+		case len(extra) > 0:
+			err = multierror.Append(err, fmt.Errorf("scopes %v are not in the scopes of the role %v: %w", extra, role.Scopes, errs.ErrFieldInvalidValue))
+		default:
+			role.Scopes = scopes
+		}
+	}
+
+	if val, ok := data.GetOk("access_level"); ok {
+		var accessLevel, e = t.ParseAccessLevel(val.(string))
+		switch {
+		case e != nil:
+			err = multierror.Append(err, fmt.Errorf("access_level: %w: %w", e, errs.ErrFieldInvalidValue))
+		case role.AccessLevel == t.AccessLevelUnknown:
+			err = multierror.Append(err, fmt.Errorf("access_level cannot be used with token_type='%s': %w", role.TokenType, errs.ErrFieldInvalidValue))
+		case accessLevel.Value() > role.AccessLevel.Value() || !t.IsAccessLevelAllowed(role.TokenType, accessLevel, ""):
+			err = multierror.Append(err, fmt.Errorf("access_level='%s' should be at most '%s' and allowed for token_type='%s': %w", accessLevel, role.AccessLevel, role.TokenType, errs.ErrFieldInvalidValue))
+		default:
+			role.AccessLevel = accessLevel
+		}
+	}
+
+	return err
+}
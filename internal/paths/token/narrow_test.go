@@ -0,0 +1,79 @@
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestPathTokenRoleCreate_Narrowing(t *testing.T) {
+	newRole := func() *mockTokenBackend {
+		r := role(tk.TypeProject, "p")
+		r.MaxTTL = 24 * time.Hour
+		r.Scopes = []string{tk.ScopeApi.String(), tk.ScopeReadApi.String(), tk.ScopeReadRepository.String()}
+		return &mockTokenBackend{role: r, client: &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}}
+	}
+
+	t.Run("defaults to the role", func(t *testing.T) {
+		mb := newRole()
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, resp.Secret.TTL)
+		assert.Equal(t, 24*time.Hour, resp.Secret.MaxTTL)
+		assert.Equal(t, mb.role.Scopes, mb.client.(*mockGitlabClient).scopes)
+		assert.Equal(t, tk.AccessLevelDeveloperPermissions, mb.client.(*mockGitlabClient).accessLevel)
+	})
+
+	t.Run("narrowed by the request", func(t *testing.T) {
+		mb := newRole()
+		resp, err := callCreate(t, mb, map[string]any{
+			"role_name":    "r",
+			"ttl":          "10m",
+			"scopes":       tk.ScopeReadApi.String(),
+			"access_level": tk.AccessLevelGuestPermissions.String(),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Minute, resp.Secret.TTL)
+		assert.Equal(t, 10*time.Minute, resp.Secret.MaxTTL)
+		assert.Equal(t, []string{tk.ScopeReadApi.String()}, mb.client.(*mockGitlabClient).scopes)
+		assert.Equal(t, tk.AccessLevelGuestPermissions, mb.client.(*mockGitlabClient).accessLevel)
+	})
+
+	tests := []struct {
+		name   string
+		raw    map[string]any
+		errMsg string
+	}{
+		{name: "longer ttl", raw: map[string]any{"ttl": "2h"}, errMsg: "ttl = 2h0m0s"},
+		{name: "scope not in the role", raw: map[string]any{"scopes": "read_api,write_repository"}, errMsg: "scopes [write_repository] are not in the scopes of the role"},
+		{name: "empty scopes", raw: map[string]any{"scopes": ""}, errMsg: "scopes cannot be empty"},
+		{name: "higher access level", raw: map[string]any{"access_level": tk.AccessLevelMaintainerPermissions.String()}, errMsg: "access_level='maintainer' should be at most 'developer'"},
+		{name: "unknown access level", raw: map[string]any{"access_level": "superuser"}, errMsg: "access_level"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := newRole()
+			tt.raw["role_name"] = "r"
+			resp, err := callCreate(t, mb, tt.raw)
+			require.ErrorIs(t, err, errs.ErrFieldInvalidValue, "%v", err)
+			require.ErrorContains(t, err, tt.errMsg)
+			require.NotNil(t, resp)
+			assert.True(t, resp.IsError())
+			assert.Empty(t, mb.client.(*mockGitlabClient).paths)
+		})
+	}
+
+	t.Run("access level on a token type without one", func(t *testing.T) {
+		mb := &mockTokenBackend{role: role(tk.TypePersonal, "user"), client: &mockGitlabClient{token: newToken(tk.TypePersonal, testNow, testExpiresAt)}}
+		mb.role.AccessLevel = tk.AccessLevelUnknown
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r", "access_level": tk.AccessLevelGuestPermissions.String()})
+		require.ErrorIs(t, err, errs.ErrFieldInvalidValue)
+		assert.Empty(t, mb.client.(*mockGitlabClient).usernames)
+	})
+}
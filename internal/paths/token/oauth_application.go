@@ -0,0 +1,44 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// createOAuthApplication either creates a new OAuth application for the lease that is deleted when the lease is
+// revoked, or renews the secret of the existing application with the id in the role path. Both return the
+// client id and secret of the application.
+func (p *Provider) createOAuthApplication(ctx context.Context, client gitlab.Client, role *modelRole.Role, name string, startTime time.Time) (token t.Token, err error) {
+	var app *modelToken.TokenOAuthApplication
+	switch role.OAuthMode {
+	case t.OAuthApplicationModeRotate:
+		var applicationId int64
+		if applicationId, err = strconv.ParseInt(role.Path, 10, 64); err != nil {
+			return nil, fmt.Errorf("path '%s' is not an application id: %w", role.Path, errs.ErrInvalidValue)
+		}
+		p.b.Logger().Debug("Renewing OAuth application secret for role", "path", role.Path, "applicationId", applicationId)
+		if app, err = client.RenewOAuthApplicationSecret(ctx, role.Path, applicationId); err == nil && app != nil {
+			// the issued token record of the application is replaced by every lease, the revocation of an older
+			// lease compares the time with it to leave the secret of the newer lease alone
+			app.RenewedAt = startTime
+		}
+	default:
+		p.b.Logger().Debug("Creating OAuth application for role", "path", role.Path, "name", name, "redirectURIs", role.OAuthRedirectURIs, "scopes", role.Scopes, "confidential", role.OAuthConfidential)
+		app, err = client.CreateOAuthApplication(ctx, role.Path, name, role.OAuthRedirectURIs, role.Scopes, role.OAuthConfidential)
+	}
+	if err == nil && app == nil {
+		err = fmt.Errorf("%w: token is nil", errs.ErrNilValue)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
@@ -0,0 +1,67 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestPathTokenRoleCreate_OAuthApplication(t *testing.T) {
+	t.Run("creates an application", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		r := role(tk.TypeOAuthApplication, "dashboard")
+		r.Scopes = []string{"openid", "profile"}
+		r.OAuthMode = tk.OAuthApplicationModeCreate
+		r.OAuthRedirectURIs = []string{"https://dashboard.example.com/callback"}
+		r.OAuthConfidential = true
+		mb := &mockTokenBackend{role: r, client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "client-id", resp.Data["client_id"])
+		assert.Equal(t, "gloas-test", resp.Data["client_secret"])
+		assert.NotContains(t, resp.Data, "token")
+		assert.Equal(t, []string{"https://dashboard.example.com/callback"}, resp.Data["redirect_uris"])
+		assert.Equal(t, tk.OAuthApplicationModeCreate, resp.Secret.InternalData["oauth_mode"])
+		assert.Empty(t, client.renewedApps)
+	})
+
+	t.Run("rotates the secret of an existing application", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		r := role(tk.TypeOAuthApplication, "42")
+		r.OAuthMode = tk.OAuthApplicationModeRotate
+		mb := &mockTokenBackend{role: r, client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "gloas-renewed", resp.Data["client_secret"])
+		assert.Equal(t, tk.OAuthApplicationModeRotate, resp.Secret.InternalData["oauth_mode"])
+		assert.Equal(t, []int64{42}, client.renewedApps)
+		require.Len(t, mb.issued, 1)
+		assert.Equal(t, mb.issued[0].IssuedAt, resp.Secret.InternalData["renewed_at"])
+	})
+
+	t.Run("rotate with a path that is not an application id", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		r := role(tk.TypeOAuthApplication, "dashboard")
+		r.OAuthMode = tk.OAuthApplicationModeRotate
+		mb := &mockTokenBackend{role: r, client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errs.ErrInvalidValue)
+		assert.Empty(t, client.renewedApps)
+	})
+
+	t.Run("creating the application fails", func(t *testing.T) {
+		client := &mockGitlabClient{createErr: errTest}
+		r := role(tk.TypeOAuthApplication, "dashboard")
+		r.OAuthMode = tk.OAuthApplicationModeCreate
+		mb := &mockTokenBackend{role: r, client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Empty(t, mb.issued)
+	})
+}
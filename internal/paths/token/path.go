@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -36,6 +37,21 @@ var (
 			Description: "Overwrites the role path, only available if the role has dynamic-path set to true",
 			Required:    false,
 		},
+		"ttl": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Shorter lifetime of the token than the ttl of the role, the lease can't be renewed past it",
+			Required:    false,
+		},
+		"scopes": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "Subset of the scopes of the role to create the token with",
+			Required:    false,
+		},
+		"access_level": {
+			Type:        framework.TypeString,
+			Description: "Access level of the token, the same as or lower than the access level of the role",
+			Required:    false,
+		},
 	}
 )
 
@@ -44,27 +60,43 @@ type tokenBackend interface {
 	backend.Logging
 	backend.Locker
 	backend.RoleStore
+	backend.IssuedTokenStore
+	backend.PoolStore
+	backend.ReuseStore
+	backend.CIVariableStore
 	backend.ClientReader
 	backend.EventSender
+	backend.EntityReader
 }
 
-// Provider implements backend.PathProvider for the token role path.
+// Provider implements backend.PathProvider, backend.PeriodicHandler and backend.WALRollbackHandler for token paths.
 type Provider struct {
-	b      tokenBackend
-	secret *framework.Secret
+	b                tokenBackend
+	secret           *framework.Secret
+	membershipSecret *framework.Secret
+	allowlistSecret  *framework.Secret
+	batchSecret      *framework.Secret
+
+	slotsMu sync.Mutex
+	slots   map[string]chan struct{}
+
+	// poolMu serializes taking tokens out of the pools, so a pooled token is only handed out once
+	poolMu sync.Mutex
 }
 
 func (p *Provider) Name() string { return "token" }
 
 // New creates a new token path provider.
-// The secret parameter is the framework.Secret for access tokens (injected, not from the interface).
-func New(b tokenBackend, s *framework.Secret) *Provider {
-	return &Provider{b: b, secret: s}
+// The secret parameters are the framework.Secret for access tokens, memberships, job token allowlist entries and
+// batches of access tokens (injected, not from the interface).
+func New(b tokenBackend, s *framework.Secret, ms *framework.Secret, as *framework.Secret, bs *framework.Secret) *Provider {
+	return &Provider{b: b, secret: s, membershipSecret: ms, allowlistSecret: as, batchSecret: bs}
 }
 
-// Paths returns the framework paths for token generation.
+// Paths returns the framework paths for token generation. The batch path comes first, so it is matched before the
+// optional path of a role with a dynamic path.
 func (p *Provider) Paths() []*framework.Path {
-	return []*framework.Path{p.pathTokenRoles()}
+	return []*framework.Path{p.pathTokenRolesBatch(), p.pathTokenRoles()}
 }
 
 func (p *Provider) pathTokenRoles() *framework.Path {
@@ -12,16 +12,17 @@ import (
 )
 
 func TestProvider_Name(t *testing.T) {
-	p := pathtoken.New(&mockTokenBackend{}, &framework.Secret{})
+	p := pathtoken.New(&mockTokenBackend{}, &framework.Secret{}, &framework.Secret{}, &framework.Secret{}, &framework.Secret{})
 	assert.Equal(t, "token", p.Name())
 }
 
 func TestProvider_Paths(t *testing.T) {
-	p := pathtoken.New(&mockTokenBackend{}, &framework.Secret{})
+	p := pathtoken.New(&mockTokenBackend{}, &framework.Secret{}, &framework.Secret{}, &framework.Secret{}, &framework.Secret{})
 	paths := p.Paths()
-	require.Len(t, paths, 1)
+	require.Len(t, paths, 2)
+	assert.Contains(t, paths[0].Pattern, "/batch$")
 
-	path := paths[0]
+	path := paths[1]
 	assert.NotNil(t, path.Operations[logical.ReadOperation])
 	assert.NotNil(t, path.Operations[logical.UpdateOperation])
 	assert.Contains(t, path.Pattern, pathtoken.PathTokenRoleStorage)
@@ -0,0 +1,208 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/pool"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// takePooledToken hands out the oldest usable token from the pool of the role. Like a created token it is guarded by
+// a WAL entry until the caller hands it over to a lease. If there is no usable token nil is returned, and the caller
+// creates one as usual.
+func (p *Provider) takePooledToken(ctx context.Context, s logical.Storage, role *modelRole.Role, startTime time.Time) (token t.Token, walId string, err error) {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+
+	var entries []*pool.Entry
+	if entries, err = p.pooledTokens(ctx, s, role.RoleName); err != nil {
+		return nil, "", err
+	}
+
+	// a narrowed request doesn't match the hash of the role, so it never gets a pooled token
+	var idx = slices.IndexFunc(entries, func(e *pool.Entry) bool { return e.Usable(startTime, *role) })
+	if idx < 0 {
+		return nil, "", nil
+	}
+
+	var entry = entries[idx]
+	if walId, err = framework.PutWAL(ctx, s, walKindToken, revocation.New(entry)); err != nil {
+		return nil, "", fmt.Errorf("write wal entry: %w", err)
+	}
+
+	if err = p.b.DeletePooledToken(ctx, s, role.RoleName, entry.GetName()); err != nil {
+		_ = framework.DeleteWAL(ctx, s, walId)
+		return nil, "", fmt.Errorf("delete pooled token: %w", err)
+	}
+
+	// the token in GitLab outlives the pool by max_ttl, the lease starts now
+	var expiresAt = startTime.Add(role.GetMaxTTL())
+	entry.SetExpiresAt(&expiresAt)
+
+	if err = p.b.SaveIssuedToken(ctx, s, issued.New(entry, startTime)); err != nil {
+		return nil, walId, err
+	}
+
+	p.b.Logger().Debug("Handing out pooled token", "role_name", role.RoleName, "token_id", entry.TokenID, "age", startTime.Sub(entry.CreatedAt))
+	return entry, walId, nil
+}
+
+// PeriodicFunc implements backend.PeriodicHandler.
+// It fills the pools of the roles up to their size. Tokens that are older than the max age of the pool, or were
+// created for a previous version of the role, are revoked and replaced. The pools of roles that were deleted, or no
+// longer have a pool, are revoked.
+func (p *Provider) PeriodicFunc(ctx context.Context, req *logical.Request) (err error) {
+	var roleNames, poolNames []string
+	if roleNames, err = req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathRoleStorage)); err != nil {
+		return err
+	}
+	if poolNames, err = req.Storage.List(ctx, fmt.Sprintf("%s/", backend.PathPoolStorage)); err != nil {
+		return err
+	}
+
+	for _, name := range poolNames {
+		if name = strings.TrimSuffix(name, "/"); !slices.Contains(roleNames, name) {
+			roleNames = append(roleNames, name)
+		}
+	}
+
+	for _, name := range roleNames {
+		err = errors.Join(err, p.refillPool(ctx, req, name))
+	}
+
+	return err
+}
+
+func (p *Provider) refillPool(ctx context.Context, req *logical.Request, roleName string) (err error) {
+	var now = utils.TimeFromContext(ctx).UTC()
+	var role *modelRole.Role
+	if role, err = p.b.GetRole(ctx, req.Storage, roleName); err != nil {
+		return err
+	}
+
+	var stale []t.Token
+	var staleWalIds []string
+	var available int
+	stale, staleWalIds, available, err = p.drainPool(ctx, req.Storage, roleName, role, now)
+	if len(stale) > 0 {
+		p.b.Logger().Debug("Revoking stale pooled tokens", "role_name", roleName, "tokens", len(stale))
+		p.rollbackTokens(ctx, req, stale, staleWalIds)
+	}
+	if err != nil {
+		return err
+	}
+
+	if role == nil || available >= role.PoolSize {
+		return nil
+	}
+
+	var client gitlab.Client
+	if client, err = p.b.GetClientByName(ctx, req.Storage, role.ConfigName); err != nil {
+		return err
+	}
+
+	// the token has to last for a whole lease after it waited in the pool
+	var roles = make([]*modelRole.Role, role.PoolSize-available)
+	for i := range roles {
+		var r = *role
+		r.MaxTTL = role.GetMaxTTL() + role.PoolMaxAge
+		roles[i] = &r
+	}
+
+	p.b.Logger().Debug("Filling pool of role", "role_name", roleName, "available", available, "pool_size", role.PoolSize)
+	var tokens, walIds, issueErr = p.issueTokens(ctx, req.Storage, client, roles, now)
+
+	var hash = pool.RoleHash(*role)
+	for i, token := range tokens {
+		if token == nil {
+			continue
+		}
+		// the record is marked until the token is handed out, so it isn't mistaken for a leased token
+		var record = issued.New(token, now)
+		record.Pooled = true
+		if err = p.b.SaveIssuedToken(ctx, req.Storage, record); err != nil {
+			issueErr = errors.Join(issueErr, fmt.Errorf("mark pooled token: %w", err))
+			continue
+		}
+		if err = p.b.SavePooledToken(ctx, req.Storage, pool.New(token, now, hash)); err != nil {
+			issueErr = errors.Join(issueErr, fmt.Errorf("save pooled token: %w", err))
+			continue
+		}
+		// from here on the token is owned by the pool
+		_ = framework.DeleteWAL(ctx, req.Storage, walIds[i])
+		walIds[i] = ""
+	}
+
+	if issueErr != nil {
+		p.rollbackTokens(ctx, req, tokens, walIds)
+		return fmt.Errorf("fill pool of role %s: %w", roleName, issueErr)
+	}
+
+	return nil
+}
+
+// drainPool takes the tokens that can no longer be handed out from the pool of the role, every token is guarded by
+// a WAL entry until it is revoked. It returns the number of tokens that are left in the pool.
+func (p *Provider) drainPool(ctx context.Context, s logical.Storage, roleName string, role *modelRole.Role, now time.Time) (tokens []t.Token, walIds []string, available int, err error) {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+
+	var entries []*pool.Entry
+	if entries, err = p.pooledTokens(ctx, s, roleName); err != nil {
+		return nil, nil, 0, err
+	}
+
+	for _, entry := range entries {
+		if role != nil && available < role.PoolSize && entry.Usable(now, *role) {
+			available++
+			continue
+		}
+
+		var walId string
+		if walId, err = framework.PutWAL(ctx, s, walKindToken, revocation.New(entry)); err != nil {
+			return tokens, walIds, available, fmt.Errorf("write wal entry: %w", err)
+		}
+		if err = p.b.DeletePooledToken(ctx, s, roleName, entry.GetName()); err != nil {
+			_ = framework.DeleteWAL(ctx, s, walId)
+			return tokens, walIds, available, fmt.Errorf("delete pooled token: %w", err)
+		}
+		tokens, walIds = append(tokens, entry), append(walIds, walId)
+	}
+
+	return tokens, walIds, available, nil
+}
+
+// pooledTokens returns the tokens in the pool of the role, the oldest first.
+func (p *Provider) pooledTokens(ctx context.Context, s logical.Storage, roleName string) (entries []*pool.Entry, err error) {
+	var names []string
+	if names, err = s.List(ctx, fmt.Sprintf("%s/%s/", backend.PathPoolStorage, roleName)); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		var entry *pool.Entry
+		if entry, err = p.b.GetPooledToken(ctx, s, roleName, name); err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b *pool.Entry) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return entries, nil
+}
@@ -0,0 +1,156 @@
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pathtoken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/token"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+type poolTest struct {
+	t       *testing.T
+	p       *pathtoken.Provider
+	mb      *mockTokenBackend
+	client  *mockGitlabClient
+	storage *logical.InmemStorage
+}
+
+func newPoolTest(t *testing.T) *poolTest {
+	t.Helper()
+	r := role(tk.TypeProject, "example/app")
+	r.PoolSize, r.PoolMaxAge = 3, time.Hour
+	client := &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}
+	mb := &mockTokenBackend{role: r, client: client}
+	storage := &logical.InmemStorage{}
+	require.NoError(t, storage.Put(t.Context(), &logical.StorageEntry{Key: "roles/r", Value: []byte("{}")}))
+	p := pathtoken.New(mb, &framework.Secret{Type: "access_tokens"}, &framework.Secret{Type: "memberships"}, &framework.Secret{Type: "job_token_allowlists"}, &framework.Secret{Type: "batch_access_tokens"})
+	return &poolTest{t: t, p: p, mb: mb, client: client, storage: storage}
+}
+
+func (pt *poolTest) periodic(now time.Time) error {
+	return pt.p.PeriodicFunc(utils.WithStaticTime(pt.t.Context(), now), &logical.Request{Storage: pt.storage})
+}
+
+func (pt *poolTest) create(now time.Time, raw map[string]any) (*logical.Response, error) {
+	path := pt.p.Paths()[1]
+	raw["role_name"] = "r"
+	fd := &framework.FieldData{Raw: raw, Schema: path.Fields}
+	return path.Operations[logical.ReadOperation].Handler()(utils.WithStaticTime(pt.t.Context(), now), &logical.Request{Storage: pt.storage}, fd)
+}
+
+func (pt *poolTest) pooled() []string {
+	keys, err := pt.storage.List(pt.t.Context(), "pool/r/")
+	require.NoError(pt.t, err)
+	return keys
+}
+
+func TestPathTokenRole_Pool(t *testing.T) {
+	t.Run("filled and handed out", func(t *testing.T) {
+		pt := newPoolTest(t)
+		require.NoError(t, pt.periodic(testNow))
+		assert.Len(t, pt.client.paths, 3)
+		assert.ElementsMatch(t, []string{"1", "2", "3"}, pt.pooled())
+		assert.Empty(t, walEntries(t, pt.storage))
+		for _, record := range pt.mb.issued[len(pt.mb.issued)-3:] {
+			assert.True(t, record.Pooled, "the record of a token in the pool is marked")
+		}
+
+		// a full pool is left alone
+		require.NoError(t, pt.periodic(testNow.Add(time.Minute)))
+		assert.Len(t, pt.client.paths, 3)
+
+		var handedOut = testNow.Add(30 * time.Minute)
+		resp, err := pt.create(handedOut, map[string]any{})
+		require.NoError(t, err)
+		require.NotNil(t, resp.Secret)
+		assert.Len(t, pt.client.paths, 3, "the token comes from the pool")
+		assert.Equal(t, "glpat-test", resp.Data["token"])
+		assert.Equal(t, time.Hour, resp.Secret.TTL)
+		assert.Equal(t, handedOut, resp.Secret.IssueTime)
+		assert.Len(t, pt.pooled(), 2)
+		assert.Empty(t, walEntries(t, pt.storage))
+
+		// the issued token expires with the lease, not when it was created
+		var last = pt.mb.issued[len(pt.mb.issued)-1]
+		assert.Equal(t, handedOut, last.IssuedAt)
+		assert.Equal(t, handedOut.Add(time.Hour), last.ExpiresAt)
+		assert.False(t, last.Pooled)
+	})
+
+	t.Run("narrowed request is not served from the pool", func(t *testing.T) {
+		pt := newPoolTest(t)
+		require.NoError(t, pt.periodic(testNow))
+		_, err := pt.create(testNow, map[string]any{"ttl": "10m"})
+		require.NoError(t, err)
+		assert.Len(t, pt.client.paths, 4)
+		assert.Len(t, pt.pooled(), 3)
+	})
+
+	t.Run("old tokens are revoked and replaced", func(t *testing.T) {
+		pt := newPoolTest(t)
+		require.NoError(t, pt.periodic(testNow))
+
+		// too old to be handed out, the token is created as usual
+		_, err := pt.create(testNow.Add(time.Hour), map[string]any{})
+		require.NoError(t, err)
+		assert.Len(t, pt.client.paths, 4)
+
+		require.NoError(t, pt.periodic(testNow.Add(time.Hour)))
+		assert.ElementsMatch(t, []int64{1, 2, 3}, pt.client.revoked)
+		assert.ElementsMatch(t, []int64{1, 2, 3}, pt.mb.deleted)
+		assert.ElementsMatch(t, []string{"5", "6", "7"}, pt.pooled())
+		assert.Empty(t, walEntries(t, pt.storage))
+	})
+
+	t.Run("changed role", func(t *testing.T) {
+		pt := newPoolTest(t)
+		require.NoError(t, pt.periodic(testNow))
+		pt.mb.role.Scopes = []string{"read_api"}
+		require.NoError(t, pt.periodic(testNow))
+		assert.ElementsMatch(t, []int64{1, 2, 3}, pt.client.revoked)
+		assert.Len(t, pt.pooled(), 3)
+	})
+
+	t.Run("smaller pool", func(t *testing.T) {
+		pt := newPoolTest(t)
+		require.NoError(t, pt.periodic(testNow))
+		pt.mb.role.PoolSize = 1
+		require.NoError(t, pt.periodic(testNow))
+		assert.Len(t, pt.client.revoked, 2)
+		assert.Len(t, pt.pooled(), 1)
+	})
+
+	t.Run("deleted role", func(t *testing.T) {
+		pt := newPoolTest(t)
+		require.NoError(t, pt.periodic(testNow))
+		pt.mb.role = nil
+		require.NoError(t, pt.storage.Delete(t.Context(), "roles/r"))
+		require.NoError(t, pt.periodic(testNow))
+		assert.ElementsMatch(t, []int64{1, 2, 3}, pt.client.revoked)
+		assert.Empty(t, pt.pooled())
+	})
+
+	t.Run("failure to fill", func(t *testing.T) {
+		pt := newPoolTest(t)
+		pt.client.createErr = errTest
+		require.ErrorIs(t, pt.periodic(testNow), errTest)
+		assert.Empty(t, pt.pooled())
+		assert.Empty(t, walEntries(t, pt.storage))
+	})
+
+	t.Run("failure to revoke keeps the wal entry", func(t *testing.T) {
+		pt := newPoolTest(t)
+		require.NoError(t, pt.periodic(testNow))
+		pt.client.revokeErr = errTest
+		require.NoError(t, pt.periodic(testNow.Add(time.Hour)))
+		assert.Len(t, walEntries(t, pt.storage), 3, "the rollback of Vault retries the revocation")
+		assert.Len(t, pt.pooled(), 3)
+	})
+}
@@ -0,0 +1,57 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+)
+
+// joinSharedToken returns a new lease on the token that an identical request was issued within the reuse window of
+// the role, the token is revoked when the last of its leases is revoked. If there is no such token nil is returned,
+// and the caller creates one as usual. The caller holds the lock of the key.
+func (p *Provider) joinSharedToken(ctx context.Context, s logical.Storage, role *modelRole.Role, key string, now time.Time) (resp *logical.Response, err error) {
+	var names []string
+	if names, err = s.List(ctx, fmt.Sprintf("%s/%s/", backend.PathReuseStorage, key)); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		var entry *reuse.Entry
+		if entry, err = p.b.GetSharedToken(ctx, s, key, name); err != nil {
+			return nil, err
+		}
+		if entry == nil || !entry.Joinable(now, role.ReuseWindow) {
+			continue
+		}
+
+		entry.Leases++
+		if err = p.b.SaveSharedToken(ctx, s, entry); err != nil {
+			return nil, fmt.Errorf("save shared token: %w", err)
+		}
+
+		var internal = entry.Internal()
+		internal["reuse_key"] = key
+		resp = p.secret.Response(entry.Data(), internal)
+
+		// the lease starts now, but neither it nor its renewals can outlive the token it shares
+		var remaining = entry.ExpiresAt.Sub(now)
+		resp.Secret.MaxTTL = min(role.GetMaxTTL(), remaining)
+		resp.Secret.TTL = min(role.TTL, remaining)
+		resp.Secret.IssueTime = now
+
+		p.b.Logger().Debug("Sharing token of role", "role_name", role.RoleName, "token_id", entry.TokenID, "leases", entry.Leases)
+		_ = p.b.SendEvent(
+			ctx, eventWrite,
+			entry.Event(map[string]string{"path": fmt.Sprintf("%s/%s", backend.PathRoleStorage, role.RoleName), "reused": "true"}),
+		)
+		return resp, nil
+	}
+
+	return nil, nil
+}
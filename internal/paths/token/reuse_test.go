@@ -0,0 +1,113 @@
+package token_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	pathtoken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/token"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+type reuseTest struct {
+	t       *testing.T
+	p       *pathtoken.Provider
+	mb      *mockTokenBackend
+	client  *mockGitlabClient
+	storage *logical.InmemStorage
+}
+
+func newReuseTest(t *testing.T) *reuseTest {
+	t.Helper()
+	r := role(tk.TypeProject, "example/app")
+	r.ReuseWindow = time.Minute
+	client := &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)}
+	mb := &mockTokenBackend{role: r, client: client}
+	p := pathtoken.New(mb, &framework.Secret{Type: "access_tokens"}, &framework.Secret{Type: "memberships"}, &framework.Secret{Type: "job_token_allowlists"}, &framework.Secret{Type: "batch_access_tokens"})
+	return &reuseTest{t: t, p: p, mb: mb, client: client, storage: &logical.InmemStorage{}}
+}
+
+func (rt *reuseTest) create(now time.Time, entityId string, raw map[string]any) *logical.Response {
+	rt.t.Helper()
+	path := rt.p.Paths()[1]
+	raw["role_name"] = "r"
+	fd := &framework.FieldData{Raw: raw, Schema: path.Fields}
+	resp, err := path.Operations[logical.ReadOperation].Handler()(utils.WithStaticTime(rt.t.Context(), now), &logical.Request{Storage: rt.storage, EntityID: entityId}, fd)
+	require.NoError(rt.t, err)
+	require.NotNil(rt.t, resp)
+	require.NotNil(rt.t, resp.Secret)
+	return resp
+}
+
+func (rt *reuseTest) shared(resp *logical.Response) *reuse.Entry {
+	rt.t.Helper()
+	key, _ := resp.Secret.InternalData["reuse_key"].(string)
+	require.NotEmpty(rt.t, key)
+	entry, err := model.Get[reuse.Entry](rt.t.Context(), rt.storage, "reuse/"+key+"/"+fmt.Sprint(resp.Secret.InternalData["token_id"]))
+	require.NoError(rt.t, err)
+	require.NotNil(rt.t, entry)
+	return entry
+}
+
+func TestPathTokenRole_Reuse(t *testing.T) {
+	t.Run("shared within the window", func(t *testing.T) {
+		rt := newReuseTest(t)
+		first := rt.create(testNow, "entity", map[string]any{})
+		assert.Len(t, rt.client.paths, 1)
+		assert.Equal(t, time.Hour, first.Secret.TTL)
+
+		var joinedAt = testNow.Add(30 * time.Second)
+		second := rt.create(joinedAt, "entity", map[string]any{})
+		assert.Len(t, rt.client.paths, 1, "the token is not created again")
+		assert.Equal(t, first.Data["token"], second.Data["token"])
+		assert.Equal(t, first.Secret.InternalData["reuse_key"], second.Secret.InternalData["reuse_key"])
+		assert.Equal(t, joinedAt, second.Secret.IssueTime)
+		assert.Equal(t, time.Hour-30*time.Second, second.Secret.TTL, "the lease can't outlive the token")
+		assert.Equal(t, time.Hour-30*time.Second, second.Secret.MaxTTL, "the lease can't be renewed past the token")
+		assert.Len(t, rt.mb.issued, 1)
+		assert.Equal(t, 2, rt.shared(second).Leases)
+		assert.Empty(t, walEntries(t, rt.storage))
+	})
+
+	t.Run("outside of the window", func(t *testing.T) {
+		rt := newReuseTest(t)
+		rt.create(testNow, "entity", map[string]any{})
+		second := rt.create(testNow.Add(time.Minute), "entity", map[string]any{})
+		assert.Len(t, rt.client.paths, 2)
+		assert.Equal(t, 1, rt.shared(second).Leases)
+	})
+
+	t.Run("other entity", func(t *testing.T) {
+		rt := newReuseTest(t)
+		rt.create(testNow, "entity", map[string]any{})
+		rt.create(testNow, "other", map[string]any{})
+		assert.Len(t, rt.client.paths, 2)
+	})
+
+	t.Run("narrowed request", func(t *testing.T) {
+		rt := newReuseTest(t)
+		rt.create(testNow, "entity", map[string]any{})
+		rt.create(testNow, "entity", map[string]any{"ttl": "10m"})
+		assert.Len(t, rt.client.paths, 2)
+	})
+
+	t.Run("request without an entity", func(t *testing.T) {
+		rt := newReuseTest(t)
+		resp := rt.create(testNow, "", map[string]any{})
+		rt.create(testNow, "", map[string]any{})
+		assert.Len(t, rt.client.paths, 2)
+		assert.NotContains(t, resp.Secret.InternalData, "reuse_key")
+
+		keys, err := rt.storage.List(t.Context(), "reuse/")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+}
@@ -0,0 +1,39 @@
+package token
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+// createRunner creates a new runner for the lease in the group or project of the role path, or on the
+// instance, and returns its authentication token. The runner is deleted when the lease is revoked.
+func (p *Provider) createRunner(ctx context.Context, client gitlab.Client, role *modelRole.Role, name string) (token t.Token, err error) {
+	var parentId int64
+	switch role.TokenType {
+	case t.TypeGroupRunner:
+		parentId, err = client.GetGroupIdByPath(ctx, role.Path)
+	case t.TypeProjectRunner:
+		parentId, err = client.GetProjectIdByPath(ctx, role.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var description = cmp.Or(role.RunnerDescription, name)
+	p.b.Logger().Debug("Creating runner for role", "path", role.Path, "parentId", parentId, "name", name, "description", description, "tags", role.RunnerTags, "runUntagged", role.RunnerRunUntagged, "locked", role.RunnerLocked)
+	var runner *modelToken.TokenRunner
+	if runner, err = client.CreateRunner(ctx, role.TokenType, role.Path, parentId, name, description, role.RunnerTags, role.RunnerRunUntagged, role.RunnerLocked); err == nil && runner == nil {
+		err = fmt.Errorf("%w: token is nil", errs.ErrNilValue)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
@@ -0,0 +1,57 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestPathTokenRoleCreate_Runner(t *testing.T) {
+	t.Run("creates a group runner", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		r := role(tk.TypeGroupRunner, "group")
+		r.RunnerTags = []string{"docker"}
+		r.RunnerLocked = true
+		mb := &mockTokenBackend{role: r, client: client}
+		resp, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, "glrt-test", resp.Data["token"])
+		assert.Equal(t, []string{"docker"}, resp.Data["tags"])
+		assert.Equal(t, "1", resp.Secret.InternalData["parent_id"])
+		require.Len(t, client.runners, 1)
+		assert.Equal(t, "n", client.runners[0].Description)
+		assert.True(t, client.runners[0].Locked)
+	})
+
+	t.Run("creates an instance runner with a description", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		r := role(tk.TypeInstanceRunner, "instance")
+		r.RunnerDescription = "shared runner"
+		mb := &mockTokenBackend{role: r, client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.NoError(t, err)
+		require.Len(t, client.runners, 1)
+		assert.Equal(t, "shared runner", client.runners[0].Description)
+		assert.Equal(t, "0", client.runners[0].ParentID)
+	})
+
+	t.Run("project lookup fails", func(t *testing.T) {
+		client := &mockGitlabClient{lookupErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeProjectRunner, "group/project"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Empty(t, client.runners)
+	})
+
+	t.Run("creating the runner fails", func(t *testing.T) {
+		client := &mockGitlabClient{createErr: errTest}
+		mb := &mockTokenBackend{role: role(tk.TypeProjectRunner, "group/project"), client: client}
+		_, err := callCreate(t, mb, map[string]any{"role_name": "r"})
+		require.ErrorIs(t, err, errTest)
+		assert.Empty(t, mb.issued)
+	})
+}
@@ -0,0 +1,69 @@
+package token
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelRole "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/role"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+// createProjectDeployKey generates a new key pair and adds the public key as a deploy key of the project in the
+// role path. Only the public key is sent to GitLab, the private key is returned with the lease.
+func (p *Provider) createProjectDeployKey(ctx context.Context, client gitlab.Client, role *modelRole.Role, name string, expiresAt time.Time) (token t.Token, err error) {
+	var projectId int64
+	if projectId, err = client.GetProjectIdByPath(ctx, role.Path); err != nil {
+		return nil, err
+	}
+
+	var publicKey, privateKey, fingerprint string
+	if publicKey, privateKey, fingerprint, err = utils.GenerateSSHKey(name); err != nil {
+		return nil, err
+	}
+
+	p.b.Logger().Debug("Creating project deploy key for role", "path", role.Path, "projectId", projectId, "name", name, "canPush", role.CanPush, "expiresAt", expiresAt)
+	var dk *modelToken.TokenProjectDeployKey
+	if dk, err = client.CreateProjectDeployKey(ctx, role.Path, projectId, name, publicKey, role.CanPush, &expiresAt); err == nil && dk == nil {
+		err = fmt.Errorf("%w: token is nil", errs.ErrNilValue)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dk.Token.Token = privateKey
+	dk.Fingerprint = cmp.Or(dk.Fingerprint, fingerprint)
+	return dk, nil
+}
+
+// createUserSSHKey generates a new key pair and adds the public key to the user in the role path, this requires
+// an admin token. The private key is returned with the lease.
+func (p *Provider) createUserSSHKey(ctx context.Context, client gitlab.Client, role *modelRole.Role, name string, expiresAt time.Time) (token t.Token, err error) {
+	var userId int64
+	if userId, err = client.GetUserIdByUsername(ctx, role.Path); err != nil {
+		return nil, err
+	}
+
+	var publicKey, privateKey, fingerprint string
+	if publicKey, privateKey, fingerprint, err = utils.GenerateSSHKey(name); err != nil {
+		return nil, err
+	}
+
+	p.b.Logger().Debug("Creating user SSH key for role", "path", role.Path, "userId", userId, "name", name, "expiresAt", expiresAt)
+	var key *modelToken.TokenUserSSHKey
+	if key, err = client.CreateUserSSHKey(ctx, role.Path, userId, name, publicKey, &expiresAt); err == nil && key == nil {
+		err = fmt.Errorf("%w: token is nil", errs.ErrNilValue)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key.Token.Token = privateKey
+	key.Fingerprint = fingerprint
+	return key, nil
+}
@@ -0,0 +1,136 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	modelIssued "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+const (
+	// walKindToken is the kind of the WAL entries written while a token is being created.
+	walKindToken = "token"
+
+	// walClockSkew is how much earlier than the request GitLab may record the creation of a token, as the clocks of
+	// Vault and GitLab are not in sync.
+	walClockSkew = time.Minute
+)
+
+// updateWAL replaces the WAL entry written before the token was created with one that identifies
+// the created token, so the rollback is able to revoke it.
+func (p *Provider) updateWAL(ctx context.Context, s logical.Storage, walId string, token t.Token, startTime time.Time) (string, error) {
+	var entry = revocation.New(token)
+	entry.CreatedAt = startTime
+	return p.replaceWAL(ctx, s, walId, entry)
+}
+
+// replaceWAL replaces the WAL entry with the entry, the returned id is that of the entry that guards the token.
+func (p *Provider) replaceWAL(ctx context.Context, s logical.Storage, walId string, entry *revocation.Entry) (string, error) {
+	newWalId, err := framework.PutWAL(ctx, s, walKindToken, entry)
+	if err != nil {
+		return walId, fmt.Errorf("write wal entry: %w", err)
+	}
+
+	if err = framework.DeleteWAL(ctx, s, walId); err != nil {
+		return newWalId, fmt.Errorf("delete wal entry: %w", err)
+	}
+
+	return newWalId, nil
+}
+
+// WALRollback implements backend.WALRollbackHandler.
+// It revokes the tokens that were created in GitLab by a request that failed before the lease was issued.
+func (p *Provider) WALRollback(ctx context.Context, req *logical.Request, kind string, data any) (err error) {
+	if kind != walKindToken {
+		return nil
+	}
+
+	var entry revocation.Entry
+	var raw []byte
+	if raw, err = json.Marshal(data); err == nil {
+		err = json.Unmarshal(raw, &entry)
+	}
+	if err != nil {
+		return fmt.Errorf("decode wal entry: %w", err)
+	}
+
+	var ephemeral = entry.TokenType == t.TypeEphemeralGroupServiceAccount || entry.TokenType == t.TypeEphemeralUserServiceAccount
+	if entry.TokenID == 0 && !(ephemeral && entry.UserID != 0) {
+		// the request failed before GitLab returned the token, it may still have been created
+		return p.rollbackByName(ctx, req.Storage, &entry)
+	}
+
+	p.b.Logger().Info("Rolling back token", "role_name", entry.RoleName, "token_type", entry.TokenType.String(), "token_id", entry.TokenID)
+
+	var client gitlab.Client
+	if client, err = p.b.GetClientByName(ctx, req.Storage, entry.ConfigName); err != nil {
+		return err
+	}
+
+	if err = secret.RevokeToken(ctx, client, &entry); err != nil && !errors.Is(err, errs.ErrAccessTokenNotFound) {
+		return fmt.Errorf("rollback token %d: %w", entry.TokenID, err)
+	}
+
+	if entry.TokenID == 0 {
+		// only the service account was created, deleting it revoked everything there was
+		return nil
+	}
+	return p.b.DeleteIssuedToken(ctx, req.Storage, entry.ConfigName, entry.RoleName, entry.TokenID)
+}
+
+// rollbackByName revokes the tokens that GitLab created for a request that failed before the token was returned.
+// They are found by the name recorded in the WAL entry, the tokens with the same name that were created before the
+// request or were handed over to a lease are left alone.
+func (p *Provider) rollbackByName(ctx context.Context, s logical.Storage, entry *revocation.Entry) (err error) {
+	if !slices.Contains(gitlab.ListableTokenTypes, entry.TokenType) {
+		// the tokens can't be listed, there is nothing we know how to revoke
+		p.b.Logger().Warn("Dropping WAL entry without a token", "role_name", entry.RoleName, "token_type", entry.TokenType.String(), "path", entry.Path, "name", entry.Name)
+		return nil
+	}
+
+	var client gitlab.Client
+	if client, err = p.b.GetClientByName(ctx, s, entry.ConfigName); err != nil {
+		return err
+	}
+
+	var tokens []*modelToken.Token
+	if tokens, err = gitlab.ListTokens(ctx, client, entry.TokenType, entry.Path); err != nil {
+		return fmt.Errorf("rollback token %s: %w", entry.Name, err)
+	}
+
+	for _, tok := range tokens {
+		if tok.Name != entry.Name || tok.GetCreatedAt().Before(entry.CreatedAt.Add(-walClockSkew)) {
+			continue
+		}
+
+		var issued *modelIssued.Token
+		if issued, err = p.b.GetIssuedToken(ctx, s, entry.ConfigName, entry.RoleName, tok.TokenID); err != nil {
+			return err
+		}
+		if issued != nil {
+			continue
+		}
+
+		var found = *entry
+		found.TokenID, found.ParentID = tok.TokenID, tok.ParentID
+		p.b.Logger().Info("Rolling back token", "role_name", found.RoleName, "token_type", found.TokenType.String(), "token_id", found.TokenID)
+		if err = secret.RevokeToken(ctx, client, &found); err != nil && !errors.Is(err, errs.ErrAccessTokenNotFound) {
+			return fmt.Errorf("rollback token %d: %w", found.TokenID, err)
+		}
+	}
+
+	return nil
+}
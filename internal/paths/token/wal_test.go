@@ -0,0 +1,206 @@
+package token_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	g "gitlab.com/gitlab-org/api/client-go/v2"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	mt "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	pathtoken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/paths/token"
+	tk "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func walEntries(t *testing.T, s logical.Storage) (entries []*framework.WALEntry) {
+	t.Helper()
+	keys, err := framework.ListWAL(t.Context(), s)
+	require.NoError(t, err)
+	for _, key := range keys {
+		entry, err := framework.GetWAL(t.Context(), s, key)
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestPathTokenRoleCreate_WAL(t *testing.T) {
+	t.Run("removed on success", func(t *testing.T) {
+		s := &logical.InmemStorage{}
+		mb := &mockTokenBackend{
+			role:   role(tk.TypeProject, "g/p"),
+			client: &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)},
+		}
+		_, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, s)
+		require.NoError(t, err)
+		assert.Empty(t, walEntries(t, s))
+	})
+
+	t.Run("removed when gitlab fails", func(t *testing.T) {
+		s := &logical.InmemStorage{}
+		mb := &mockTokenBackend{
+			role:   role(tk.TypeProject, "g/p"),
+			client: &mockGitlabClient{createErr: errTest},
+		}
+		_, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, s)
+		require.Error(t, err)
+		assert.Empty(t, walEntries(t, s))
+	})
+
+	t.Run("kept when failing after the token is created", func(t *testing.T) {
+		s := &logical.InmemStorage{}
+		mb := &mockTokenBackend{
+			role:      role(tk.TypeProject, "g/p"),
+			client:    &mockGitlabClient{token: newToken(tk.TypeProject, testNow, testExpiresAt)},
+			issuedErr: errTest,
+		}
+		_, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, s)
+		require.Error(t, err)
+
+		entries := walEntries(t, s)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "token", entries[0].Kind)
+		data := entries[0].Data.(map[string]any)
+		assert.Equal(t, json.Number("1"), data["token_id"])
+		assert.Equal(t, "default", data["config_name"])
+		assert.Equal(t, "r", data["role_name"])
+		assert.Empty(t, data["token"])
+	})
+
+	t.Run("kept when the outcome of the create is unknown", func(t *testing.T) {
+		s := &logical.InmemStorage{}
+		client := &mockGitlabClient{
+			token:      newToken(tk.TypeProject, testNow, testExpiresAt),
+			createdErr: &g.ErrorResponse{StatusCode: http.StatusGatewayTimeout},
+		}
+		mb := &mockTokenBackend{role: role(tk.TypeProject, "g/p"), client: client}
+		_, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, s)
+		require.Error(t, err)
+		assert.Empty(t, mb.issued)
+
+		entries := walEntries(t, s)
+		require.Len(t, entries, 1)
+		data := entries[0].Data.(map[string]any)
+		assert.Equal(t, json.Number("0"), data["token_id"])
+		assert.Equal(t, client.listed[0].Name, data["name"])
+
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{Storage: s}, "token", entries[0].Data))
+		assert.Equal(t, []int64{client.listed[0].TokenID}, client.revoked)
+	})
+
+	t.Run("kept when the request is cancelled", func(t *testing.T) {
+		s := &logical.InmemStorage{}
+		mb := &mockTokenBackend{
+			role:   role(tk.TypeProject, "g/p"),
+			client: &mockGitlabClient{createErr: context.DeadlineExceeded},
+		}
+		_, err := callCreateWithStorage(t, mb, map[string]any{"role_name": "r"}, s)
+		require.Error(t, err)
+		assert.Len(t, walEntries(t, s), 1)
+	})
+}
+
+func TestWALRollback(t *testing.T) {
+	var data = func(tokenId int64) map[string]any {
+		return map[string]any{
+			"config_name": "default",
+			"role_name":   "r",
+			"token_id":    json.Number(strconv.FormatInt(tokenId, 10)),
+			"token_type":  tk.TypeProject.String(),
+			"parent_id":   "g/p",
+		}
+	}
+
+	t.Run("other kind", func(t *testing.T) {
+		mb := &mockTokenBackend{}
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "other", nil))
+	})
+
+	t.Run("revokes the token", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockTokenBackend{client: client}
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", data(42)))
+		assert.Equal(t, []int64{42}, client.revoked)
+		assert.Equal(t, []int64{42}, mb.deleted)
+	})
+
+	t.Run("token not created", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockTokenBackend{client: client}
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", data(0)))
+		assert.Empty(t, client.revoked)
+	})
+
+	t.Run("token found by name", func(t *testing.T) {
+		var listed = func(id int64, name string, createdAt time.Time) *mt.TokenProject {
+			return &mt.TokenProject{TokenWithScopesAndAccessLevel: mt.TokenWithScopesAndAccessLevel{
+				Token: mt.Token{TokenID: id, Name: name, ParentID: "g/p", TokenType: tk.TypeProject, CreatedAt: &createdAt},
+			}}
+		}
+		client := &mockGitlabClient{listed: []*mt.TokenProject{
+			listed(1, "vault-r", testNow),
+			listed(2, "other", testNow),
+			listed(3, "vault-r", testNow.Add(-time.Hour)),
+			listed(4, "vault-r", testNow),
+		}}
+		mb := &mockTokenBackend{client: client, issued: []*issued.Token{{TokenID: 4}}}
+		entry := &revocation.Entry{ConfigName: "default", RoleName: "r", TokenType: tk.TypeProject, Path: "g/p", Name: "vault-r", CreatedAt: testNow}
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", entry))
+		assert.Equal(t, []int64{1}, client.revoked)
+	})
+
+	t.Run("token not found by name", func(t *testing.T) {
+		mb := &mockTokenBackend{client: &mockGitlabClient{lookupErr: errTest}}
+		entry := &revocation.Entry{ConfigName: "default", RoleName: "r", TokenType: tk.TypeProject, Path: "g/p", Name: "vault-r", CreatedAt: testNow}
+		require.ErrorIs(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", entry), errTest)
+	})
+
+	t.Run("token type can't be listed", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockTokenBackend{client: client}
+		entry := &revocation.Entry{ConfigName: "default", RoleName: "r", TokenType: tk.TypeProjectDeploy, Path: "g/p", Name: "vault-r"}
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", entry))
+		assert.Empty(t, client.revoked)
+	})
+
+	t.Run("token already gone", func(t *testing.T) {
+		mb := &mockTokenBackend{client: &mockGitlabClient{revokeErr: fmt.Errorf("project: %w", errs.ErrAccessTokenNotFound)}}
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", data(42)))
+		assert.Equal(t, []int64{42}, mb.deleted)
+	})
+
+	t.Run("revoke error", func(t *testing.T) {
+		mb := &mockTokenBackend{client: &mockGitlabClient{revokeErr: errTest}}
+		require.ErrorIs(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", data(42)), errTest)
+		assert.Empty(t, mb.deleted)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mb := &mockTokenBackend{clientErr: errTest}
+		require.ErrorIs(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", data(42)), errTest)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		mb := &mockTokenBackend{}
+		require.Error(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", map[string]any{"token_id": "invalid"}))
+	})
+
+	t.Run("entry", func(t *testing.T) {
+		client := &mockGitlabClient{}
+		mb := &mockTokenBackend{client: client}
+		entry := &revocation.Entry{ConfigName: "default", RoleName: "r", TokenID: 7, TokenType: tk.TypeProject}
+		require.NoError(t, pathtoken.New(mb, nil, nil, nil, nil).WALRollback(t.Context(), &logical.Request{}, "token", entry))
+		assert.Equal(t, []int64{7}, client.revoked)
+	})
+}
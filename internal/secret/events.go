@@ -3,3 +3,5 @@ package secret
 import "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
 
 var eventRevoke = event.MustEventType("token-revoke")
+var eventRenew = event.MustEventType("token-renew")
+var eventRevokeDeferred = event.MustEventType("token-revoke-deferred")
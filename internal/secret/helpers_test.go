@@ -3,10 +3,17 @@ package secret_test
 import (
 	"context"
 
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 )
 
@@ -14,6 +21,69 @@ import (
 type mockSecretBackend struct {
 	getClientByName func(ctx context.Context, s logical.Storage, name string) (gitlab.Client, error)
 	sendEvent       func(ctx context.Context, eventType event.EventType, metadata map[string]string) error
+	deleteIssued    func(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) error
+	issued          *issued.Token
+	deferred        []*revocation.Entry
+	deferredErr     error
+}
+
+func (m *mockSecretBackend) LockForKey(_, _ string) *locksutil.LockEntry {
+	return locksutil.CreateLocks()[0]
+}
+
+func (m *mockSecretBackend) GetSharedToken(ctx context.Context, s logical.Storage, key, name string) (*reuse.Entry, error) {
+	return model.Get[reuse.Entry](ctx, s, "reuse/"+key+"/"+name)
+}
+
+func (m *mockSecretBackend) SaveSharedToken(ctx context.Context, s logical.Storage, e *reuse.Entry) error {
+	return model.Save(ctx, s, "reuse/"+e.Key, e)
+}
+
+func (m *mockSecretBackend) DeleteSharedToken(ctx context.Context, s logical.Storage, key, name string) error {
+	return model.Delete(ctx, s, "reuse/"+key+"/"+name)
+}
+
+func (m *mockSecretBackend) GetCIVariableInjection(ctx context.Context, s logical.Storage, name string) (*variable.Injection, error) {
+	return model.Get[variable.Injection](ctx, s, "ci-variable/"+name)
+}
+
+func (m *mockSecretBackend) SaveCIVariableInjection(ctx context.Context, s logical.Storage, inj *variable.Injection) error {
+	return model.Save(ctx, s, "ci-variable", inj)
+}
+
+func (m *mockSecretBackend) DeleteCIVariableInjection(ctx context.Context, s logical.Storage, name string) error {
+	return model.Delete(ctx, s, "ci-variable/"+name)
+}
+
+func (m *mockSecretBackend) GetDeferredRevocation(_ context.Context, _ logical.Storage, _ string) (*revocation.Entry, error) {
+	return nil, nil
+}
+
+func (m *mockSecretBackend) SaveDeferredRevocation(_ context.Context, _ logical.Storage, e *revocation.Entry) error {
+	if m.deferredErr != nil {
+		return m.deferredErr
+	}
+	m.deferred = append(m.deferred, e)
+	return nil
+}
+
+func (m *mockSecretBackend) DeleteDeferredRevocation(_ context.Context, _ logical.Storage, _ string) error {
+	return nil
+}
+
+func (m *mockSecretBackend) GetIssuedToken(_ context.Context, _ logical.Storage, _, _ string, _ int64) (*issued.Token, error) {
+	return m.issued, nil
+}
+
+func (m *mockSecretBackend) SaveIssuedToken(_ context.Context, _ logical.Storage, _ *issued.Token) error {
+	return nil
+}
+
+func (m *mockSecretBackend) DeleteIssuedToken(ctx context.Context, s logical.Storage, configName, roleName string, tokenId int64) error {
+	if m.deleteIssued != nil {
+		return m.deleteIssued(ctx, s, configName, roleName, tokenId)
+	}
+	return nil
 }
 
 func (m *mockSecretBackend) GetClientByName(ctx context.Context, s logical.Storage, name string) (gitlab.Client, error) {
@@ -39,6 +109,20 @@ type stubClient struct {
 	revokePipelineProjectTriggerAccessToken func(ctx context.Context, projectId int64, tokenId int64) error
 	revokeGroupDeployToken                  func(ctx context.Context, groupId, deployTokenId int64) error
 	revokeProjectDeployToken                func(ctx context.Context, projectId, deployTokenId int64) error
+	deleteGroupServiceAccount               func(ctx context.Context, groupId string, userId int64) error
+	deleteUserServiceAccount                func(ctx context.Context, userId int64) error
+	removeMember                            func(ctx context.Context, path string, userId int64) error
+	deleteRunner                            func(ctx context.Context, runnerId int64) error
+	revokeClusterAgentToken                 func(ctx context.Context, projectId, agentId, tokenId int64, deleteAgent bool) error
+	revokeProjectDeployKey                  func(ctx context.Context, projectId, deployKeyId int64) error
+	revokeUserSSHKey                        func(ctx context.Context, userId, keyId int64) error
+	revokeImpersonationToken                func(ctx context.Context, userId, tokenId int64) error
+	deleteOAuthApplication                  func(ctx context.Context, applicationId int64) error
+	renewOAuthApplicationSecret             func(ctx context.Context, path string, applicationId int64) (*modelToken.TokenOAuthApplication, error)
+	removeJobTokenAllowlistEntry            func(ctx context.Context, tokenType token.Type, projectId, allowedId int64) error
+	getCIVariable                           func(ctx context.Context, target, path, key, environmentScope string) (*variable.Variable, error)
+	updateCIVariable                        func(ctx context.Context, target, path string, v *variable.Variable) error
+	deleteCIVariable                        func(ctx context.Context, target, path, key, environmentScope string) error
 }
 
 func (s *stubClient) RevokePersonalAccessToken(ctx context.Context, tokenId int64) error {
@@ -73,6 +157,62 @@ func (s *stubClient) RevokeProjectDeployToken(ctx context.Context, projectId, de
 	return s.revokeProjectDeployToken(ctx, projectId, deployTokenId)
 }
 
+func (s *stubClient) DeleteGroupServiceAccount(ctx context.Context, groupId string, userId int64) error {
+	return s.deleteGroupServiceAccount(ctx, groupId, userId)
+}
+
+func (s *stubClient) DeleteUserServiceAccount(ctx context.Context, userId int64) error {
+	return s.deleteUserServiceAccount(ctx, userId)
+}
+
+func (s *stubClient) RemoveMember(ctx context.Context, path string, userId int64) error {
+	return s.removeMember(ctx, path, userId)
+}
+
+func (s *stubClient) DeleteRunner(ctx context.Context, runnerId int64) error {
+	return s.deleteRunner(ctx, runnerId)
+}
+
+func (s *stubClient) RevokeClusterAgentToken(ctx context.Context, projectId, agentId, tokenId int64, deleteAgent bool) error {
+	return s.revokeClusterAgentToken(ctx, projectId, agentId, tokenId, deleteAgent)
+}
+
+func (s *stubClient) RemoveJobTokenAllowlistEntry(ctx context.Context, tokenType token.Type, projectId, allowedId int64) error {
+	return s.removeJobTokenAllowlistEntry(ctx, tokenType, projectId, allowedId)
+}
+
+func (s *stubClient) DeleteOAuthApplication(ctx context.Context, applicationId int64) error {
+	return s.deleteOAuthApplication(ctx, applicationId)
+}
+
+func (s *stubClient) RenewOAuthApplicationSecret(ctx context.Context, path string, applicationId int64) (*modelToken.TokenOAuthApplication, error) {
+	return s.renewOAuthApplicationSecret(ctx, path, applicationId)
+}
+
+func (s *stubClient) RevokeProjectDeployKey(ctx context.Context, projectId, deployKeyId int64) error {
+	return s.revokeProjectDeployKey(ctx, projectId, deployKeyId)
+}
+
+func (s *stubClient) RevokeUserSSHKey(ctx context.Context, userId, keyId int64) error {
+	return s.revokeUserSSHKey(ctx, userId, keyId)
+}
+
+func (s *stubClient) RevokeImpersonationToken(ctx context.Context, userId, tokenId int64) error {
+	return s.revokeImpersonationToken(ctx, userId, tokenId)
+}
+
+func (s *stubClient) GetCIVariable(ctx context.Context, target, path, key, environmentScope string) (*variable.Variable, error) {
+	return s.getCIVariable(ctx, target, path, key, environmentScope)
+}
+
+func (s *stubClient) UpdateCIVariable(ctx context.Context, target, path string, v *variable.Variable) error {
+	return s.updateCIVariable(ctx, target, path, v)
+}
+
+func (s *stubClient) DeleteCIVariable(ctx context.Context, target, path, key, environmentScope string) error {
+	return s.deleteCIVariable(ctx, target, path, key, environmentScope)
+}
+
 func newRevokeSecret(tokenType token.Type, parentId string, extra map[string]any) *logical.Secret {
 	data := map[string]any{
 		"token_id":             int64(42),
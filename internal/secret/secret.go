@@ -1,10 +1,14 @@
 package secret
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -12,16 +16,29 @@ import (
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/backend"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
 	g "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
 )
 
 const (
 	SecretAccessTokenType = "access_tokens"
+	SecretMembershipType  = "memberships"
+
+	SecretJobTokenAllowlistType = "job_token_allowlists"
+	SecretBatchAccessTokenType  = "batch_access_tokens"
 )
 
 type secretBackend interface {
+	backend.Locker
 	backend.ClientReader
+	backend.IssuedTokenStore
+	backend.ReuseStore
+	backend.CIVariableStore
+	backend.RevocationQueueStore
 	backend.EventSender
 }
 
@@ -55,102 +72,510 @@ var (
 	}
 )
 
-// NewSecret creates a framework.Secret for access tokens with the revoke handler
+// FieldSchemaMemberships defines the field schema for membership secrets.
+var FieldSchemaMemberships = map[string]*framework.FieldSchema{
+	"path": {
+		Type:         framework.TypeString,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Path"},
+	},
+	"username": {
+		Type:         framework.TypeString,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Username"},
+	},
+	"user_id": {
+		Type:         framework.TypeInt64,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "User ID"},
+	},
+	"access_level": {
+		Type:         framework.TypeString,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Access Level"},
+	},
+	"expires_at": {
+		Type:         framework.TypeTime,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Expires At"},
+	},
+}
+
+// FieldSchemaJobTokenAllowlists defines the field schema for job token allowlist secrets.
+var FieldSchemaJobTokenAllowlists = map[string]*framework.FieldSchema{
+	"path": {
+		Type:         framework.TypeString,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Path"},
+	},
+	"project_path": {
+		Type:         framework.TypeString,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Project Path"},
+	},
+	"allowed_path": {
+		Type:         framework.TypeString,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Allowed Path"},
+	},
+	"expires_at": {
+		Type:         framework.TypeTime,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Expires At"},
+	},
+}
+
+// FieldSchemaBatchAccessTokens defines the field schema for secrets that hold a batch of access tokens.
+var FieldSchemaBatchAccessTokens = map[string]*framework.FieldSchema{
+	"tokens": {
+		Type:         framework.TypeSlice,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Tokens"},
+	},
+	"expires_at": {
+		Type:         framework.TypeTime,
+		DisplayAttrs: &framework.DisplayAttributes{Name: "Expires At"},
+	},
+}
+
+// NewBatchSecret creates a framework.Secret for a batch of access tokens issued under a single lease, revoking the
+// lease revokes every token of the batch.
+func NewBatchSecret(b secretBackend, defaultConfigName string) *framework.Secret {
+	return &framework.Secret{
+		Type:   SecretBatchAccessTokenType,
+		Fields: FieldSchemaBatchAccessTokens,
+		Revoke: revokeBatchAccessTokens(b, defaultConfigName),
+		Renew:  renewAccessToken(b, defaultConfigName),
+	}
+}
+
+// NewJobTokenAllowlistSecret creates a framework.Secret for a project or group in the job token allowlist of a
+// project, the entry is revoked and renewed the same way as an access token.
+func NewJobTokenAllowlistSecret(b secretBackend, defaultConfigName string) *framework.Secret {
+	return &framework.Secret{
+		Type:   SecretJobTokenAllowlistType,
+		Fields: FieldSchemaJobTokenAllowlists,
+		Revoke: revokeAccessToken(b, defaultConfigName),
+		Renew:  renewAccessToken(b, defaultConfigName),
+	}
+}
+
+// NewMembershipSecret creates a framework.Secret for memberships of a user in a group or project, the membership
+// is revoked and renewed the same way as an access token.
+func NewMembershipSecret(b secretBackend, defaultConfigName string) *framework.Secret {
+	return &framework.Secret{
+		Type:   SecretMembershipType,
+		Fields: FieldSchemaMemberships,
+		Revoke: revokeAccessToken(b, defaultConfigName),
+		Renew:  renewAccessToken(b, defaultConfigName),
+	}
+}
+
+// NewSecret creates a framework.Secret for access tokens with the revoke and renew handlers
 // wired through the provided secretBackend interface.
 func NewSecret(b secretBackend, defaultConfigName string) *framework.Secret {
 	return &framework.Secret{
 		Type:   SecretAccessTokenType,
 		Fields: FieldSchemaAccessTokens,
 		Revoke: revokeAccessToken(b, defaultConfigName),
+		Renew:  renewAccessToken(b, defaultConfigName),
+	}
+}
+
+// renewAccessToken extends the lease by the requested increment, capped at the ttl of the lease, or by the ttl of the
+// lease if no increment was requested. The lease can never be extended past the expiry of the token in GitLab.
+func renewAccessToken(b secretBackend, defaultConfigName string) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+		var secret = req.Secret
+		if secret == nil {
+			return nil, fmt.Errorf("secret: %w", errs.ErrNilValue)
+		}
+
+		expiresAt, err := utils.ConvertToTime(secret.InternalData["expires_at"])
+		if err != nil {
+			return nil, fmt.Errorf("expires_at: %w", err)
+		}
+
+		var remaining = expiresAt.Sub(utils.TimeFromContext(ctx))
+		if remaining <= 0 {
+			return logical.ErrorResponse("token has expired"), fmt.Errorf("token expired at %s: %w", expiresAt, errs.ErrInvalidValue)
+		}
+
+		var configName = defaultConfigName
+		if val, ok := secret.InternalData["config_name"]; ok {
+			configName = val.(string)
+		}
+
+		// a requested increment is capped at the ttl of the lease, a renewal never grants more than the role does
+		var ttl = secret.TTL
+		if secret.Increment > 0 {
+			ttl = min(secret.Increment, cmp.Or(secret.TTL, secret.Increment))
+		}
+
+		var resp = &logical.Response{Secret: secret}
+		resp.Secret.TTL = min(ttl, remaining)
+
+		_ = b.SendEvent(ctx, eventRenew, map[string]string{
+			"lease_id":    secret.LeaseID,
+			"path":        fmt.Sprint(secret.InternalData["path"]),
+			"name":        fmt.Sprint(secret.InternalData["name"]),
+			"token_type":  fmt.Sprint(secret.InternalData["token_type"]),
+			"config_name": configName,
+			"ttl":         resp.Secret.TTL.String(),
+			"expires_at":  expiresAt.UTC().Format(time.RFC3339),
+		})
+
+		return resp, nil
 	}
 }
 
 func revokeAccessToken(b secretBackend, defaultConfigName string) framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
-		var err error
+		if req.Storage == nil {
+			return nil, fmt.Errorf("storage: %w", errs.ErrNilValue)
+		}
+
+		if req.Secret == nil {
+			return nil, fmt.Errorf("secret: %w", errs.ErrNilValue)
+		}
+
+		return revokeToken(ctx, b, req.Storage, req.Secret.LeaseID, req.Secret.InternalData, defaultConfigName)
+	}
+}
 
+// revokeBatchAccessTokens revokes every token of a batch lease. If one of them fails the revocation is retried by
+// Vault, the tokens that were already revoked are no longer found in GitLab and are skipped.
+func revokeBatchAccessTokens(b secretBackend, defaultConfigName string) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
 		if req.Storage == nil {
 			return nil, fmt.Errorf("storage: %w", errs.ErrNilValue)
 		}
 
-		var secret = req.Secret
-		if secret == nil {
+		if req.Secret == nil {
 			return nil, fmt.Errorf("secret: %w", errs.ErrNilValue)
 		}
 
-		var configName = defaultConfigName
-		if val, ok := req.Secret.InternalData["config_name"]; ok {
-			configName = val.(string)
+		var tokens []map[string]any
+		if raw, err := json.Marshal(req.Secret.InternalData["tokens"]); err != nil {
+			return nil, fmt.Errorf("tokens: %w", err)
+		} else if err = json.Unmarshal(raw, &tokens); err != nil {
+			return nil, fmt.Errorf("tokens: %w", err)
+		}
+
+		// every token is revoked even if one fails, so a single failure doesn't keep the rest of the batch alive
+		// until Vault retries the lease, the tokens revoked by this attempt are skipped by the next one
+		var failed []error
+		for _, internalData := range tokens {
+			if _, err := revokeToken(ctx, b, req.Storage, req.Secret.LeaseID, internalData, defaultConfigName); err != nil {
+				failed = append(failed, err)
+			}
 		}
 
-		var tokenId int64
-		tokenId, err = utils.ConvertToInt64(req.Secret.InternalData["token_id"])
+		if len(failed) > 0 {
+			return logical.ErrorResponse("failed to revoke %d of %d tokens", len(failed), len(tokens)), errors.Join(failed...)
+		}
+		return nil, nil
+	}
+}
+
+// revokeToken revokes the token described by the internal data of a lease.
+func revokeToken(ctx context.Context, b secretBackend, s logical.Storage, leaseId string, internalData map[string]any, defaultConfigName string) (_ *logical.Response, err error) {
+	var configName = defaultConfigName
+	if val, ok := internalData["config_name"]; ok {
+		configName = val.(string)
+	}
+
+	var tokenId int64
+	tokenId, err = utils.ConvertToInt64(internalData["token_id"])
+	if err != nil {
+		return nil, fmt.Errorf("token_id: %w", err)
+	}
+
+	var gitlabRevokesToken = internalData["gitlab_revokes_token"].(bool)
+	var vaultRevokesToken = !gitlabRevokesToken
+	var parentId = internalData["parent_id"].(string)
+	var tokenType token.Type
+	var tokenTypeValue = internalData["token_type"].(string)
+	tokenType, _ = token.ParseType(tokenTypeValue)
+	var roleName, _ = internalData["role_name"].(string)
+
+	if key, _ := internalData["reuse_key"].(string); key != "" {
+		var leases int
+		if leases, err = releaseSharedToken(ctx, b, s, key, tokenId); err != nil {
+			return nil, fmt.Errorf("release shared token: %w", err)
+		}
+		if leases > 0 {
+			// other leases still hold the token, it's revoked with the last of them
+			_ = b.SendEvent(ctx, eventRevoke, map[string]string{
+				"lease_id":    leaseId,
+				"path":        internalData["path"].(string),
+				"name":        internalData["name"].(string),
+				"token_id":    strconv.FormatInt(tokenId, 10),
+				"token_type":  tokenTypeValue,
+				"config_name": configName,
+				"leases":      strconv.Itoa(leases),
+			})
+			return nil, nil
+		}
+	}
+
+	if mode, _ := internalData["oauth_mode"].(string); tokenType == token.TypeOAuthApplication && mode == token.OAuthApplicationModeRotate {
+		var superseded bool
+		if superseded, err = supersededSecret(ctx, b, s, configName, roleName, tokenId, internalData["renewed_at"]); err != nil {
+			return nil, fmt.Errorf("oauth application secret: %w", err)
+		}
+		if superseded {
+			// a newer lease renewed the secret, the secret of this lease no longer works and renewing it again
+			// would break the newer lease
+			_ = b.SendEvent(ctx, eventRevoke, map[string]string{
+				"lease_id":    leaseId,
+				"path":        internalData["path"].(string),
+				"name":        internalData["name"].(string),
+				"token_id":    strconv.FormatInt(tokenId, 10),
+				"token_type":  tokenTypeValue,
+				"config_name": configName,
+				"superseded":  "true",
+			})
+			return nil, nil
+		}
+	}
+
+	if data, ok := internalData["ci_variable"]; ok && data != nil {
+		// the variable is restored even if GitLab revokes the token, pipelines should stop receiving it
+		var inj *variable.Injection
+		if inj, err = variable.Decode(data); err != nil {
+			return nil, err
+		}
+
+		var client g.Client
+		if client, err = b.GetClientByName(ctx, s, configName); err != nil {
+			return nil, fmt.Errorf("restore ci variable cannot get client got %s config: %w", configName, err)
+		}
+
+		if err = restoreCIVariable(ctx, b, s, client, inj); err != nil {
+			return logical.ErrorResponse("failed to restore ci variable"), fmt.Errorf("restore ci variable %s: %w", inj.Key, err)
+		}
+	}
+
+	if vaultRevokesToken {
+		var client g.Client
+		client, err = b.GetClientByName(ctx, s, configName)
 		if err != nil {
-			return nil, fmt.Errorf("token_id: %w", err)
+			return nil, fmt.Errorf("revoke token cannot get client got %s config: %w", configName, err)
 		}
 
-		var gitlabRevokesToken = req.Secret.InternalData["gitlab_revokes_token"].(bool)
-		var vaultRevokesToken = !gitlabRevokesToken
-		var parentId = req.Secret.InternalData["parent_id"].(string)
-		var tokenType token.Type
-		var tokenTypeValue = req.Secret.InternalData["token_type"].(string)
-		tokenType, _ = token.ParseType(tokenTypeValue)
+		var entry = &revocation.Entry{
+			LeaseID:    leaseId,
+			ConfigName: configName,
+			RoleName:   roleName,
+			TokenID:    tokenId,
+			TokenType:  tokenType,
+			ParentID:   parentId,
+		}
+		entry.Path, _ = internalData["path"].(string)
+		entry.Name, _ = internalData["name"].(string)
+		entry.UserID, _ = utils.ConvertToInt64(internalData["user_id"])
+		entry.AgentID, _ = utils.ConvertToInt64(internalData["agent_id"])
+		entry.AgentCreated, _ = internalData["agent_created"].(bool)
+		entry.OAuthMode, _ = internalData["oauth_mode"].(string)
+		if slices.Contains(revocation.SelfRevokingTokenTypes, tokenType) {
+			entry.Token, _ = internalData["token"].(string)
+		}
 
-		if vaultRevokesToken {
-			var client g.Client
-			client, err = b.GetClientByName(ctx, req.Storage, configName)
-			if err != nil {
-				return nil, fmt.Errorf("revoke token cannot get client got %s config: %w", configName, err)
+		err = RevokeToken(ctx, client, entry)
+		if err != nil && !errors.Is(err, errs.ErrAccessTokenNotFound) {
+			if !g.IsTransientError(err) {
+				return logical.ErrorResponse("failed to revoke token"), fmt.Errorf("revoke token: %w", err)
 			}
 
-			switch tokenType {
-			case token.TypePersonal:
-				err = client.RevokePersonalAccessToken(ctx, tokenId)
-			case token.TypeProject:
-				err = client.RevokeProjectAccessToken(ctx, tokenId, parentId)
-			case token.TypeGroup:
-				err = client.RevokeGroupAccessToken(ctx, tokenId, parentId)
-			case token.TypeUserServiceAccount:
-				var token = req.Secret.InternalData["token"].(string)
-				err = client.RevokeUserServiceAccountAccessToken(ctx, token)
-			case token.TypeGroupServiceAccount:
-				var token = req.Secret.InternalData["token"].(string)
-				err = client.RevokeGroupServiceAccountAccessToken(ctx, token)
-			case token.TypeProjectServiceAccount:
-				var token = req.Secret.InternalData["token"].(string)
-				err = client.RevokeProjectServiceAccountAccessToken(ctx, token)
-			case token.TypePipelineProjectTrigger:
-				var projectId int64
-				if projectId, err = strconv.ParseInt(parentId, 10, 64); err == nil {
-					err = client.RevokePipelineProjectTriggerAccessToken(ctx, projectId, tokenId)
-				}
-			case token.TypeGroupDeploy:
-				var groupId int64
-				if groupId, err = strconv.ParseInt(parentId, 10, 64); err == nil {
-					err = client.RevokeGroupDeployToken(ctx, groupId, tokenId)
-				}
-			case token.TypeProjectDeploy:
-				var projectId int64
-				if projectId, err = strconv.ParseInt(parentId, 10, 64); err == nil {
-					err = client.RevokeProjectDeployToken(ctx, projectId, tokenId)
-				}
+			// GitLab is unreachable, the revocation is queued and retried in the background so the
+			// lease can be released, the issued token record stays until the token is revoked
+			if err = deferRevocation(ctx, b, s, entry, err); err != nil {
+				return logical.ErrorResponse("failed to revoke token"), fmt.Errorf("defer revoke token: %w", err)
 			}
 
-			if err != nil && !errors.Is(err, errs.ErrAccessTokenNotFound) {
-				return logical.ErrorResponse("failed to revoke token"), fmt.Errorf("revoke token: %w", err)
-			}
+			return nil, nil
 		}
+	}
 
-		_ = b.SendEvent(ctx, eventRevoke, map[string]string{
-			"lease_id":             secret.LeaseID,
-			"path":                 req.Secret.InternalData["path"].(string),
-			"name":                 req.Secret.InternalData["name"].(string),
-			"token_id":             strconv.FormatInt(tokenId, 10),
-			"token_type":           tokenTypeValue,
-			"config_name":          configName,
-			"gitlab_revokes_token": strconv.FormatBool(gitlabRevokesToken),
-		})
+	if err = b.DeleteIssuedToken(ctx, s, configName, roleName, tokenId); err != nil {
+		return nil, fmt.Errorf("delete issued token: %w", err)
+	}
 
-		return nil, nil
+	_ = b.SendEvent(ctx, eventRevoke, map[string]string{
+		"lease_id":             leaseId,
+		"path":                 internalData["path"].(string),
+		"name":                 internalData["name"].(string),
+		"token_id":             strconv.FormatInt(tokenId, 10),
+		"token_type":           tokenTypeValue,
+		"config_name":          configName,
+		"gitlab_revokes_token": strconv.FormatBool(gitlabRevokesToken),
+	})
+
+	return nil, nil
+}
+
+// releaseSharedToken releases a lease of a token that is shared by the leases of identical requests, and returns
+// the number of leases that still hold the token. The shared token is removed with the last lease, so no new
+// request joins a token that is being revoked.
+func releaseSharedToken(ctx context.Context, b secretBackend, s logical.Storage, key string, tokenId int64) (leases int, err error) {
+	lock := b.LockForKey("reuse", key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var name = strconv.FormatInt(tokenId, 10)
+	var entry *reuse.Entry
+	if entry, err = b.GetSharedToken(ctx, s, key, name); err != nil || entry == nil {
+		return 0, err
+	}
+
+	if entry.Leases--; entry.Leases > 0 {
+		return entry.Leases, b.SaveSharedToken(ctx, s, entry)
+	}
+	return 0, b.DeleteSharedToken(ctx, s, key, name)
+}
+
+// supersededSecret reports if the secret of an OAuth application in rotate mode was renewed by a newer lease of the
+// role. Every lease replaces the issued token record of the application, so the record belongs to the newest lease.
+func supersededSecret(ctx context.Context, b secretBackend, s logical.Storage, configName, roleName string, applicationId int64, renewedAt any) (_ bool, err error) {
+	var leaseRenewedAt time.Time
+	if leaseRenewedAt, err = utils.ConvertToTime(renewedAt); err != nil || leaseRenewedAt.IsZero() {
+		// leases issued before the time was recorded, the secret is renewed as before
+		return false, nil
+	}
+
+	var it *issued.Token
+	if it, err = b.GetIssuedToken(ctx, s, configName, roleName, applicationId); err != nil || it == nil {
+		return false, err
+	}
+	return it.IssuedAt.After(leaseRenewedAt), nil
+}
+
+// deferRevocation stores the revocation in the queue, from where it is retried until GitLab revokes the token.
+func deferRevocation(ctx context.Context, b secretBackend, s logical.Storage, entry *revocation.Entry, cause error) (err error) {
+	var now = utils.TimeFromContext(ctx).UTC()
+	entry.CreatedAt = now
+	entry.Failed(now, cause)
+
+	if err = b.SaveDeferredRevocation(ctx, s, entry); err != nil {
+		return err
+	}
+
+	_ = b.SendEvent(ctx, eventRevokeDeferred, map[string]string{
+		"lease_id":        entry.LeaseID,
+		"path":            entry.Path,
+		"name":            entry.Name,
+		"token_id":        strconv.FormatInt(entry.TokenID, 10),
+		"token_type":      entry.TokenType.String(),
+		"config_name":     entry.ConfigName,
+		"error":           entry.LastError,
+		"next_attempt_at": entry.NextAttemptAt.Format(time.RFC3339),
+	})
+
+	return nil
+}
+
+// restoreCIVariable restores the CI/CD variable of the lease, and forgets the injection if it's the last one into
+// the variable.
+func restoreCIVariable(ctx context.Context, b secretBackend, s logical.Storage, client g.Client, inj *variable.Injection) (err error) {
+	lock := b.LockForKey("ci-variable", inj.GetName())
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err = RestoreCIVariable(ctx, client, inj); err != nil {
+		return err
+	}
+
+	var last *variable.Injection
+	if last, err = b.GetCIVariableInjection(ctx, s, inj.GetName()); err != nil || last == nil || last.ValueHash != inj.ValueHash {
+		return err
+	}
+	return b.DeleteCIVariableInjection(ctx, s, inj.GetName())
+}
+
+// RestoreCIVariable restores the CI/CD variable a lease value was written to, or deletes it if it didn't exist
+// before. A variable that no longer holds the lease value was changed by someone else and is left alone.
+func RestoreCIVariable(ctx context.Context, client g.Client, inj *variable.Injection) (err error) {
+	var current *variable.Variable
+	if current, err = client.GetCIVariable(ctx, inj.Target, inj.Path, inj.Key, inj.EnvironmentScope); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if current == nil || !inj.Holds(current.Value) {
+		return nil
+	}
+
+	if inj.Previous != nil {
+		return client.UpdateCIVariable(ctx, inj.Target, inj.Path, inj.Previous)
+	}
+
+	if err = client.DeleteCIVariable(ctx, inj.Target, inj.Path, inj.Key, inj.EnvironmentScope); errors.Is(err, errs.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// RevokeToken revokes the token described by the entry in GitLab.
+func RevokeToken(ctx context.Context, client g.Client, e *revocation.Entry) (err error) {
+	switch e.TokenType {
+	case token.TypePersonal:
+		err = client.RevokePersonalAccessToken(ctx, e.TokenID)
+	case token.TypeImpersonation:
+		err = client.RevokeImpersonationToken(ctx, e.UserID, e.TokenID)
+	case token.TypeProject:
+		err = client.RevokeProjectAccessToken(ctx, e.TokenID, e.ParentID)
+	case token.TypeGroup:
+		err = client.RevokeGroupAccessToken(ctx, e.TokenID, e.ParentID)
+	case token.TypeUserServiceAccount:
+		err = client.RevokeUserServiceAccountAccessToken(ctx, e.Token)
+	case token.TypeGroupServiceAccount:
+		err = client.RevokeGroupServiceAccountAccessToken(ctx, e.Token)
+	case token.TypeProjectServiceAccount:
+		err = client.RevokeProjectServiceAccountAccessToken(ctx, e.Token)
+	case token.TypeEphemeralGroupServiceAccount:
+		// deleting the service account revokes its tokens and memberships
+		err = client.DeleteGroupServiceAccount(ctx, e.ParentID, e.UserID)
+	case token.TypeEphemeralUserServiceAccount:
+		err = client.DeleteUserServiceAccount(ctx, e.UserID)
+	case token.TypeMembership:
+		err = client.RemoveMember(ctx, e.ParentID, e.UserID)
+	case token.TypeGroupRunner, token.TypeProjectRunner, token.TypeInstanceRunner:
+		// deleting the runner revokes its authentication token
+		err = client.DeleteRunner(ctx, e.TokenID)
+	case token.TypePipelineProjectTrigger:
+		var projectId int64
+		if projectId, err = strconv.ParseInt(e.ParentID, 10, 64); err == nil {
+			err = client.RevokePipelineProjectTriggerAccessToken(ctx, projectId, e.TokenID)
+		}
+	case token.TypeGroupDeploy:
+		var groupId int64
+		if groupId, err = strconv.ParseInt(e.ParentID, 10, 64); err == nil {
+			err = client.RevokeGroupDeployToken(ctx, groupId, e.TokenID)
+		}
+	case token.TypeProjectDeploy:
+		var projectId int64
+		if projectId, err = strconv.ParseInt(e.ParentID, 10, 64); err == nil {
+			err = client.RevokeProjectDeployToken(ctx, projectId, e.TokenID)
+		}
+	case token.TypeProjectDeployKey:
+		var projectId int64
+		if projectId, err = strconv.ParseInt(e.ParentID, 10, 64); err == nil {
+			err = client.RevokeProjectDeployKey(ctx, projectId, e.TokenID)
+		}
+	case token.TypeUserSSHKey:
+		err = client.RevokeUserSSHKey(ctx, e.UserID, e.TokenID)
+	case token.TypeClusterAgent:
+		var projectId int64
+		if projectId, err = strconv.ParseInt(e.ParentID, 10, 64); err == nil {
+			// the agent registered for the lease is deleted once it has no tokens left
+			err = client.RevokeClusterAgentToken(ctx, projectId, e.AgentID, e.TokenID, e.AgentCreated)
+		}
+	case token.TypeProjectJobTokenAllowlist, token.TypeGroupJobTokenAllowlist:
+		var projectId int64
+		if projectId, err = strconv.ParseInt(e.ParentID, 10, 64); err == nil {
+			err = client.RemoveJobTokenAllowlistEntry(ctx, e.TokenType, projectId, e.TokenID)
+		}
+	case token.TypeOAuthApplication:
+		if e.OAuthMode == token.OAuthApplicationModeRotate {
+			// the application is not owned by the lease, renewing the secret again invalidates the leased one
+			_, err = client.RenewOAuthApplicationSecret(ctx, e.Path, e.TokenID)
+		} else {
+			err = client.DeleteOAuthApplication(ctx, e.TokenID)
+		}
+	default:
+		err = fmt.Errorf("%s: %w", e.TokenType, errs.ErrUnknownTokenType)
 	}
+	return err
 }
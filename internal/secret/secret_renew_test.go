@@ -0,0 +1,103 @@
+package secret_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func newRenewSecret(ttl, increment time.Duration, expiresAt any) *logical.Secret {
+	return &logical.Secret{
+		LeaseOptions: logical.LeaseOptions{TTL: ttl, Increment: increment, MaxTTL: 4 * time.Hour},
+		InternalData: map[string]any{
+			"path":        "example",
+			"name":        "vault-generated-token",
+			"token_type":  "personal",
+			"config_name": "cfg",
+			"expires_at":  expiresAt,
+		},
+		LeaseID: "lease-1",
+	}
+}
+
+func TestRenewAccessToken(t *testing.T) {
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var expiresAt = now.Add(4 * time.Hour)
+	var ctx = utils.WithStaticTime(t.Context(), now)
+
+	t.Run("nil secret", func(t *testing.T) {
+		s := secret.NewSecret(&mockSecretBackend{}, "default")
+		resp, err := s.HandleRenew(ctx, &logical.Request{})
+		require.ErrorIs(t, err, errs.ErrNilValue)
+		require.Nil(t, resp)
+	})
+
+	t.Run("invalid expires_at", func(t *testing.T) {
+		s := secret.NewSecret(&mockSecretBackend{}, "default")
+		resp, err := s.HandleRenew(ctx, &logical.Request{Secret: newRenewSecret(time.Hour, 0, "invalid")})
+		require.ErrorIs(t, err, errs.ErrInvalidValue)
+		require.Nil(t, resp)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		s := secret.NewSecret(&mockSecretBackend{}, "default")
+		resp, err := s.HandleRenew(ctx, &logical.Request{Secret: newRenewSecret(time.Hour, 0, now.Add(-time.Second))})
+		require.ErrorIs(t, err, errs.ErrInvalidValue)
+		require.NotNil(t, resp)
+		require.True(t, resp.IsError())
+	})
+
+	t.Run("extends by ttl", func(t *testing.T) {
+		var sentEventType event.EventType
+		var sentMetadata map[string]string
+		s := secret.NewSecret(&mockSecretBackend{
+			sendEvent: func(_ context.Context, eventType event.EventType, metadata map[string]string) error {
+				sentEventType = eventType
+				sentMetadata = metadata
+				return nil
+			},
+		}, "default")
+		resp, err := s.HandleRenew(ctx, &logical.Request{Secret: newRenewSecret(time.Hour, 0, expiresAt.Format(time.RFC3339))})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, time.Hour, resp.Secret.TTL)
+		assert.Equal(t, 4*time.Hour, resp.Secret.MaxTTL)
+		assert.Equal(t, "token-renew", sentEventType.String())
+		assert.Equal(t, "lease-1", sentMetadata["lease_id"])
+		assert.Equal(t, "cfg", sentMetadata["config_name"])
+		assert.Equal(t, "1h0m0s", sentMetadata["ttl"])
+	})
+
+	t.Run("extends by increment", func(t *testing.T) {
+		s := secret.NewSecret(&mockSecretBackend{}, "default")
+		resp, err := s.HandleRenew(ctx, &logical.Request{Secret: newRenewSecret(time.Hour, 30*time.Minute, &expiresAt)})
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, resp.Secret.TTL)
+	})
+
+	t.Run("increment capped at ttl", func(t *testing.T) {
+		s := secret.NewSecret(&mockSecretBackend{}, "default")
+		resp, err := s.HandleRenew(ctx, &logical.Request{Secret: newRenewSecret(time.Hour, 3*time.Hour, &expiresAt)})
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, resp.Secret.TTL)
+	})
+
+	t.Run("capped at gitlab expiry", func(t *testing.T) {
+		s := secret.NewSecret(&mockSecretBackend{}, "default")
+		resp, err := s.HandleRenew(
+			utils.WithStaticTime(t.Context(), expiresAt.Add(-30*time.Minute)),
+			&logical.Request{Secret: newRenewSecret(time.Hour, 0, expiresAt)},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, resp.Secret.TTL)
+	})
+}
@@ -0,0 +1,100 @@
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestRevokeBatchAccessTokens(t *testing.T) {
+	var batch = func() *logical.Secret {
+		var tokens []any
+		for _, id := range []int64{1, 2, 3} {
+			tokens = append(tokens, newRevokeSecret(token.TypeProject, "example/app", map[string]any{"token_id": id}).InternalData)
+		}
+		return &logical.Secret{InternalData: map[string]any{"tokens": tokens}}
+	}
+
+	t.Run("revokes every token", func(t *testing.T) {
+		var revoked, deleted []int64
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return &stubClient{revokeProjectAccessToken: func(_ context.Context, tokenId int64, _ string) error {
+					revoked = append(revoked, tokenId)
+					return nil
+				}}, nil
+			},
+			deleteIssued: func(_ context.Context, _ logical.Storage, _, _ string, tokenId int64) error {
+				deleted = append(deleted, tokenId)
+				return nil
+			},
+		}
+
+		resp, err := secret.NewBatchSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{Storage: &logical.InmemStorage{}, Secret: batch()})
+		require.NoError(t, err)
+		require.Nil(t, resp)
+		assert.Equal(t, []int64{1, 2, 3}, revoked)
+		assert.Equal(t, []int64{1, 2, 3}, deleted)
+	})
+
+	t.Run("tokens revoked by an earlier attempt are skipped", func(t *testing.T) {
+		var revoked []int64
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return &stubClient{revokeProjectAccessToken: func(_ context.Context, tokenId int64, _ string) error {
+					if tokenId == 1 {
+						return errs.ErrAccessTokenNotFound
+					}
+					revoked = append(revoked, tokenId)
+					return nil
+				}}, nil
+			},
+		}
+
+		_, err := secret.NewBatchSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{Storage: &logical.InmemStorage{}, Secret: batch()})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 3}, revoked)
+	})
+
+	t.Run("revokes the rest after a failure", func(t *testing.T) {
+		var revoked []int64
+		var errOther = errors.New("other error")
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return &stubClient{revokeProjectAccessToken: func(_ context.Context, tokenId int64, _ string) error {
+					switch tokenId {
+					case 1:
+						return errTest
+					case 3:
+						return errOther
+					}
+					revoked = append(revoked, tokenId)
+					return nil
+				}}, nil
+			},
+		}
+
+		resp, err := secret.NewBatchSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{Storage: &logical.InmemStorage{}, Secret: batch()})
+		require.ErrorIs(t, err, errTest)
+		require.ErrorIs(t, err, errOther)
+		require.True(t, resp.IsError())
+		assert.Equal(t, []int64{2}, revoked)
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		_, err := secret.NewBatchSecret(&mockSecretBackend{}, "default").HandleRevoke(t.Context(), &logical.Request{
+			Storage: &logical.InmemStorage{},
+			Secret:  &logical.Secret{InternalData: map[string]any{"tokens": "invalid"}},
+		})
+		require.Error(t, err)
+	})
+}
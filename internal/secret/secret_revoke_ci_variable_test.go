@@ -0,0 +1,182 @@
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+var errTest = errors.New("test error")
+
+func TestRestoreCIVariable(t *testing.T) {
+	var previous = &variable.Variable{Key: "DEPLOY_TOKEN", Value: "old-value", EnvironmentScope: "*", Protected: true, VariableType: variable.TypeFile, Description: "deploy"}
+	var current = &variable.Variable{Key: "DEPLOY_TOKEN", Value: "glpat-test", EnvironmentScope: "*"}
+
+	tests := []struct {
+		name     string
+		previous *variable.Variable
+		current  *variable.Variable
+		getErr   error
+		delErr   error
+		updated  *variable.Variable
+		deleted  bool
+		err      error
+	}{
+		{name: "restores the previous variable", previous: previous, current: current, updated: previous},
+		{name: "deletes the variable", current: current, deleted: true},
+		{name: "variable was already deleted", current: current, delErr: errs.ErrNotFound, deleted: true},
+		{name: "variable does not exist", getErr: errs.ErrNotFound},
+		{name: "variable was changed", previous: previous, current: &variable.Variable{Key: "DEPLOY_TOKEN", Value: "changed"}},
+		{name: "reading the variable fails", getErr: errTest, err: errTest},
+		{name: "deleting the variable fails", current: current, delErr: errTest, deleted: true, err: errTest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated *variable.Variable
+			var deleted bool
+			client := &stubClient{
+				getCIVariable: func(_ context.Context, target, path, key, environmentScope string) (*variable.Variable, error) {
+					require.Equal(t, variable.TargetGroup, target)
+					require.Equal(t, "example", path)
+					require.Equal(t, "DEPLOY_TOKEN", key)
+					require.Equal(t, "*", environmentScope)
+					return tt.current, tt.getErr
+				},
+				updateCIVariable: func(_ context.Context, _, _ string, v *variable.Variable) error {
+					updated = v
+					return nil
+				},
+				deleteCIVariable: func(_ context.Context, _, _, _, _ string) error {
+					deleted = true
+					return tt.delErr
+				},
+			}
+
+			err := secret.RestoreCIVariable(t.Context(), client, &variable.Injection{
+				Target:           variable.TargetGroup,
+				Path:             "example",
+				Key:              "DEPLOY_TOKEN",
+				EnvironmentScope: "*",
+				ValueHash:        variable.Hash("glpat-test"),
+				Previous:         tt.previous,
+			})
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.updated, updated)
+			assert.Equal(t, tt.deleted, deleted)
+		})
+	}
+}
+
+func TestRevokeAccessToken_RestoresCIVariable(t *testing.T) {
+	// the internal data of a lease is read back from storage as a map
+	var injection = map[string]any{
+		"target":            variable.TargetProject,
+		"path":              "example/app",
+		"key":               "DEPLOY_TOKEN",
+		"environment_scope": "*",
+		"value_hash":        variable.Hash("glpat-test"),
+	}
+
+	t.Run("restored before the token is revoked by GitLab", func(t *testing.T) {
+		var deleted, eventSent bool
+		client := &stubClient{
+			getCIVariable: func(_ context.Context, _, _, _, _ string) (*variable.Variable, error) {
+				return &variable.Variable{Key: "DEPLOY_TOKEN", Value: "glpat-test"}, nil
+			},
+			deleteCIVariable: func(_ context.Context, target, path, key, _ string) error {
+				require.Equal(t, variable.TargetProject, target)
+				require.Equal(t, "example/app", path)
+				require.Equal(t, "DEPLOY_TOKEN", key)
+				deleted = true
+				return nil
+			},
+		}
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return client, nil
+			},
+			sendEvent: func(_ context.Context, _ event.EventType, _ map[string]string) error {
+				eventSent = true
+				return nil
+			},
+		}
+
+		resp, err := secret.NewSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{
+			Storage: &logical.InmemStorage{},
+			Secret:  newRevokeSecret(token.TypePersonal, "user1", map[string]any{"gitlab_revokes_token": true, "ci_variable": injection}),
+		})
+		require.NoError(t, err)
+		require.Nil(t, resp)
+		assert.True(t, deleted)
+		assert.True(t, eventSent)
+	})
+
+	t.Run("forgets the last injection into the variable", func(t *testing.T) {
+		client := &stubClient{
+			getCIVariable: func(_ context.Context, _, _, _, _ string) (*variable.Variable, error) {
+				return &variable.Variable{Key: "DEPLOY_TOKEN", Value: "glpat-test"}, nil
+			},
+			deleteCIVariable: func(_ context.Context, _, _, _, _ string) error { return nil },
+		}
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return client, nil
+			},
+		}
+		var last = &variable.Injection{Target: variable.TargetProject, Path: "example/app", Key: "DEPLOY_TOKEN", EnvironmentScope: "*", ValueHash: variable.Hash("glpat-test")}
+		var storage = &logical.InmemStorage{}
+		require.NoError(t, mb.SaveCIVariableInjection(t.Context(), storage, last))
+
+		_, err := secret.NewSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{
+			Storage: storage,
+			Secret:  newRevokeSecret(token.TypePersonal, "user1", map[string]any{"gitlab_revokes_token": true, "ci_variable": injection}),
+		})
+		require.NoError(t, err)
+
+		got, err := mb.GetCIVariableInjection(t.Context(), storage, last.GetName())
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("restore fails", func(t *testing.T) {
+		var revoked bool
+		client := &stubClient{
+			getCIVariable: func(_ context.Context, _, _, _, _ string) (*variable.Variable, error) {
+				return nil, errTest
+			},
+			revokePersonalAccessToken: func(_ context.Context, _ int64) error {
+				revoked = true
+				return nil
+			},
+		}
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return client, nil
+			},
+		}
+
+		resp, err := secret.NewSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{
+			Storage: &logical.InmemStorage{},
+			Secret:  newRevokeSecret(token.TypePersonal, "user1", map[string]any{"ci_variable": injection}),
+		})
+		require.ErrorIs(t, err, errTest)
+		require.NotNil(t, resp)
+		assert.False(t, revoked)
+	})
+}
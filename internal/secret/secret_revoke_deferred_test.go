@@ -0,0 +1,109 @@
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	g "gitlab.com/gitlab-org/api/client-go/v2"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func TestRevokeAccessToken_Deferred(t *testing.T) {
+	var now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ctx = utils.WithStaticTime(t.Context(), now)
+
+	client := &stubClient{
+		revokeGroupServiceAccountAccessToken: func(_ context.Context, _ string) error {
+			return &g.ErrorResponse{StatusCode: http.StatusBadGateway}
+		},
+	}
+
+	t.Run("queued", func(t *testing.T) {
+		var events []event.EventType
+		var metadata map[string]string
+		var issuedDeleted bool
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return client, nil
+			},
+			deleteIssued: func(_ context.Context, _ logical.Storage, _, _ string, _ int64) error {
+				issuedDeleted = true
+				return nil
+			},
+			sendEvent: func(_ context.Context, eventType event.EventType, m map[string]string) error {
+				events, metadata = append(events, eventType), m
+				return nil
+			},
+		}
+
+		sec := newRevokeSecret(token.TypeGroupServiceAccount, "grp1", map[string]any{"token": "glpat-secret", "role_name": "role"})
+		sec.LeaseID = "gitlab/token/role/abc"
+		resp, err := secret.NewSecret(mb, "default").HandleRevoke(ctx, &logical.Request{
+			Storage: &logical.InmemStorage{},
+			Secret:  sec,
+		})
+		require.NoError(t, err)
+		require.Nil(t, resp)
+		assert.False(t, issuedDeleted, "the issued token record stays until the token is revoked")
+
+		require.Len(t, mb.deferred, 1)
+		entry := mb.deferred[0]
+		assert.Equal(t, "gitlab/token/role/abc", entry.LeaseID)
+		assert.Equal(t, "role", entry.RoleName)
+		assert.Equal(t, "glpat-secret", entry.Token)
+		assert.Equal(t, 1, entry.Attempts)
+		assert.Equal(t, now, entry.CreatedAt)
+		assert.WithinRange(t, entry.NextAttemptAt, now.Add(30*time.Second), now.Add(time.Minute))
+		assert.Contains(t, entry.LastError, "502")
+
+		require.Equal(t, []event.EventType{event.MustEventType("token-revoke-deferred")}, events)
+		assert.Equal(t, "42", metadata["token_id"])
+		assert.Equal(t, entry.NextAttemptAt.Format(time.RFC3339), metadata["next_attempt_at"])
+		assert.NotContains(t, metadata, "token")
+	})
+
+	t.Run("token not stored for types revoked by id", func(t *testing.T) {
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return &stubClient{revokePersonalAccessToken: func(_ context.Context, _ int64) error {
+					return &g.ErrorResponse{StatusCode: http.StatusServiceUnavailable}
+				}}, nil
+			},
+		}
+
+		_, err := secret.NewSecret(mb, "default").HandleRevoke(ctx, &logical.Request{
+			Storage: &logical.InmemStorage{},
+			Secret:  newRevokeSecret(token.TypePersonal, "user1", map[string]any{"token": "glpat-secret"}),
+		})
+		require.NoError(t, err)
+		require.Len(t, mb.deferred, 1)
+		assert.Empty(t, mb.deferred[0].Token)
+	})
+
+	t.Run("queue error", func(t *testing.T) {
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return client, nil
+			},
+			deferredErr: errors.New("storage error"),
+		}
+
+		resp, err := secret.NewSecret(mb, "default").HandleRevoke(ctx, &logical.Request{
+			Storage: &logical.InmemStorage{},
+			Secret:  newRevokeSecret(token.TypeGroupServiceAccount, "grp1", map[string]any{"token": "glpat-secret"}),
+		})
+		require.ErrorContains(t, err, "storage error")
+		require.NotNil(t, resp)
+	})
+}
@@ -0,0 +1,77 @@
+package secret_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/reuse"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
+)
+
+func TestRevokeAccessToken_SharedToken(t *testing.T) {
+	var revoked []int64
+	var events []map[string]string
+	client := &stubClient{
+		revokeProjectAccessToken: func(_ context.Context, tokenId int64, _ string) error {
+			revoked = append(revoked, tokenId)
+			return nil
+		},
+	}
+	mb := &mockSecretBackend{
+		getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+			return client, nil
+		},
+		sendEvent: func(_ context.Context, _ event.EventType, metadata map[string]string) error {
+			events = append(events, metadata)
+			return nil
+		},
+	}
+
+	storage := &logical.InmemStorage{}
+	require.NoError(t, mb.SaveSharedToken(t.Context(), storage, &reuse.Entry{Key: "key", TokenID: 42, Leases: 2}))
+
+	s := secret.NewSecret(mb, "default")
+	revoke := func() {
+		t.Helper()
+		resp, err := s.HandleRevoke(t.Context(), &logical.Request{
+			Storage: storage,
+			Secret: &logical.Secret{
+				InternalData: map[string]any{
+					"token_id":             int64(42),
+					"gitlab_revokes_token": false,
+					"parent_id":            "proj1",
+					"token_type":           token.TypeProject.String(),
+					"path":                 "proj1",
+					"name":                 "test-token",
+					"config_name":          "default",
+					"reuse_key":            "key",
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Nil(t, resp)
+	}
+
+	revoke()
+	assert.Empty(t, revoked, "another lease still holds the token")
+	entry, err := model.Get[reuse.Entry](t.Context(), storage, "reuse/key/42")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, 1, entry.Leases)
+	require.Len(t, events, 1)
+	assert.Equal(t, "1", events[0]["leases"])
+
+	revoke()
+	assert.Equal(t, []int64{42}, revoked, "the last lease revokes the token")
+	entry, err = model.Get[reuse.Entry](t.Context(), storage, "reuse/key/42")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
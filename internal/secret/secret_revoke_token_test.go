@@ -2,7 +2,9 @@ package secret_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/stretchr/testify/require"
@@ -10,6 +12,9 @@ import (
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/event"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/issued"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/revocation"
+	modelToken "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/secret"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 )
@@ -111,6 +116,158 @@ func TestRevokeAccessToken_VaultRevokes(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:      "ephemeral group service account",
+			tokenType: token.TypeEphemeralGroupServiceAccount,
+			parentId:  "grp1",
+			extra:     map[string]any{"user_id": float64(7)},
+			setupStub: func(c *stubClient) {
+				c.deleteGroupServiceAccount = func(_ context.Context, groupId string, userId int64) error {
+					require.Equal(t, "grp1", groupId)
+					require.Equal(t, int64(7), userId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "ephemeral user service account",
+			tokenType: token.TypeEphemeralUserServiceAccount,
+			parentId:  "",
+			extra:     map[string]any{"user_id": int64(7)},
+			setupStub: func(c *stubClient) {
+				c.deleteUserServiceAccount = func(_ context.Context, userId int64) error {
+					require.Equal(t, int64(7), userId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "membership",
+			tokenType: token.TypeMembership,
+			parentId:  "group/project",
+			extra:     map[string]any{"user_id": int64(7)},
+			setupStub: func(c *stubClient) {
+				c.removeMember = func(_ context.Context, path string, userId int64) error {
+					require.Equal(t, "group/project", path)
+					require.Equal(t, int64(7), userId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "group runner",
+			tokenType: token.TypeGroupRunner,
+			parentId:  "200",
+			setupStub: func(c *stubClient) {
+				c.deleteRunner = func(_ context.Context, runnerId int64) error {
+					require.Equal(t, int64(42), runnerId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "project deploy key",
+			tokenType: token.TypeProjectDeployKey,
+			parentId:  "100",
+			setupStub: func(c *stubClient) {
+				c.revokeProjectDeployKey = func(_ context.Context, projectId, deployKeyId int64) error {
+					require.Equal(t, int64(100), projectId)
+					require.Equal(t, int64(42), deployKeyId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "impersonation",
+			tokenType: token.TypeImpersonation,
+			parentId:  "",
+			extra:     map[string]any{"user_id": int64(7)},
+			setupStub: func(c *stubClient) {
+				c.revokeImpersonationToken = func(_ context.Context, userId, tokenId int64) error {
+					require.Equal(t, int64(7), userId)
+					require.Equal(t, int64(42), tokenId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "user ssh key",
+			tokenType: token.TypeUserSSHKey,
+			parentId:  "",
+			extra:     map[string]any{"user_id": int64(7)},
+			setupStub: func(c *stubClient) {
+				c.revokeUserSSHKey = func(_ context.Context, userId, keyId int64) error {
+					require.Equal(t, int64(7), userId)
+					require.Equal(t, int64(42), keyId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "cluster agent",
+			tokenType: token.TypeClusterAgent,
+			parentId:  "100",
+			extra:     map[string]any{"agent_id": int64(3)},
+			setupStub: func(c *stubClient) {
+				c.revokeClusterAgentToken = func(_ context.Context, projectId, agentId, tokenId int64, deleteAgent bool) error {
+					require.Equal(t, int64(100), projectId)
+					require.Equal(t, int64(3), agentId)
+					require.Equal(t, int64(42), tokenId)
+					require.False(t, deleteAgent)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "cluster agent created for the lease",
+			tokenType: token.TypeClusterAgent,
+			parentId:  "100",
+			extra:     map[string]any{"agent_id": int64(3), "agent_created": true},
+			setupStub: func(c *stubClient) {
+				c.revokeClusterAgentToken = func(_ context.Context, _, agentId, _ int64, deleteAgent bool) error {
+					require.Equal(t, int64(3), agentId)
+					require.True(t, deleteAgent)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "group job token allowlist",
+			tokenType: token.TypeGroupJobTokenAllowlist,
+			parentId:  "100",
+			setupStub: func(c *stubClient) {
+				c.removeJobTokenAllowlistEntry = func(_ context.Context, tokenType token.Type, projectId, allowedId int64) error {
+					require.Equal(t, token.TypeGroupJobTokenAllowlist, tokenType)
+					require.Equal(t, int64(100), projectId)
+					require.Equal(t, int64(42), allowedId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "oauth application",
+			tokenType: token.TypeOAuthApplication,
+			parentId:  "",
+			extra:     map[string]any{"oauth_mode": token.OAuthApplicationModeCreate},
+			setupStub: func(c *stubClient) {
+				c.deleteOAuthApplication = func(_ context.Context, applicationId int64) error {
+					require.Equal(t, int64(42), applicationId)
+					return nil
+				}
+			},
+		},
+		{
+			name:      "oauth application rotated secret",
+			tokenType: token.TypeOAuthApplication,
+			parentId:  "",
+			extra:     map[string]any{"oauth_mode": token.OAuthApplicationModeRotate},
+			setupStub: func(c *stubClient) {
+				c.renewOAuthApplicationSecret = func(_ context.Context, _ string, applicationId int64) (*modelToken.TokenOAuthApplication, error) {
+					require.Equal(t, int64(42), applicationId)
+					return &modelToken.TokenOAuthApplication{}, nil
+				}
+			},
+		},
 		{
 			name:      "pipeline project trigger",
 			tokenType: token.TypePipelineProjectTrigger,
@@ -223,3 +380,109 @@ func TestRevokeAccessToken_UsesConfigNameFromSecret(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, resp)
 }
+
+func TestRevokeAccessToken_DeletesIssuedToken(t *testing.T) {
+	client := &stubClient{
+		revokePersonalAccessToken: func(_ context.Context, _ int64) error { return nil },
+	}
+
+	t.Run("removed", func(t *testing.T) {
+		var deleted bool
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return client, nil
+			},
+			deleteIssued: func(_ context.Context, _ logical.Storage, configName, roleName string, tokenId int64) error {
+				require.Equal(t, "default", configName)
+				require.Equal(t, "role", roleName)
+				require.EqualValues(t, 42, tokenId)
+				deleted = true
+				return nil
+			},
+		}
+
+		resp, err := secret.NewSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{
+			Storage: &logical.InmemStorage{},
+			Secret:  newRevokeSecret(token.TypePersonal, "user1", map[string]any{"role_name": "role"}),
+		})
+		require.NoError(t, err)
+		require.Nil(t, resp)
+		require.True(t, deleted)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mb := &mockSecretBackend{
+			getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+				return client, nil
+			},
+			deleteIssued: func(_ context.Context, _ logical.Storage, _, _ string, _ int64) error {
+				return errors.New("storage error")
+			},
+		}
+
+		_, err := secret.NewSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{
+			Storage: &logical.InmemStorage{},
+			Secret:  newRevokeSecret(token.TypePersonal, "user1", map[string]any{"role_name": "role"}),
+		})
+		require.ErrorContains(t, err, "storage error")
+	})
+}
+
+func TestRevokeToken_UnknownType(t *testing.T) {
+	err := secret.RevokeToken(t.Context(), &stubClient{}, &revocation.Entry{TokenType: token.Type("unknown"), TokenID: 42})
+	require.ErrorIs(t, err, errs.ErrUnknownTokenType)
+}
+
+func TestRevokeAccessToken_OAuthApplicationRotate(t *testing.T) {
+	var renewedAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name       string
+		issuedAt   time.Time
+		superseded bool
+	}{
+		{name: "current secret", issuedAt: renewedAt},
+		{name: "secret renewed by a newer lease", issuedAt: renewedAt.Add(time.Minute), superseded: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var renewed, deleted bool
+			var metadata map[string]string
+			client := &stubClient{
+				renewOAuthApplicationSecret: func(_ context.Context, _ string, _ int64) (*modelToken.TokenOAuthApplication, error) {
+					renewed = true
+					return &modelToken.TokenOAuthApplication{}, nil
+				},
+			}
+			mb := &mockSecretBackend{
+				issued: &issued.Token{ConfigName: "default", RoleName: "role", TokenID: 42, IssuedAt: tt.issuedAt},
+				getClientByName: func(_ context.Context, _ logical.Storage, _ string) (gitlab.Client, error) {
+					return client, nil
+				},
+				deleteIssued: func(_ context.Context, _ logical.Storage, _, _ string, _ int64) error {
+					deleted = true
+					return nil
+				},
+				sendEvent: func(_ context.Context, _ event.EventType, m map[string]string) error {
+					metadata = m
+					return nil
+				},
+			}
+
+			resp, err := secret.NewSecret(mb, "default").HandleRevoke(t.Context(), &logical.Request{
+				Storage: &logical.InmemStorage{},
+				Secret: newRevokeSecret(token.TypeOAuthApplication, "", map[string]any{
+					"role_name":  "role",
+					"oauth_mode": token.OAuthApplicationModeRotate,
+					"renewed_at": renewedAt.Format(time.RFC3339Nano),
+				}),
+			})
+			require.NoError(t, err)
+			require.Nil(t, resp)
+			require.Equal(t, !tt.superseded, renewed)
+			require.Equal(t, !tt.superseded, deleted)
+			if tt.superseded {
+				require.Equal(t, "true", metadata["superseded"])
+			}
+		})
+	}
+}
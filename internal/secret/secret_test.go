@@ -15,5 +15,36 @@ func TestNewSecret(t *testing.T) {
 	require.NotNil(t, s)
 	assert.Equal(t, secret.SecretAccessTokenType, s.Type)
 	assert.NotNil(t, s.Revoke)
+	assert.NotNil(t, s.Renew)
 	assert.NotEmpty(t, s.Fields)
 }
+
+func TestNewMembershipSecret(t *testing.T) {
+	mb := &mockSecretBackend{}
+	s := secret.NewMembershipSecret(mb, "default")
+	require.NotNil(t, s)
+	assert.Equal(t, secret.SecretMembershipType, s.Type)
+	assert.NotNil(t, s.Revoke)
+	assert.NotNil(t, s.Renew)
+	assert.Contains(t, s.Fields, "username")
+}
+
+func TestNewJobTokenAllowlistSecret(t *testing.T) {
+	mb := &mockSecretBackend{}
+	s := secret.NewJobTokenAllowlistSecret(mb, "default")
+	require.NotNil(t, s)
+	assert.Equal(t, secret.SecretJobTokenAllowlistType, s.Type)
+	assert.NotNil(t, s.Revoke)
+	assert.NotNil(t, s.Renew)
+	assert.Contains(t, s.Fields, "allowed_path")
+}
+
+func TestNewBatchSecret(t *testing.T) {
+	mb := &mockSecretBackend{}
+	s := secret.NewBatchSecret(mb, "default")
+	require.NotNil(t, s)
+	assert.Equal(t, secret.SecretBatchAccessTokenType, s.Type)
+	assert.NotNil(t, s.Revoke)
+	assert.NotNil(t, s.Renew)
+	assert.Contains(t, s.Fields, "tokens")
+}
@@ -74,13 +74,64 @@ var accessLevelMinVersionByTokenType = map[Type]map[AccessLevel]string{
 		AccessLevelPlannerPermissions:         "17.7",
 		AccessLevelSecurityManagerPermissions: "18.11",
 	},
-	TypePersonal:               nil,
-	TypeUserServiceAccount:     nil,
-	TypeGroupServiceAccount:    nil,
-	TypeProjectServiceAccount:  nil,
-	TypePipelineProjectTrigger: nil,
-	TypeProjectDeploy:          nil,
-	TypeGroupDeploy:            nil,
+	// the access level of the membership of the service account, the membership is optional
+	TypeEphemeralGroupServiceAccount: {
+		AccessLevelGuestPermissions:           "0.0",
+		AccessLevelReporterPermissions:        "0.0",
+		AccessLevelDeveloperPermissions:       "0.0",
+		AccessLevelMaintainerPermissions:      "0.0",
+		AccessLevelOwnerPermissions:           "0.0",
+		AccessLevelPlannerPermissions:         "17.7",
+		AccessLevelSecurityManagerPermissions: "18.11",
+	},
+	TypeEphemeralUserServiceAccount: {
+		AccessLevelGuestPermissions:           "0.0",
+		AccessLevelReporterPermissions:        "0.0",
+		AccessLevelDeveloperPermissions:       "0.0",
+		AccessLevelMaintainerPermissions:      "0.0",
+		AccessLevelOwnerPermissions:           "0.0",
+		AccessLevelPlannerPermissions:         "17.7",
+		AccessLevelSecurityManagerPermissions: "18.11",
+	},
+	// the access level of the membership
+	TypeMembership: {
+		AccessLevelGuestPermissions:           "0.0",
+		AccessLevelReporterPermissions:        "0.0",
+		AccessLevelDeveloperPermissions:       "0.0",
+		AccessLevelMaintainerPermissions:      "0.0",
+		AccessLevelOwnerPermissions:           "0.0",
+		AccessLevelPlannerPermissions:         "17.7",
+		AccessLevelSecurityManagerPermissions: "18.11",
+	},
+	TypePersonal:                 nil,
+	TypeImpersonation:            nil,
+	TypeUserServiceAccount:       nil,
+	TypeGroupServiceAccount:      nil,
+	TypeProjectServiceAccount:    nil,
+	TypePipelineProjectTrigger:   nil,
+	TypeProjectDeploy:            nil,
+	TypeGroupDeploy:              nil,
+	TypeGroupRunner:              nil,
+	TypeProjectRunner:            nil,
+	TypeInstanceRunner:           nil,
+	TypeClusterAgent:             nil,
+	TypeProjectDeployKey:         nil,
+	TypeUserSSHKey:               nil,
+	TypeOAuthApplication:         nil,
+	TypeProjectJobTokenAllowlist: nil,
+	TypeGroupJobTokenAllowlist:   nil,
+}
+
+// accessLevelOptional lists the token types where the access_level can be left empty,
+// for these the access_level grants a membership instead of being a property of the token.
+var accessLevelOptional = []Type{
+	TypeEphemeralGroupServiceAccount,
+	TypeEphemeralUserServiceAccount,
+}
+
+// IsAccessLevelOptional reports whether tokenType can be used without an access_level.
+func IsAccessLevelOptional(tokenType Type) bool {
+	return slices.Contains(accessLevelOptional, tokenType)
 }
 
 // ValidAccessLevelsFor returns the access_levels allowed for tokenType on the
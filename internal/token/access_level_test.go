@@ -106,6 +106,30 @@ func TestValidAccessLevelsFor_Applicability(t *testing.T) {
 		_, applicable := gitlab.ValidAccessLevelsFor(gitlab.TypeProject, "17.0")
 		assert.True(t, applicable)
 	})
+
+	t.Run("membership applicable", func(t *testing.T) {
+		_, applicable := gitlab.ValidAccessLevelsFor(gitlab.TypeMembership, "17.0")
+		assert.True(t, applicable)
+		assert.False(t, gitlab.IsAccessLevelOptional(gitlab.TypeMembership))
+	})
+}
+
+func TestIsAccessLevelOptional(t *testing.T) {
+	assert.True(t, gitlab.IsAccessLevelOptional(gitlab.TypeEphemeralGroupServiceAccount))
+	assert.True(t, gitlab.IsAccessLevelOptional(gitlab.TypeEphemeralUserServiceAccount))
+	assert.False(t, gitlab.IsAccessLevelOptional(gitlab.TypeGroup))
+	assert.False(t, gitlab.IsAccessLevelOptional(gitlab.TypePersonal))
+}
+
+func TestIsRevokedByVault(t *testing.T) {
+	assert.True(t, gitlab.IsRevokedByVault(gitlab.TypeEphemeralGroupServiceAccount))
+	assert.True(t, gitlab.IsRevokedByVault(gitlab.TypeGroupRunner))
+	assert.True(t, gitlab.IsRevokedByVault(gitlab.TypeInstanceRunner))
+	assert.True(t, gitlab.IsRevokedByVault(gitlab.TypeClusterAgent))
+	assert.True(t, gitlab.IsRevokedByVault(gitlab.TypeOAuthApplication))
+	assert.True(t, gitlab.IsRevokedByVault(gitlab.TypeProjectJobTokenAllowlist))
+	assert.False(t, gitlab.IsRevokedByVault(gitlab.TypeProject))
+	assert.False(t, gitlab.IsRevokedByVault(gitlab.TypeMembership))
 }
 
 func TestIsAccessLevelAllowed_VersionGating(t *testing.T) {
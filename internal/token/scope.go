@@ -54,6 +54,13 @@ const (
 	// ScopeWriteVirtualRegistry if a project is private and authorization is required, grants read (pull), write (push), and delete access to container images through the dependency proxy. Available only when the dependency proxy is enabled.
 	ScopeWriteVirtualRegistry = Scope("write_virtual_registry")
 
+	// ScopeOpenID grants permission to authenticate with GitLab using OpenID Connect. Also gives read-only access to the user’s profile and group memberships.
+	ScopeOpenID = Scope("openid")
+	// ScopeProfile grants read-only access to the user’s profile data using OpenID Connect.
+	ScopeProfile = Scope("profile")
+	// ScopeEmail grants read-only access to the user’s primary email address using OpenID Connect.
+	ScopeEmail = Scope("email")
+
 	ScopeUnknown = Scope("")
 )
 
@@ -85,6 +92,24 @@ var scopeMinVersionByTokenType = map[Type]map[Scope]string{
 		ScopeSelfRotate:           "17.9",
 		ScopeReadServicePing:      "17.1",
 	},
+	TypeImpersonation: {
+		ScopeApi:                  "0.0",
+		ScopeReadApi:              "0.0",
+		ScopeReadUser:             "0.0",
+		ScopeReadRepository:       "0.0",
+		ScopeWriteRepository:      "0.0",
+		ScopeReadRegistry:         "0.0",
+		ScopeWriteRegistry:        "0.0",
+		ScopeReadVirtualRegistry:  "18.0",
+		ScopeWriteVirtualRegistry: "18.0",
+		ScopeSudo:                 "0.0",
+		ScopeAdminMode:            "0.0",
+		ScopeCreateRunner:         "0.0",
+		ScopeManageRunner:         "17.1",
+		ScopeAiFeatures:           "0.0",
+		ScopeK8SProxy:             "0.0",
+		ScopeReadServicePing:      "17.1",
+	},
 	TypeProject: {
 		ScopeApi:             "0.0",
 		ScopeReadApi:         "0.0",
@@ -180,7 +205,65 @@ var scopeMinVersionByTokenType = map[Type]map[Scope]string{
 		ScopeK8SProxy:             "0.0",
 		ScopeSelfRotate:           "17.9",
 	},
-	TypePipelineProjectTrigger: nil, // not applicable
+	TypeEphemeralUserServiceAccount: {
+		ScopeApi:                  "0.0",
+		ScopeReadApi:              "0.0",
+		ScopeReadUser:             "0.0",
+		ScopeReadRepository:       "0.0",
+		ScopeWriteRepository:      "0.0",
+		ScopeReadRegistry:         "0.0",
+		ScopeWriteRegistry:        "0.0",
+		ScopeReadVirtualRegistry:  "18.0",
+		ScopeWriteVirtualRegistry: "18.0",
+		ScopeCreateRunner:         "0.0",
+		ScopeManageRunner:         "17.1",
+		ScopeAiFeatures:           "0.0",
+		ScopeK8SProxy:             "0.0",
+	},
+	TypeEphemeralGroupServiceAccount: {
+		ScopeApi:                  "0.0",
+		ScopeReadApi:              "0.0",
+		ScopeReadRegistry:         "0.0",
+		ScopeWriteRegistry:        "0.0",
+		ScopeReadVirtualRegistry:  "18.0",
+		ScopeWriteVirtualRegistry: "18.0",
+		ScopeReadRepository:       "0.0",
+		ScopeWriteRepository:      "0.0",
+		ScopeCreateRunner:         "0.0",
+		ScopeManageRunner:         "17.1",
+		ScopeAiFeatures:           "0.0",
+		ScopeK8SProxy:             "0.0",
+	},
+	TypeOAuthApplication: {
+		ScopeApi:                  "0.0",
+		ScopeReadApi:              "0.0",
+		ScopeReadUser:             "0.0",
+		ScopeReadRepository:       "0.0",
+		ScopeWriteRepository:      "0.0",
+		ScopeReadRegistry:         "0.0",
+		ScopeWriteRegistry:        "0.0",
+		ScopeReadVirtualRegistry:  "18.0",
+		ScopeWriteVirtualRegistry: "18.0",
+		ScopeCreateRunner:         "0.0",
+		ScopeManageRunner:         "17.1",
+		ScopeK8SProxy:             "0.0",
+		ScopeAiFeatures:           "0.0",
+		ScopeSudo:                 "0.0",
+		ScopeAdminMode:            "0.0",
+		ScopeOpenID:               "0.0",
+		ScopeProfile:              "0.0",
+		ScopeEmail:                "0.0",
+	},
+	TypePipelineProjectTrigger:   nil, // not applicable
+	TypeMembership:               nil, // not applicable
+	TypeGroupRunner:              nil, // not applicable
+	TypeProjectRunner:            nil, // not applicable
+	TypeInstanceRunner:           nil, // not applicable
+	TypeClusterAgent:             nil, // not applicable
+	TypeProjectDeployKey:         nil, // not applicable
+	TypeUserSSHKey:               nil, // not applicable
+	TypeProjectJobTokenAllowlist: nil, // not applicable
+	TypeGroupJobTokenAllowlist:   nil, // not applicable
 }
 
 // ValidScopesFor returns the scopes allowed for tokenType on the given GitLab
@@ -111,6 +111,11 @@ func TestTokenScope(t *testing.T) {
 			expected: token.ScopeWriteVirtualRegistry,
 			input:    token.ScopeWriteVirtualRegistry.String(),
 		},
+		{
+			name:     "openid",
+			expected: token.ScopeOpenID,
+			input:    token.ScopeOpenID.String(),
+		},
 		{
 			name:     "unknown",
 			expected: token.ScopeUnknown,
@@ -172,6 +177,13 @@ func TestIsScopeAllowed_VersionGating(t *testing.T) {
 		// read_service_ping is PAT-only
 		{"read_service_ping personal at 17.1", token.TypePersonal, token.ScopeReadServicePing, "17.1", true},
 		{"read_service_ping not on project AT", token.TypeProject, token.ScopeReadServicePing, "18.0", false},
+		// impersonation tokens follow the PAT scopes, except self_rotate
+		{"sudo on impersonation", token.TypeImpersonation, token.ScopeSudo, "17.0", true},
+		{"self_rotate not on impersonation", token.TypeImpersonation, token.ScopeSelfRotate, "18.0", false},
+		// oauth applications can request the OpenID Connect scopes
+		{"openid on oauth application", token.TypeOAuthApplication, token.ScopeOpenID, "17.0", true},
+		{"openid not on personal", token.TypePersonal, token.ScopeOpenID, "18.0", false},
+		{"self_rotate not on oauth application", token.TypeOAuthApplication, token.ScopeSelfRotate, "18.0", false},
 		// deploy tokens have a different scope set
 		{"read_package_registry on project deploy", token.TypeProjectDeploy, token.ScopeReadPackageRegistry, "17.0", true},
 		{"api not on project deploy", token.TypeProjectDeploy, token.ScopeApi, "18.0", false},
@@ -11,6 +11,7 @@ type Type string
 
 const (
 	TypePersonal               = Type("personal")
+	TypeImpersonation          = Type("impersonation")
 	TypeProject                = Type("project")
 	TypeGroup                  = Type("group")
 	TypeUserServiceAccount     = Type("user-service-account")
@@ -20,6 +21,25 @@ const (
 	TypeProjectDeploy          = Type("project-deploy")
 	TypeGroupDeploy            = Type("group-deploy")
 
+	TypeEphemeralGroupServiceAccount = Type("ephemeral-group-service-account")
+	TypeEphemeralUserServiceAccount  = Type("ephemeral-user-service-account")
+
+	TypeMembership = Type("membership")
+
+	TypeGroupRunner    = Type("group-runner")
+	TypeProjectRunner  = Type("project-runner")
+	TypeInstanceRunner = Type("instance-runner")
+
+	TypeClusterAgent = Type("cluster-agent")
+
+	TypeProjectDeployKey = Type("project-deploy-key")
+	TypeUserSSHKey       = Type("user-ssh-key")
+
+	TypeOAuthApplication = Type("oauth-application")
+
+	TypeProjectJobTokenAllowlist = Type("project-job-token-allowlist")
+	TypeGroupJobTokenAllowlist   = Type("group-job-token-allowlist")
+
 	TypeUnknown = Type("")
 )
 
@@ -34,9 +54,100 @@ var (
 		TypePipelineProjectTrigger.String(),
 		TypeProjectDeploy.String(),
 		TypeGroupDeploy.String(),
+		TypeEphemeralGroupServiceAccount.String(),
+		TypeEphemeralUserServiceAccount.String(),
+		TypeMembership.String(),
+		TypeGroupRunner.String(),
+		TypeProjectRunner.String(),
+		TypeInstanceRunner.String(),
+		TypeClusterAgent.String(),
+		TypeProjectDeployKey.String(),
+		TypeUserSSHKey.String(),
+		TypeImpersonation.String(),
+		TypeOAuthApplication.String(),
+		TypeProjectJobTokenAllowlist.String(),
+		TypeGroupJobTokenAllowlist.String(),
+	}
+
+	// RunnerTokenTypes are the token types that create a runner and return its authentication token.
+	RunnerTokenTypes = []Type{TypeGroupRunner, TypeProjectRunner, TypeInstanceRunner}
+
+	// JobTokenAllowlistTypes are the token types that add a project or group to the CI/CD job token allowlist of a project.
+	JobTokenAllowlistTypes = []Type{TypeProjectJobTokenAllowlist, TypeGroupJobTokenAllowlist}
+
+	// CIVariableTokenTypes are the token types that issue a value which can be written to a CI/CD variable.
+	CIVariableTokenTypes = []Type{
+		TypePersonal,
+		TypeImpersonation,
+		TypeProject,
+		TypeGroup,
+		TypeUserServiceAccount,
+		TypeGroupServiceAccount,
+		TypeProjectServiceAccount,
+		TypePipelineProjectTrigger,
+		TypeProjectDeploy,
+		TypeGroupDeploy,
+		TypeEphemeralGroupServiceAccount,
+		TypeEphemeralUserServiceAccount,
+		TypeGroupRunner,
+		TypeProjectRunner,
+		TypeInstanceRunner,
+		TypeClusterAgent,
+		TypeProjectDeployKey,
+		TypeUserSSHKey,
+		TypeOAuthApplication,
+	}
+
+	// PoolTokenTypes are the token types that can be created ahead of a request, the token grants nothing in GitLab
+	// until its value is handed out.
+	PoolTokenTypes = []Type{
+		TypePersonal,
+		TypeImpersonation,
+		TypeProject,
+		TypeGroup,
+		TypeUserServiceAccount,
+		TypeGroupServiceAccount,
+		TypeProjectServiceAccount,
+		TypePipelineProjectTrigger,
+		TypeProjectDeploy,
+		TypeGroupDeploy,
+	}
+
+	// ReuseTokenTypes are the token types whose leases can share a token, the token is all there is to revoke in
+	// GitLab.
+	ReuseTokenTypes = PoolTokenTypes
+
+	// vaultRevokedTokenTypes are the token types where revoking the lease deletes more than the token in GitLab,
+	// or the token has no expiry in GitLab, so the revocation cannot be left to GitLab.
+	vaultRevokedTokenTypes = []Type{
+		TypeEphemeralGroupServiceAccount,
+		TypeEphemeralUserServiceAccount,
+		TypeGroupRunner,
+		TypeProjectRunner,
+		TypeInstanceRunner,
+		TypeClusterAgent,
+		TypeOAuthApplication,
+		TypeProjectJobTokenAllowlist,
+		TypeGroupJobTokenAllowlist,
 	}
 )
 
+const (
+	// OAuthApplicationModeCreate creates a new OAuth application for every lease and deletes it on revoke.
+	OAuthApplicationModeCreate = "create"
+	// OAuthApplicationModeRotate renews the secret of an existing OAuth application for every lease,
+	// the secret is renewed again on revoke so the leased secret stops working.
+	OAuthApplicationModeRotate = "rotate"
+)
+
+// OAuthApplicationModes are the valid values for the oauth_mode of a role.
+var OAuthApplicationModes = []string{OAuthApplicationModeCreate, OAuthApplicationModeRotate}
+
+// IsRevokedByVault reports whether the leases of tokenType must always be revoked by Vault.
+func IsRevokedByVault(tokenType Type) bool {
+	return slices.Contains(vaultRevokedTokenTypes, tokenType)
+}
+
 func (i Type) String() string {
 	return string(i)
 }
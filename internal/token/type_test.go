@@ -46,6 +46,71 @@ func TestTokenType(t *testing.T) {
 			expected: token.TypeProjectServiceAccount,
 			input:    token.TypeProjectServiceAccount.String(),
 		},
+		{
+			name:     "ephemeral-group-service-account",
+			expected: token.TypeEphemeralGroupServiceAccount,
+			input:    token.TypeEphemeralGroupServiceAccount.String(),
+		},
+		{
+			name:     "ephemeral-user-service-account",
+			expected: token.TypeEphemeralUserServiceAccount,
+			input:    token.TypeEphemeralUserServiceAccount.String(),
+		},
+		{
+			name:     "membership",
+			expected: token.TypeMembership,
+			input:    token.TypeMembership.String(),
+		},
+		{
+			name:     "group-runner",
+			expected: token.TypeGroupRunner,
+			input:    token.TypeGroupRunner.String(),
+		},
+		{
+			name:     "project-runner",
+			expected: token.TypeProjectRunner,
+			input:    token.TypeProjectRunner.String(),
+		},
+		{
+			name:     "instance-runner",
+			expected: token.TypeInstanceRunner,
+			input:    token.TypeInstanceRunner.String(),
+		},
+		{
+			name:     "cluster-agent",
+			expected: token.TypeClusterAgent,
+			input:    token.TypeClusterAgent.String(),
+		},
+		{
+			name:     "project-deploy-key",
+			expected: token.TypeProjectDeployKey,
+			input:    token.TypeProjectDeployKey.String(),
+		},
+		{
+			name:     "user-ssh-key",
+			expected: token.TypeUserSSHKey,
+			input:    token.TypeUserSSHKey.String(),
+		},
+		{
+			name:     "impersonation",
+			expected: token.TypeImpersonation,
+			input:    token.TypeImpersonation.String(),
+		},
+		{
+			name:     "oauth-application",
+			expected: token.TypeOAuthApplication,
+			input:    token.TypeOAuthApplication.String(),
+		},
+		{
+			name:     "project-job-token-allowlist",
+			expected: token.TypeProjectJobTokenAllowlist,
+			input:    token.TypeProjectJobTokenAllowlist.String(),
+		},
+		{
+			name:     "group-job-token-allowlist",
+			expected: token.TypeGroupJobTokenAllowlist,
+			input:    token.TypeGroupJobTokenAllowlist.String(),
+		},
 		{
 			name:     "pipeline-project-trigger",
 			expected: token.TypePipelineProjectTrigger,
@@ -2,13 +2,19 @@ package token
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
 )
 
+// JobTokenAllowlistSeparator separates the project from the project or group that is added to its job token
+// allowlist in the path of the job token allowlist token types.
+const JobTokenAllowlistSeparator = ":"
+
 var (
 	allowedSegment      = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	allowedAgentName    = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
 	invalidPathPrefixes = []string{"-", "_", "."}
 	invalidPathSuffixes = []string{"-", "_", ".", ".git", ".atom"}
 	invalidSegmentEdges = []string{"-", "_", "."}
@@ -22,9 +28,18 @@ Validation rules:
   - Path must not start with '-', '_', or '.'.
   - Path must not end with '-', '_', '.', '.git' or '.atom'.
   - Segment count rules per token type:
-    -- TypePersonal, TypeUserServiceAccount: exactly 1 segment.
+    -- TypePersonal, TypeImpersonation, TypeUserServiceAccount, TypeUserSSHKey: exactly 1 segment.
     -- TypeGroupServiceAccount, TypeProjectServiceAccount: exactly 2 segments.
     -- TypeProject, TypeGroup, TypeProjectDeploy, TypeGroupDeploy, TypePipelineProjectTrigger: 1 or more segments.
+    -- TypeProjectDeployKey: 1 or more segments.
+    -- TypeEphemeralGroupServiceAccount, TypeEphemeralUserServiceAccount: 1 or more segments.
+    -- TypeMembership: 2 or more segments.
+    -- TypeGroupRunner, TypeProjectRunner: 1 or more segments.
+    -- TypeInstanceRunner: exactly 1 segment.
+    -- TypeClusterAgent: 2 or more segments, the last one is a valid agent name (lowercase letters, digits and '-').
+    -- TypeOAuthApplication: exactly 1 segment.
+    -- TypeProjectJobTokenAllowlist, TypeGroupJobTokenAllowlist: a project path and the allowed project or group path
+    separated by JobTokenAllowlistSeparator, both validated with the rules above.
 
 Returns true if valid, else false.
 */
@@ -33,6 +48,20 @@ func IsValidPath(path string, tokenType Type) (valid bool) {
 		return false
 	}
 
+	if slices.Contains(JobTokenAllowlistTypes, tokenType) {
+		/*
+			Format of the paths, the project followed by the project or group that is added to its allowlist:
+				- group/project:group/other-project
+				- group/subgroup/project:group
+		*/
+		project, allowed, found := strings.Cut(path, JobTokenAllowlistSeparator)
+		var allowedType = TypeProject
+		if tokenType == TypeGroupJobTokenAllowlist {
+			allowedType = TypeGroup
+		}
+		return found && IsValidPath(project, TypeProject) && IsValidPath(allowed, allowedType)
+	}
+
 	if utils.HasAny(path, invalidPathPrefixes, strings.HasPrefix) ||
 		utils.HasAny(path, invalidPathSuffixes, strings.HasSuffix) {
 		return false
@@ -52,7 +81,7 @@ func IsValidPath(path string, tokenType Type) (valid bool) {
 	}
 
 	switch tokenType {
-	case TypePersonal, TypeUserServiceAccount:
+	case TypePersonal, TypeImpersonation, TypeUserServiceAccount, TypeUserSSHKey:
 		/*
 			Format of the paths:
 				- {username}
@@ -73,13 +102,60 @@ func IsValidPath(path string, tokenType Type) (valid bool) {
 		*/
 		return len(segments) == 2
 
-	case TypeProject, TypeGroup, TypeProjectDeploy, TypeGroupDeploy, TypePipelineProjectTrigger:
+	case TypeProject, TypeGroup, TypeProjectDeploy, TypeGroupDeploy, TypePipelineProjectTrigger, TypeProjectDeployKey:
 		/*
 			Format of the paths:
 				- group/project or group/subgroup/project
 				- group or group/subgroup
 		*/
 		return len(segments) >= 1
+
+	case TypeEphemeralGroupServiceAccount, TypeEphemeralUserServiceAccount:
+		/*
+			Format of the paths, the group or project the service account is added to:
+				- group/project or group/subgroup/project
+				- group or group/subgroup
+		*/
+		return len(segments) >= 1
+
+	case TypeMembership:
+		/*
+			Format of the paths, the group or project followed by the user that becomes a member:
+				- group/project/{username} or group/subgroup/project/{username}
+				- group/{username} or group/subgroup/{username}
+		*/
+		return len(segments) >= 2
+
+	case TypeGroupRunner, TypeProjectRunner:
+		/*
+			Format of the paths, the group or project the runner is created in:
+				- group/project or group/subgroup/project
+				- group or group/subgroup
+		*/
+		return len(segments) >= 1
+
+	case TypeInstanceRunner:
+		/*
+			Format of the paths, only identifies the role as the runner is created on the instance:
+				- instance
+		*/
+		return len(segments) == 1
+
+	case TypeClusterAgent:
+		/*
+			Format of the paths, the project followed by the name of the agent:
+				- group/project/{agentName} or group/subgroup/project/{agentName}
+		*/
+		return len(segments) >= 2 && allowedAgentName.MatchString(segments[len(segments)-1])
+
+	case TypeOAuthApplication:
+		/*
+			Format of the paths, identifies the application that is created or the id of the existing application
+			that has its secret rotated:
+				- {applicationName}
+				- {applicationId}
+		*/
+		return len(segments) == 1
 	}
 
 	return false
@@ -55,6 +55,30 @@ func TestIsValidPath(t *testing.T) {
 		{"project SA segment starts with invalid", "-project/acct", token.TypeProjectServiceAccount, false},
 		{"project SA segment ends with invalid", "project/acct-", token.TypeProjectServiceAccount, false},
 
+		// TypeEphemeralGroupServiceAccount, TypeEphemeralUserServiceAccount: group or project
+		{"ephemeral group SA top level group", "group1", token.TypeEphemeralGroupServiceAccount, true},
+		{"ephemeral group SA project", "group1/sub/project", token.TypeEphemeralGroupServiceAccount, true},
+		{"ephemeral user SA project", "group1/project", token.TypeEphemeralUserServiceAccount, true},
+		{"ephemeral user SA invalid segment", "group1/project-", token.TypeEphemeralUserServiceAccount, false},
+
+		// TypeMembership: group or project followed by the username
+		{"membership group", "group1/alice", token.TypeMembership, true},
+		{"membership nested project", "group1/sub/project/alice", token.TypeMembership, true},
+		{"membership without user", "group1", token.TypeMembership, false},
+
+		// TypeGroupRunner, TypeProjectRunner: group or project, TypeInstanceRunner: a single segment
+		{"group runner", "group1/sub", token.TypeGroupRunner, true},
+		{"project runner", "group1/project", token.TypeProjectRunner, true},
+		{"instance runner", "instance", token.TypeInstanceRunner, true},
+		{"instance runner nested", "group1/project", token.TypeInstanceRunner, false},
+
+		// TypeClusterAgent: project followed by the agent name
+		{"cluster agent", "group1/project/production", token.TypeClusterAgent, true},
+		{"cluster agent nested project", "group1/sub/project/prod-1", token.TypeClusterAgent, true},
+		{"cluster agent without project", "production", token.TypeClusterAgent, false},
+		{"cluster agent uppercase name", "group1/project/Production", token.TypeClusterAgent, false},
+		{"cluster agent name with underscore", "group1/project/prod_1", token.TypeClusterAgent, false},
+
 		// TypeProject, TypeGroup, TypeProjectDeploy, TypeGroupDeploy, TypePipelineProjectTrigger types
 		{"one segment", "myproj", token.TypeProject, true},
 		{"two segments", "group/proj", token.TypeGroup, true},
@@ -62,6 +86,21 @@ func TestIsValidPath(t *testing.T) {
 		{"segments valid", "grp1/pro.j2/b_c", token.TypeGroup, true},
 		{"forbidden prefix", "-group/project", token.TypeGroupDeploy, false},
 		{"forbidden suffix", "g1/proj.git", token.TypeProject, false},
+		{"deploy key project", "group1/sub/project", token.TypeProjectDeployKey, true},
+		{"user ssh key", "alice", token.TypeUserSSHKey, true},
+		{"impersonation", "alice", token.TypeImpersonation, true},
+		{"impersonation nested", "group1/alice", token.TypeImpersonation, false},
+		{"oauth application name", "ci-dashboard", token.TypeOAuthApplication, true},
+		{"oauth application id", "42", token.TypeOAuthApplication, true},
+		{"oauth application nested", "group1/app", token.TypeOAuthApplication, false},
+		// TypeProjectJobTokenAllowlist, TypeGroupJobTokenAllowlist: project followed by the allowed project or group
+		{"job token allowlist project", "group1/app:group2/consumer", token.TypeProjectJobTokenAllowlist, true},
+		{"job token allowlist group", "group1/sub/app:group2", token.TypeGroupJobTokenAllowlist, true},
+		{"job token allowlist without separator", "group1/app", token.TypeProjectJobTokenAllowlist, false},
+		{"job token allowlist empty allowed path", "group1/app:", token.TypeGroupJobTokenAllowlist, false},
+		{"job token allowlist invalid allowed path", "group1/app:-group2", token.TypeGroupJobTokenAllowlist, false},
+		{"job token allowlist two separators", "group1/app:group2:group3", token.TypeGroupJobTokenAllowlist, false},
+		{"user ssh key nested", "group1/alice", token.TypeUserSSHKey, false},
 		{"trailing slash", "g1/", token.TypeProject, false},
 		{"leading slash", "/g1", token.TypeProjectDeploy, false},
 		{"double slash (empty segment)", "g1//p2", token.TypeProjectDeploy, false},
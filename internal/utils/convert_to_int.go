@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
@@ -35,8 +36,8 @@ func ConvertToInt(num any) (int, error) {
 // ConvertToInt64 attempts to convert various numeric types to an int64.
 //
 // This function handles conversions from several numeric types (including int, int8,
-// int16, int32, int64, float32, and float64) to a standard int. It uses type
-// assertion to check the underlying type of the input. If the input is not a supported
+// int16, int32, int64, float32, float64, and json.Number as decoded from storage) to a standard int.
+// It uses type assertion to check the underlying type of the input. If the input is not a supported
 // numeric type, it returns an error.
 func ConvertToInt64(num any) (int64, error) {
 	switch val := num.(type) {
@@ -54,6 +55,10 @@ func ConvertToInt64(num any) (int64, error) {
 		return int64(val), nil
 	case float64:
 		return int64(val), nil
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i, nil
+		}
 	}
 	return int64(0), fmt.Errorf("%v: %w", num, errs.ErrInvalidValue)
 }
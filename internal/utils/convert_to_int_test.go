@@ -1,6 +1,7 @@
 package utils_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,6 +49,8 @@ func TestConvertToInt64(t *testing.T) {
 		{int64(23462346), int64(23462346), nil},
 		{float32(62346.62), int64(62346), nil},
 		{float64(263467.26), int64(263467), nil},
+		{json.Number("2346"), int64(2346), nil},
+		{json.Number("2346.5"), int64(0), errs.ErrInvalidValue},
 		{"1", int64(0), errs.ErrInvalidValue},
 	}
 
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+)
+
+// ConvertToTime attempts to convert a value stored in the lease internal data to a time.Time.
+//
+// Values written to the internal data are time.Time or *time.Time when the lease is issued,
+// but after they have been persisted by Vault they come back as RFC3339 formatted strings.
+// All of these forms are supported, anything else (including a nil pointer) returns an error.
+func ConvertToTime(val any) (time.Time, error) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+	case string:
+		if tm, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%v: %w", val, errs.ErrInvalidValue)
+}
@@ -0,0 +1,39 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/utils"
+)
+
+func TestConvertToTime(t *testing.T) {
+	var tm = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	var nilTime *time.Time
+
+	var tests = []struct {
+		in     any
+		outVal time.Time
+		outErr error
+	}{
+		{tm, tm, nil},
+		{&tm, tm, nil},
+		{tm.Format(time.RFC3339), tm, nil},
+		{nilTime, time.Time{}, errs.ErrInvalidValue},
+		{"invalid", time.Time{}, errs.ErrInvalidValue},
+		{int64(1), time.Time{}, errs.ErrInvalidValue},
+		{nil, time.Time{}, errs.ErrInvalidValue},
+	}
+
+	for _, tst := range tests {
+		t.Logf("ConvertToTime(%T(%v))", tst.in, tst.in)
+		val, err := utils.ConvertToTime(tst.in)
+		assert.True(t, tst.outVal.Equal(val))
+		if tst.outErr != nil {
+			assert.ErrorIs(t, err, tst.outErr)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package utils
 import (
 	"crypto/rand"
 	"fmt"
+	"regexp"
 	"strings"
 	"text/template"
+	"text/template/parse"
 	"time"
 	_ "unsafe"
 
@@ -103,3 +105,66 @@ func TokenName(role TokenNameData) (name string, err error) {
 	name = buf.String()
 	return name, err
 }
+
+// TokenNameRegexp builds a regular expression that matches the names TokenName generates for the role.
+//
+// Text in the template and actions that print a single field of the role data are matched literally,
+// everything else (functions, conditions, the unix_timestamp_utc field) can produce any value and is
+// matched with a wildcard. Templates without any literal part would match every token name, so they
+// are rejected.
+func TokenNameRegexp(role TokenNameData) (rx *regexp.Regexp, err error) {
+	if role == nil || role.IsNil() {
+		return nil, fmt.Errorf("role: %w", errs.ErrNilValue)
+	}
+	var tpl *template.Template
+	tpl, err = template.New("name").Funcs(tplFuncMap).Parse(role.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	var data = role.LogicalResponseData()
+	delete(data, "name")
+	delete(data, "unix_timestamp_utc")
+
+	var literal bool
+	var buf = new(strings.Builder)
+	buf.WriteString("^")
+	for _, node := range tpl.Tree.Root.Nodes {
+		var value, ok = "", false
+		switch n := node.(type) {
+		case *parse.TextNode:
+			value, ok = string(n.Text), true
+		case *parse.ActionNode:
+			value, ok = fieldValue(n, data)
+		}
+		if !ok {
+			buf.WriteString(".*")
+			continue
+		}
+		literal = literal || value != ""
+		buf.WriteString(regexp.QuoteMeta(value))
+	}
+	buf.WriteString("$")
+
+	if !literal {
+		return nil, fmt.Errorf("name template %q has no literal part to match on: %w", role.GetName(), errs.ErrInvalidValue)
+	}
+
+	return regexp.Compile(buf.String())
+}
+
+// fieldValue returns the printed value of an action in the form of {{ .field }}.
+func fieldValue(n *parse.ActionNode, data map[string]any) (string, bool) {
+	if len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) != 1 || len(n.Pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+	field, ok := n.Pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	if !ok || len(field.Ident) != 1 {
+		return "", false
+	}
+	val, ok := data[field.Ident[0]]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(val), true
+}
@@ -174,3 +174,74 @@ func TestTokenNameGenerator_UnixTimeStamp(t *testing.T) {
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, i, now)
 }
+
+func TestTokenNameRegexp(t *testing.T) {
+	t.Run("nil role", func(t *testing.T) {
+		_, err := utils.TokenNameRegexp(nil)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := utils.TokenNameRegexp(&tokenName{name: "{{ .role_name"})
+		require.Error(t, err)
+	})
+
+	t.Run("no literal part", func(t *testing.T) {
+		_, err := utils.TokenNameRegexp(&tokenName{name: "{{ randHexString 8 }}"})
+		require.Error(t, err)
+	})
+
+	var data = map[string]any{
+		"role_name":            "test.role",
+		"token_type":           "personal",
+		"gitlab_revokes_token": true,
+	}
+
+	var tests = []struct {
+		name     string
+		tpl      string
+		match    []string
+		notMatch []string
+	}{
+		{
+			name:     "fields and text",
+			tpl:      "vault-{{ .role_name }}-{{ .token_type }}",
+			match:    []string{"vault-test.role-personal"},
+			notMatch: []string{"vault-testxrole-personal", "vault-test.role-project", "prefix-vault-test.role-personal"},
+		},
+		{
+			name:     "functions and timestamp are wildcards",
+			tpl:      "{{ .role_name }}-{{ yesNoBool .gitlab_revokes_token }}-{{ .unix_timestamp_utc }}-{{ randHexString 4 }}",
+			match:    []string{"test.role-yes-1700000000-abcdabcd", "test.role-no-1-"},
+			notMatch: []string{"other-yes-1700000000-abcd"},
+		},
+		{
+			name:     "unknown field is a wildcard",
+			tpl:      "{{ .role_name }}-{{ .unknown }}",
+			match:    []string{"test.role-anything"},
+			notMatch: []string{"test.rol-anything"},
+		},
+	}
+
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			rx, err := utils.TokenNameRegexp(&tokenName{name: tst.tpl, data: data})
+			require.NoError(t, err)
+			for _, val := range tst.match {
+				assert.True(t, rx.MatchString(val), val)
+			}
+			for _, val := range tst.notMatch {
+				assert.False(t, rx.MatchString(val), val)
+			}
+		})
+	}
+
+	t.Run("matches the generated name", func(t *testing.T) {
+		var role = &tokenName{name: "{{ .role_name }}-{{ .token_type }}-{{ timeNowFormat \"2006-01\" }}", data: data}
+		rx, err := utils.TokenNameRegexp(role)
+		require.NoError(t, err)
+		name, err := utils.TokenName(role)
+		require.NoError(t, err)
+		assert.True(t, rx.MatchString(name))
+	})
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// GenerateSSHKey generates a new ed25519 key pair. The public key is returned in the authorized_keys format with
+// the comment appended, and the private key in the OpenSSH PEM format.
+func GenerateSSHKey(comment string) (publicKey, privateKey, fingerprint string, err error) {
+	var pub ed25519.PublicKey
+	var priv ed25519.PrivateKey
+	if pub, priv, err = ed25519.GenerateKey(rand.Reader); err != nil {
+		return "", "", "", fmt.Errorf("generate ed25519 key: %w", err)
+	}
+
+	var sshPub ssh.PublicKey
+	if sshPub, err = ssh.NewPublicKey(pub); err != nil {
+		return "", "", "", fmt.Errorf("convert public key: %w", err)
+	}
+
+	var block *pem.Block
+	if block, err = ssh.MarshalPrivateKey(priv, comment); err != nil {
+		return "", "", "", fmt.Errorf("marshal private key: %w", err)
+	}
+
+	publicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
+	if comment != "" {
+		publicKey = publicKey + " " + comment
+	}
+	return publicKey, string(pem.EncodeToMemory(block)), ssh.FingerprintSHA256(sshPub), nil
+}
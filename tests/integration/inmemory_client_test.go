@@ -5,6 +5,7 @@ package integration_test
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
@@ -16,8 +17,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	g "gitlab.com/gitlab-org/api/client-go/v2"
 
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/errs"
 	glab "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/gitlab"
 	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/token"
+	"github.com/ilijamt/vault-plugin-secrets-gitlab/internal/model/variable"
 	t "github.com/ilijamt/vault-plugin-secrets-gitlab/internal/token"
 )
 
@@ -30,12 +33,19 @@ func newInMemoryClient(tt *testing.T, valid bool) *inMemoryClient {
 		groups:          make([]string, 0),
 		valid:           valid,
 		accessTokens:    make(map[string]t.Token),
+		serviceAccounts: make(map[int64]string),
+		members:         make(map[string]t.AccessLevel),
+		clusterAgents:   make(map[string]int64),
+		oauthSecrets:    make(map[int64]string),
+		ciVariables:     make(map[string]*variable.Variable),
 		injectedErrors:  make(map[string]bool),
 		mainTokenInfo:   newSeededTokenConfig(),
 		rotateMainToken: newSeededTokenConfig(),
 	}
 	tt.Cleanup(func() {
 		assert.Empty(tt, c.LiveTokens(), "test left unrevoked tokens in inMemoryClient")
+		assert.Empty(tt, c.ServiceAccounts(), "test left undeleted service accounts in inMemoryClient")
+		assert.Empty(tt, c.Members(), "test left memberships in inMemoryClient")
 	})
 	return c
 }
@@ -76,12 +86,19 @@ type inMemoryClient struct {
 	calledMainToken       int64
 	calledRotateMainToken int64
 	calledValid           int64
+	calledRotateToken     int64
 
 	mainTokenInfo   token.TokenConfig
 	rotateMainToken token.TokenConfig
 
 	accessTokens map[string]t.Token
 
+	serviceAccounts map[int64]string
+	members         map[string]t.AccessLevel
+	clusterAgents   map[string]int64
+	oauthSecrets    map[int64]string
+	ciVariables     map[string]*variable.Variable
+
 	valueGetProjectIdByPath int64
 }
 
@@ -360,6 +377,10 @@ func (i *inMemoryClient) Valid(ctx context.Context) bool {
 	return i.valid
 }
 
+func (i *inMemoryClient) Saturation() glab.Saturation {
+	return glab.Saturation{}
+}
+
 func (i *inMemoryClient) CreatePersonalAccessToken(ctx context.Context, username string, userId int64, name string, expiresAt time.Time, scopes []string) (*token.TokenPersonal, error) {
 	i.muLock.Lock()
 	defer i.muLock.Unlock()
@@ -444,6 +465,551 @@ func (i *inMemoryClient) RevokeGroupAccessToken(ctx context.Context, tokenId int
 	return nil
 }
 
+func (i *inMemoryClient) ListPersonalAccessTokens(ctx context.Context, username string, userId int64) (tokens []*token.TokenPersonal, err error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err = i.injectedErrLocked("ListPersonalAccessTokens"); err != nil {
+		return nil, err
+	}
+	for _, tok := range i.accessTokens {
+		if et, ok := tok.(*token.TokenPersonal); ok && et.Path == username {
+			tokens = append(tokens, et)
+		}
+	}
+	slices.SortFunc(tokens, func(a, b *token.TokenPersonal) int { return int(a.TokenID - b.TokenID) })
+	return tokens, nil
+}
+
+func (i *inMemoryClient) ListProjectAccessTokens(ctx context.Context, projectId string) (tokens []*token.TokenProject, err error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err = i.injectedErrLocked("ListProjectAccessTokens"); err != nil {
+		return nil, err
+	}
+	for _, tok := range i.accessTokens {
+		if et, ok := tok.(*token.TokenProject); ok && et.Path == projectId {
+			tokens = append(tokens, et)
+		}
+	}
+	slices.SortFunc(tokens, func(a, b *token.TokenProject) int { return int(a.TokenID - b.TokenID) })
+	return tokens, nil
+}
+
+func (i *inMemoryClient) ListGroupAccessTokens(ctx context.Context, groupId string) (tokens []*token.TokenGroup, err error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err = i.injectedErrLocked("ListGroupAccessTokens"); err != nil {
+		return nil, err
+	}
+	for _, tok := range i.accessTokens {
+		if et, ok := tok.(*token.TokenGroup); ok && et.Path == groupId {
+			tokens = append(tokens, et)
+		}
+	}
+	slices.SortFunc(tokens, func(a, b *token.TokenGroup) int { return int(a.TokenID - b.TokenID) })
+	return tokens, nil
+}
+
 func (i *inMemoryClient) GetUserIdByUsername(ctx context.Context, username string) (int64, error) {
 	return int64(indexOrAppend(&i.users, username)), nil
 }
+
+// Rotated tokens belong to static roles and are never revoked through a lease,
+// so they are not tracked in accessTokens.
+func (i *inMemoryClient) RotatePersonalAccessToken(ctx context.Context, username string, tokenId int64, expiresAt time.Time) (*token.TokenPersonal, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RotatePersonalAccessToken"); err != nil {
+		return nil, err
+	}
+	i.calledRotateToken++
+	return &token.TokenPersonal{
+		TokenWithScopes: token.TokenWithScopes{
+			Token: newTokenBase(i.nextID(), "", username, "rotated", "glpat", t.TypePersonal, &expiresAt),
+		},
+	}, nil
+}
+
+func (i *inMemoryClient) RotateGroupAccessToken(ctx context.Context, groupId string, tokenId int64, expiresAt time.Time) (*token.TokenGroup, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RotateGroupAccessToken"); err != nil {
+		return nil, err
+	}
+	i.calledRotateToken++
+	return &token.TokenGroup{
+		TokenWithScopesAndAccessLevel: token.TokenWithScopesAndAccessLevel{
+			Token: newTokenBase(i.nextID(), groupId, groupId, "rotated", "glgat", t.TypeGroup, &expiresAt),
+		},
+	}, nil
+}
+
+func (i *inMemoryClient) RotateProjectAccessToken(ctx context.Context, projectId string, tokenId int64, expiresAt time.Time) (*token.TokenProject, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RotateProjectAccessToken"); err != nil {
+		return nil, err
+	}
+	i.calledRotateToken++
+	return &token.TokenProject{
+		TokenWithScopesAndAccessLevel: token.TokenWithScopesAndAccessLevel{
+			Token: newTokenBase(i.nextID(), projectId, projectId, "rotated", "glpat", t.TypeProject, &expiresAt),
+		},
+	}, nil
+}
+
+func (i *inMemoryClient) RotateGroupServiceAccountAccessToken(ctx context.Context, path string, groupId string, userId int64, tokenId int64, expiresAt time.Time) (*token.TokenGroupServiceAccount, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RotateGroupServiceAccountAccessToken"); err != nil {
+		return nil, err
+	}
+	i.calledRotateToken++
+	return &token.TokenGroupServiceAccount{
+		TokenWithScopes: token.TokenWithScopes{
+			Token: newTokenBase(i.nextID(), groupId, path, "rotated", "glpat", t.TypeGroupServiceAccount, &expiresAt),
+		},
+		UserID: userId,
+	}, nil
+}
+
+func (i *inMemoryClient) RotateProjectServiceAccountAccessToken(ctx context.Context, path string, projectId string, userId int64, tokenId int64, expiresAt time.Time) (*token.TokenProjectServiceAccount, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RotateProjectServiceAccountAccessToken"); err != nil {
+		return nil, err
+	}
+	i.calledRotateToken++
+	return &token.TokenProjectServiceAccount{
+		TokenWithScopes: token.TokenWithScopes{
+			Token: newTokenBase(i.nextID(), projectId, path, "rotated", "glpat", t.TypeProjectServiceAccount, &expiresAt),
+		},
+		UserID: userId,
+	}, nil
+}
+
+func (i *inMemoryClient) createServiceAccountLocked(prefix, name string) (int64, string) {
+	id := i.nextID()
+	username := fmt.Sprintf("%s_%d_%s", prefix, id, strings.ToLower(name))
+	i.serviceAccounts[id] = username
+	return id, username
+}
+
+// deleteServiceAccountLocked removes the service account together with its tokens and memberships.
+func (i *inMemoryClient) deleteServiceAccountLocked(userId int64) {
+	delete(i.serviceAccounts, userId)
+	for key, tok := range i.accessTokens {
+		switch tk := tok.(type) {
+		case *token.TokenGroupServiceAccount:
+			if tk.UserID == userId {
+				delete(i.accessTokens, key)
+			}
+		case *token.TokenPersonal:
+			if tk.UserID == userId {
+				delete(i.accessTokens, key)
+			}
+		}
+	}
+	for key := range i.members {
+		if strings.HasSuffix(key, fmt.Sprintf("_%d", userId)) {
+			delete(i.members, key)
+		}
+	}
+}
+
+func (i *inMemoryClient) CreateGroupServiceAccount(ctx context.Context, groupId string, name string) (int64, string, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateGroupServiceAccount"); err != nil {
+		return 0, "", err
+	}
+	userId, username := i.createServiceAccountLocked("service_account_group_"+groupId, name)
+	return userId, username, nil
+}
+
+func (i *inMemoryClient) CreateUserServiceAccount(ctx context.Context, name string) (int64, string, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateUserServiceAccount"); err != nil {
+		return 0, "", err
+	}
+	userId, username := i.createServiceAccountLocked("service_account", name)
+	return userId, username, nil
+}
+
+func (i *inMemoryClient) DeleteGroupServiceAccount(ctx context.Context, groupId string, userId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("DeleteGroupServiceAccount"); err != nil {
+		return err
+	}
+	i.deleteServiceAccountLocked(userId)
+	return nil
+}
+
+func (i *inMemoryClient) DeleteUserServiceAccount(ctx context.Context, userId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("DeleteUserServiceAccount"); err != nil {
+		return err
+	}
+	i.deleteServiceAccountLocked(userId)
+	return nil
+}
+
+func (i *inMemoryClient) AddMember(ctx context.Context, path string, userId int64, accessLevel t.AccessLevel, expiresAt *time.Time) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("AddMember"); err != nil {
+		return err
+	}
+	i.members[fmt.Sprintf("%s_%d", path, userId)] = accessLevel
+	return nil
+}
+
+func (i *inMemoryClient) RemoveMember(ctx context.Context, path string, userId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RemoveMember"); err != nil {
+		return err
+	}
+	delete(i.members, fmt.Sprintf("%s_%d", path, userId))
+	return nil
+}
+
+func (i *inMemoryClient) ServiceAccounts() map[int64]string {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	return maps.Clone(i.serviceAccounts)
+}
+
+func (i *inMemoryClient) Members() map[string]t.AccessLevel {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	return maps.Clone(i.members)
+}
+
+func (i *inMemoryClient) CreateRunner(ctx context.Context, tokenType t.Type, path string, parentId int64, name string, description string, tags []string, runUntagged bool, locked bool) (*token.TokenRunner, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateRunner"); err != nil {
+		return nil, err
+	}
+	var parent string
+	if parentId > 0 {
+		parent = strconv.FormatInt(parentId, 10)
+	}
+	id := i.nextID()
+	entryToken := &token.TokenRunner{
+		Token:       newTokenBase(id, parent, path, name, "glrt", tokenType, nil),
+		Description: description,
+		Tags:        tags,
+		RunUntagged: runUntagged,
+		Locked:      locked,
+	}
+	i.accessTokens[tokenKey(tokenType, id)] = entryToken
+	return entryToken, nil
+}
+
+func (i *inMemoryClient) DeleteRunner(ctx context.Context, runnerId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("DeleteRunner"); err != nil {
+		return err
+	}
+	for _, tokenType := range t.RunnerTokenTypes {
+		delete(i.accessTokens, tokenKey(tokenType, runnerId))
+	}
+	return nil
+}
+
+func (i *inMemoryClient) CreateClusterAgentToken(ctx context.Context, path string, projectId int64, agentName string, createAgent bool, name string) (*token.TokenClusterAgent, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateClusterAgentToken"); err != nil {
+		return nil, err
+	}
+	agentKey := fmt.Sprintf("%d_%s", projectId, agentName)
+	agentId, ok := i.clusterAgents[agentKey]
+	if !ok {
+		if !createAgent {
+			return nil, fmt.Errorf("cluster agent %s: %w", agentName, errs.ErrNotFound)
+		}
+		agentId = i.nextID()
+		i.clusterAgents[agentKey] = agentId
+	}
+	id := i.nextID()
+	entryToken := &token.TokenClusterAgent{
+		Token:        newTokenBase(id, strconv.FormatInt(projectId, 10), path, name, "glagent", t.TypeClusterAgent, nil),
+		AgentID:      agentId,
+		AgentName:    agentName,
+		AgentCreated: !ok,
+	}
+	i.accessTokens[tokenKey(t.TypeClusterAgent, projectId, agentId, id)] = entryToken
+	return entryToken, nil
+}
+
+func (i *inMemoryClient) RevokeClusterAgentToken(ctx context.Context, projectId, agentId, tokenId int64, deleteAgent bool) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RevokeClusterAgentToken"); err != nil {
+		return err
+	}
+	delete(i.accessTokens, tokenKey(t.TypeClusterAgent, projectId, agentId, tokenId))
+	if !deleteAgent {
+		return nil
+	}
+	var prefix = tokenKey(t.TypeClusterAgent, projectId, agentId) + "_"
+	for key := range i.accessTokens {
+		if strings.HasPrefix(key, prefix) {
+			return nil
+		}
+	}
+	maps.DeleteFunc(i.clusterAgents, func(_ string, id int64) bool { return id == agentId })
+	return nil
+}
+
+func (i *inMemoryClient) ClusterAgents() map[string]int64 {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	return maps.Clone(i.clusterAgents)
+}
+
+func (i *inMemoryClient) CreateProjectDeployKey(ctx context.Context, path string, projectId int64, name string, publicKey string, canPush bool, expiresAt *time.Time) (*token.TokenProjectDeployKey, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateProjectDeployKey"); err != nil {
+		return nil, err
+	}
+	id := i.nextID()
+	base := newTokenBase(id, strconv.FormatInt(projectId, 10), path, name, "", t.TypeProjectDeployKey, expiresAt)
+	base.Token = ""
+	entryToken := &token.TokenProjectDeployKey{
+		TokenSSHKey: token.TokenSSHKey{Token: base, PublicKey: publicKey, Fingerprint: "SHA256:" + uuid.New().String()},
+		CanPush:     canPush,
+	}
+	i.accessTokens[tokenKey(t.TypeProjectDeployKey, projectId, id)] = entryToken
+	return entryToken, nil
+}
+
+func (i *inMemoryClient) RevokeProjectDeployKey(ctx context.Context, projectId, deployKeyId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RevokeProjectDeployKey"); err != nil {
+		return err
+	}
+	delete(i.accessTokens, tokenKey(t.TypeProjectDeployKey, projectId, deployKeyId))
+	return nil
+}
+
+func (i *inMemoryClient) CreateUserSSHKey(ctx context.Context, username string, userId int64, name string, publicKey string, expiresAt *time.Time) (*token.TokenUserSSHKey, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateUserSSHKey"); err != nil {
+		return nil, err
+	}
+	id := i.nextID()
+	base := newTokenBase(id, "", username, name, "", t.TypeUserSSHKey, expiresAt)
+	base.Token = ""
+	entryToken := &token.TokenUserSSHKey{
+		TokenSSHKey: token.TokenSSHKey{Token: base, PublicKey: publicKey},
+		UserID:      userId,
+		Username:    username,
+	}
+	i.accessTokens[tokenKey(t.TypeUserSSHKey, userId, id)] = entryToken
+	return entryToken, nil
+}
+
+func (i *inMemoryClient) RevokeUserSSHKey(ctx context.Context, userId, keyId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RevokeUserSSHKey"); err != nil {
+		return err
+	}
+	delete(i.accessTokens, tokenKey(t.TypeUserSSHKey, userId, keyId))
+	return nil
+}
+
+func (i *inMemoryClient) CreateImpersonationToken(ctx context.Context, username string, userId int64, name string, expiresAt time.Time, scopes []string) (*token.TokenImpersonation, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateImpersonationToken"); err != nil {
+		return nil, err
+	}
+	id := i.nextID()
+	entryToken := &token.TokenImpersonation{
+		TokenWithScopes: token.TokenWithScopes{
+			Token:  newTokenBase(id, "", username, name, "glpat", t.TypeImpersonation, &expiresAt),
+			Scopes: scopes,
+		},
+		UserID: userId,
+	}
+	i.accessTokens[tokenKey(t.TypeImpersonation, userId, id)] = entryToken
+	return entryToken, nil
+}
+
+func (i *inMemoryClient) RevokeImpersonationToken(ctx context.Context, userId, tokenId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RevokeImpersonationToken"); err != nil {
+		return err
+	}
+	delete(i.accessTokens, tokenKey(t.TypeImpersonation, userId, tokenId))
+	return nil
+}
+
+func (i *inMemoryClient) CreateOAuthApplication(ctx context.Context, path string, name string, redirectURIs []string, scopes []string, confidential bool) (*token.TokenOAuthApplication, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateOAuthApplication"); err != nil {
+		return nil, err
+	}
+	id := i.nextID()
+	entryToken := &token.TokenOAuthApplication{
+		TokenWithScopes: token.TokenWithScopes{
+			Token:  newTokenBase(id, "", path, name, "gloas", t.TypeOAuthApplication, nil),
+			Scopes: scopes,
+		},
+		ClientID:     uuid.New().String(),
+		RedirectURIs: redirectURIs,
+		Confidential: confidential,
+		Mode:         t.OAuthApplicationModeCreate,
+	}
+	i.accessTokens[tokenKey(t.TypeOAuthApplication, id)] = entryToken
+	return entryToken, nil
+}
+
+// RenewOAuthApplicationSecret renews the secret of an existing application, the application is not
+// tracked as a live token as it is not owned by the lease.
+func (i *inMemoryClient) RenewOAuthApplicationSecret(ctx context.Context, path string, applicationId int64) (*token.TokenOAuthApplication, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RenewOAuthApplicationSecret"); err != nil {
+		return nil, err
+	}
+	entryToken := &token.TokenOAuthApplication{
+		TokenWithScopes: token.TokenWithScopes{
+			Token: newTokenBase(applicationId, "", path, "existing-application", "gloas", t.TypeOAuthApplication, nil),
+		},
+		ClientID: strconv.FormatInt(applicationId, 10),
+		Mode:     t.OAuthApplicationModeRotate,
+	}
+	i.oauthSecrets[applicationId] = entryToken.Token.Token
+	return entryToken, nil
+}
+
+func (i *inMemoryClient) OAuthSecret(applicationId int64) string {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	return i.oauthSecrets[applicationId]
+}
+
+func (i *inMemoryClient) DeleteOAuthApplication(ctx context.Context, applicationId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("DeleteOAuthApplication"); err != nil {
+		return err
+	}
+	delete(i.accessTokens, tokenKey(t.TypeOAuthApplication, applicationId))
+	return nil
+}
+
+func (i *inMemoryClient) AddJobTokenAllowlistEntry(ctx context.Context, tokenType t.Type, path string, projectId int64, allowedId int64) (*token.TokenJobTokenAllowlist, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("AddJobTokenAllowlistEntry"); err != nil {
+		return nil, err
+	}
+	key := tokenKey(tokenType, projectId, allowedId)
+	if _, ok := i.accessTokens[key]; ok {
+		return nil, fmt.Errorf("%d is already in the job token allowlist of project %d: %w", allowedId, projectId, errs.ErrInvalidValue)
+	}
+	entryToken := &token.TokenJobTokenAllowlist{
+		Token:     newTokenBase(allowedId, strconv.FormatInt(projectId, 10), path, "", "", tokenType, nil),
+		ProjectID: projectId,
+		AllowedID: allowedId,
+	}
+	entryToken.Token.Token = ""
+	i.accessTokens[key] = entryToken
+	return entryToken, nil
+}
+
+func (i *inMemoryClient) RemoveJobTokenAllowlistEntry(ctx context.Context, tokenType t.Type, projectId int64, allowedId int64) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("RemoveJobTokenAllowlistEntry"); err != nil {
+		return err
+	}
+	delete(i.accessTokens, tokenKey(tokenType, projectId, allowedId))
+	return nil
+}
+
+func ciVariableKey(target, path, key, environmentScope string) string {
+	return strings.Join([]string{target, path, key, environmentScope}, "_")
+}
+
+func (i *inMemoryClient) SetCIVariable(target, path string, v *variable.Variable) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	i.ciVariables[ciVariableKey(target, path, v.Key, v.EnvironmentScope)] = v
+}
+
+func (i *inMemoryClient) CIVariables() map[string]*variable.Variable {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	return maps.Clone(i.ciVariables)
+}
+
+func (i *inMemoryClient) GetCIVariable(ctx context.Context, target string, path string, key string, environmentScope string) (*variable.Variable, error) {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("GetCIVariable"); err != nil {
+		return nil, err
+	}
+	v, ok := i.ciVariables[ciVariableKey(target, path, key, environmentScope)]
+	if !ok {
+		return nil, fmt.Errorf("ci variable %s: %w", key, errs.ErrNotFound)
+	}
+	cp := *v
+	return &cp, nil
+}
+
+func (i *inMemoryClient) CreateCIVariable(ctx context.Context, target string, path string, v *variable.Variable) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("CreateCIVariable"); err != nil {
+		return err
+	}
+	key := ciVariableKey(target, path, v.Key, v.EnvironmentScope)
+	if _, ok := i.ciVariables[key]; ok {
+		return fmt.Errorf("ci variable %s already exists: %w", v.Key, errs.ErrInvalidValue)
+	}
+	cp := *v
+	i.ciVariables[key] = &cp
+	return nil
+}
+
+func (i *inMemoryClient) UpdateCIVariable(ctx context.Context, target string, path string, v *variable.Variable) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("UpdateCIVariable"); err != nil {
+		return err
+	}
+	key := ciVariableKey(target, path, v.Key, v.EnvironmentScope)
+	if _, ok := i.ciVariables[key]; !ok {
+		return fmt.Errorf("ci variable %s: %w", v.Key, errs.ErrNotFound)
+	}
+	cp := *v
+	i.ciVariables[key] = &cp
+	return nil
+}
+
+func (i *inMemoryClient) DeleteCIVariable(ctx context.Context, target string, path string, key string, environmentScope string) error {
+	i.muLock.Lock()
+	defer i.muLock.Unlock()
+	if err := i.injectedErrLocked("DeleteCIVariable"); err != nil {
+		return err
+	}
+	delete(i.ciVariables, ciVariableKey(target, path, key, environmentScope))
+	return nil
+}